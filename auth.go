@@ -0,0 +1,173 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/phrase/phraseapp-client/internal/lineedit"
+	"github.com/phrase/phraseapp-client/internal/print"
+	"github.com/phrase/phraseapp-go/phraseapp"
+)
+
+type AuthLoginCommand struct {
+	Host     string `cli:"opt --host desc='Host to store the token for (default: host from config or https://api.phrase.com)'"`
+	Token    string `cli:"opt --token desc='Access token to store, asked for if not set'"`
+	Encrypt  bool   `cli:"opt --encrypt desc='Encrypt the credentials store with a passphrase'"`
+	NoVerify bool   `cli:"opt --no-verify desc='Store the token without checking it with the API'"`
+
+	cfg *Config
+}
+
+func (cmd *AuthLoginCommand) Run() error {
+	host := normalizeHost(firstNonEmpty(cmd.Host, cmd.cfg.Credentials.Host))
+
+	token := cmd.Token
+	if token == "" {
+		if !lineedit.IsTerminal(os.Stdin) {
+			return errors.New("no access token given, set it with --token")
+		}
+		var err error
+		token, err = lineedit.New(os.Stdin, os.Stderr).ReadSecret("Access token for " + host + ": ")
+		if err != nil {
+			return err
+		}
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return errors.New("no access token entered")
+	}
+
+	if !cmd.NoVerify {
+		user, err := verifyToken(host, token)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Authenticated as %s\n", user.Username)
+	}
+
+	store, err := openCredentialStore()
+	if err != nil {
+		return err
+	}
+	if cmd.Encrypt && !store.Encrypted {
+		if store, err = store.encrypt(); err != nil {
+			return err
+		}
+	}
+	if err := store.Set(host, token); err != nil {
+		return err
+	}
+
+	print.Success("Stored the access token for %s in %s", host, store.Path)
+	return nil
+}
+
+// verifyToken checks that the token is valid by fetching the user it belongs to.
+func verifyToken(host, token string) (*phraseapp.User, error) {
+	client, err := newClient(phraseapp.Credentials{Token: token, Host: host}, false)
+	if err != nil {
+		return nil, err
+	}
+	user, err := client.ShowUser()
+	if err != nil {
+		return nil, fmt.Errorf("checking the access token failed: %s", err)
+	}
+	return user, nil
+}
+
+type AuthLogoutCommand struct {
+	Host string `cli:"opt --host desc='Host to remove the token of (default: host from config or https://api.phrase.com)'"`
+	All  bool   `cli:"opt --all desc='Remove the tokens of all hosts'"`
+
+	cfg *Config
+}
+
+func (cmd *AuthLogoutCommand) Run() error {
+	store, err := openCredentialStore()
+	if err != nil {
+		return err
+	}
+
+	hosts := []string{normalizeHost(firstNonEmpty(cmd.Host, cmd.cfg.Credentials.Host))}
+	if cmd.All {
+		if hosts, err = store.Hosts(); err != nil {
+			return err
+		}
+		if len(hosts) == 0 {
+			fmt.Println("No access tokens stored.")
+			return nil
+		}
+	}
+
+	for _, host := range hosts {
+		found, err := store.Delete(host)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("no access token stored for %s", host)
+		}
+		print.Success("Removed the access token for %s", host)
+	}
+	return nil
+}
+
+type AuthStatusCommand struct {
+	cfg *Config
+}
+
+func (cmd *AuthStatusCommand) Run() error {
+	store, err := openCredentialStore()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Store:   %s (%s)\n", store.Path, store.backend())
+
+	hosts, err := store.Hosts()
+	if err != nil {
+		return err
+	}
+	if len(hosts) == 0 {
+		fmt.Println("Tokens:  none, store one with 'phraseapp auth login'")
+	} else {
+		creds, err := store.load()
+		if err != nil {
+			return err
+		}
+		fmt.Println("Tokens:")
+		for _, host := range hosts {
+			fmt.Printf("  %s  %s  (stored %s)\n", host, maskToken(creds[host].Token), creds[host].CreatedAt.Format("2006-01-02"))
+		}
+	}
+
+	host := normalizeHost(cmd.cfg.Credentials.Host)
+	switch {
+	case cmd.cfg.Credentials.Token != "":
+		fmt.Printf("Active:  access token from %s overrides the store for %s\n", cmd.cfg.Origin("access_token"), host)
+	case os.Getenv("PHRASEAPP_ACCESS_TOKEN") != "":
+		fmt.Printf("Active:  access token from env PHRASEAPP_ACCESS_TOKEN overrides the store for %s\n", host)
+	default:
+		found, err := store.hasHost(host)
+		if err != nil {
+			return err
+		}
+		if found {
+			fmt.Printf("Active:  access token from the store for %s\n", host)
+		} else {
+			fmt.Printf("Active:  no access token for %s\n", host)
+		}
+	}
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/jpillora/backoff"
+	"github.com/phrase/phraseapp-client/internal/print"
+	"github.com/phrase/phraseapp-client/internal/spinner"
+	"github.com/phrase/phraseapp-go/phraseapp"
+)
+
+// The branch sync commands work on the same branch in all projects pushed to, so that CI can create a branch when a
+// pull request is opened, report the keys it changes and merge it once the pull request is merged.
+
+type BranchSyncStartCommand struct {
+	phraseapp.Config
+	Name               string `cli:"arg"`
+	ProjectID          string `cli:"opt --project-id desc='Project of the branch (default: the projects of all sources)'"`
+	UseLocalBranchName bool   `cli:"opt --use-local-branch-name desc='Use the name of your currently checked out branch (git or mercurial)'"`
+}
+
+func (cmd *BranchSyncStartCommand) Run() error {
+	client, projectIDs, name, err := branchSyncSetup(cmd.Config, cmd.ProjectID, cmd.Name, cmd.UseLocalBranchName)
+	if err != nil {
+		return err
+	}
+
+	for _, projectID := range projectIDs {
+		branch, err := client.BranchShow(projectID, name)
+		if err != nil {
+			if branch, err = client.BranchCreate(projectID, &phraseapp.BranchParams{Name: &name}); err != nil {
+				return fmt.Errorf("creating branch %s in project %s failed: %s", name, projectID, err)
+			}
+		}
+
+		if branch.State != "success" {
+			fmt.Printf("Waiting for branch %s of project %s to be created... ", name, projectID)
+			spinner.While(func() {
+				branch, err = waitForBranch(client, projectID, name, "success")
+			})
+			fmt.Println()
+			if err != nil {
+				return err
+			}
+		}
+		print.Success("Branch %s of project %s is ready", name, projectID)
+	}
+	return nil
+}
+
+type BranchSyncStatusCommand struct {
+	phraseapp.Config
+	Name               string `cli:"arg"`
+	ProjectID          string `cli:"opt --project-id desc='Project of the branch (default: the projects of all sources)'"`
+	UseLocalBranchName bool   `cli:"opt --use-local-branch-name desc='Use the name of your currently checked out branch (git or mercurial)'"`
+}
+
+func (cmd *BranchSyncStatusCommand) Run() error {
+	client, projectIDs, name, err := branchSyncSetup(cmd.Config, cmd.ProjectID, cmd.Name, cmd.UseLocalBranchName)
+	if err != nil {
+		return err
+	}
+
+	for i, projectID := range projectIDs {
+		comparison, err := compareBranch(client, projectID, name)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("Branch %s of project %s compared to main:\n", name, projectID)
+		comparison.print()
+	}
+	return nil
+}
+
+type BranchSyncFinishCommand struct {
+	phraseapp.Config
+	Name               string `cli:"arg"`
+	ProjectID          string `cli:"opt --project-id desc='Project of the branch (default: the projects of all sources)'"`
+	UseLocalBranchName bool   `cli:"opt --use-local-branch-name desc='Use the name of your currently checked out branch (git or mercurial)'"`
+	Strategy           string `cli:"opt --strategy desc='Which translation wins on conflicts: use_branch (default) or use_main'"`
+	Delete             bool   `cli:"opt --delete desc='Delete the branch once it is merged'"`
+}
+
+func (cmd *BranchSyncFinishCommand) Run() error {
+	if cmd.Strategy != "" && cmd.Strategy != "use_branch" && cmd.Strategy != "use_main" {
+		return fmt.Errorf("unknown merge strategy %q, use use_branch or use_main", cmd.Strategy)
+	}
+
+	client, projectIDs, name, err := branchSyncSetup(cmd.Config, cmd.ProjectID, cmd.Name, cmd.UseLocalBranchName)
+	if err != nil {
+		return err
+	}
+
+	for _, projectID := range projectIDs {
+		if err := finishBranch(client, projectID, name, cmd.Strategy, cmd.Delete); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finishBranch merges the branch and waits for the merge to complete. Branches merged before, e.g. by an earlier run
+// of a CI job, are only deleted.
+func finishBranch(client *phraseapp.Client, projectID, name, strategy string, deleteBranch bool) error {
+	branch, err := client.BranchShow(projectID, name)
+	if err != nil {
+		return fmt.Errorf("branch %s not found in project %s: %s", name, projectID, err)
+	}
+
+	if branch.State != "merged" {
+		if err := client.BranchMerge(projectID, name, &phraseapp.BranchMergeParams{Strategy: optionalString(strategy)}); err != nil {
+			return fmt.Errorf("merging branch %s of project %s failed: %s", name, projectID, err)
+		}
+
+		fmt.Printf("Waiting for branch %s of project %s to be merged... ", name, projectID)
+		spinner.While(func() {
+			_, err = waitForBranch(client, projectID, name, "merged")
+		})
+		fmt.Println()
+		if err != nil {
+			return err
+		}
+	}
+	print.Success("Branch %s of project %s is merged", name, projectID)
+
+	if deleteBranch {
+		if err := client.BranchDelete(projectID, name); err != nil {
+			return fmt.Errorf("deleting branch %s of project %s failed: %s", name, projectID, err)
+		}
+		print.Success("Deleted branch %s of project %s", name, projectID)
+	}
+	return nil
+}
+
+// branchComparison holds the names of the keys a branch added, changed and deleted compared to the main branch.
+type branchComparison struct {
+	Added   []string
+	Changed []string
+	Deleted []string
+}
+
+// compareBranch diffs the keys and translations of a branch against the main branch. The compare route of the API
+// doesn't document a response, and BranchCompare of the API library drops it.
+func compareBranch(client *phraseapp.Client, projectID, name string) (*branchComparison, error) {
+	main, err := branchContents(client, projectID, "")
+	if err != nil {
+		return nil, fmt.Errorf("comparing branch %s of project %s failed: %s", name, projectID, err)
+	}
+	branch, err := branchContents(client, projectID, name)
+	if err != nil {
+		return nil, fmt.Errorf("comparing branch %s of project %s failed: %s", name, projectID, err)
+	}
+
+	c := &branchComparison{Added: []string{}, Changed: []string{}, Deleted: []string{}}
+	for key, translations := range branch {
+		mainTranslations, found := main[key]
+		switch {
+		case !found:
+			c.Added = append(c.Added, key)
+		case !reflect.DeepEqual(translations, mainTranslations):
+			c.Changed = append(c.Changed, key)
+		}
+	}
+	for key := range main {
+		if _, found := branch[key]; !found {
+			c.Deleted = append(c.Deleted, key)
+		}
+	}
+	sort.Strings(c.Added)
+	sort.Strings(c.Changed)
+	sort.Strings(c.Deleted)
+	return c, nil
+}
+
+// translationID identifies a translation of a key, plural keys having one per locale and plural form.
+type translationID struct {
+	LocaleCode   string
+	PluralSuffix string
+}
+
+// branchContents maps the names of the keys of a branch, or of the main branch if branch is empty, to their
+// translations.
+func branchContents(client *phraseapp.Client, projectID, branch string) (map[string]map[translationID]string, error) {
+	keysParams, translationsParams := &phraseapp.KeysListParams{}, &phraseapp.TranslationsListParams{}
+	if branch != "" {
+		keysParams.Branch, translationsParams.Branch = &branch, &branch
+	}
+
+	contents := map[string]map[translationID]string{}
+	for page := 1; ; page++ {
+		keys, err := client.KeysList(projectID, page, keysPerRequest, keysParams)
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range keys {
+			contents[k.Name] = map[translationID]string{}
+		}
+		if len(keys) < keysPerRequest {
+			break
+		}
+	}
+
+	for page := 1; ; page++ {
+		translations, err := client.TranslationsList(projectID, page, keysPerRequest, translationsParams)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range translations {
+			if t.Key == nil || t.Locale == nil || contents[t.Key.Name] == nil {
+				continue
+			}
+			contents[t.Key.Name][translationID{t.Locale.Code, t.PluralSuffix}] = t.Content
+		}
+		if len(translations) < keysPerRequest {
+			return contents, nil
+		}
+	}
+}
+
+func (c *branchComparison) print() {
+	if len(c.Added)+len(c.Changed)+len(c.Deleted) == 0 {
+		fmt.Println("  no differences")
+		return
+	}
+
+	for _, list := range []struct {
+		sign  string
+		names []string
+	}{{"+", c.Added}, {"~", c.Changed}, {"-", c.Deleted}} {
+		for _, name := range list.names {
+			fmt.Printf("  %s %s\n", list.sign, name)
+		}
+	}
+	fmt.Printf("%d added, %d changed, %d deleted\n", len(c.Added), len(c.Changed), len(c.Deleted))
+}
+
+func branchSyncSetup(cfg phraseapp.Config, projectID, name string, useLocalBranchName bool) (*phraseapp.Client, []string, string, error) {
+	name, err := usedBranchName(useLocalBranchName, name)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if name == "" {
+		return nil, nil, "", errors.New("no branch given, pass its name or use --use-local-branch-name")
+	}
+
+	projectIDs, err := branchSyncProjects(cfg, projectID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	client, err := newClient(cfg.Credentials, cfg.Debug)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return client, projectIDs, name, nil
+}
+
+// branchSyncProjects returns the given project, or else the projects of all sources, as push creates the branch in
+// each of them.
+func branchSyncProjects(cfg phraseapp.Config, projectID string) ([]string, error) {
+	if projectID != "" {
+		return []string{projectID}, nil
+	}
+
+	if len(cfg.Sources) > 0 {
+		sources, err := SourcesFromConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		found := map[string]bool{}
+		projectIDs := []string{}
+		for _, source := range sources {
+			if source.ProjectID != "" && !found[source.ProjectID] {
+				found[source.ProjectID] = true
+				projectIDs = append(projectIDs, source.ProjectID)
+			}
+		}
+		if len(projectIDs) > 0 {
+			sort.Strings(projectIDs)
+			return projectIDs, nil
+		}
+	}
+
+	if cfg.DefaultProjectID == "" {
+		return nil, errors.New("no project given, set it with --project-id or project_id in the config file")
+	}
+	return []string{cfg.DefaultProjectID}, nil
+}
+
+// branchWaitTimeout is how long to wait for a branch to be created or merged.
+var branchWaitTimeout = 10 * time.Minute
+
+// waitForBranch polls the branch until it is in the given state. The error state and the timeout end the wait with an
+// error.
+func waitForBranch(client *phraseapp.Client, projectID, name, state string) (*phraseapp.Branch, error) {
+	b := &backoff.Backoff{
+		Min:    500 * time.Millisecond,
+		Max:    10 * time.Second,
+		Factor: 2,
+		Jitter: true,
+	}
+
+	deadline := time.Now().Add(branchWaitTimeout)
+	for {
+		branch, err := client.BranchShow(projectID, name)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case branch.State == state:
+			return branch, nil
+		case branch.State == "error":
+			return nil, fmt.Errorf("branch %s of project %s is in an error state", name, projectID)
+		case time.Now().After(deadline):
+			return nil, fmt.Errorf("timed out after %s waiting for branch %s of project %s, its state is %s", branchWaitTimeout, name, projectID, branch.State)
+		}
+		time.Sleep(b.Duration())
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/phrase/phraseapp-go/phraseapp"
+)
+
+func TestBranchSyncProjects(t *testing.T) {
+	sources := []byte("sources:\n- file: ./a/<locale_code>.yml\n  project_id: b\n- file: ./b/<locale_code>.yml\n  project_id: a\n- file: ./c/<locale_code>.yml\n")
+	tests := []struct {
+		cfg       phraseapp.Config
+		projectID string
+		exp       []string
+	}{
+		{phraseapp.Config{DefaultProjectID: "default", Sources: sources}, "flag", []string{"flag"}},
+		{phraseapp.Config{DefaultProjectID: "default", Sources: sources}, "", []string{"a", "b", "default"}},
+		{phraseapp.Config{DefaultProjectID: "default"}, "", []string{"default"}},
+	}
+	for _, test := range tests {
+		got, err := branchSyncProjects(test.cfg, test.projectID)
+		if err != nil {
+			t.Fatalf("didn't expect an error, got: %s", err)
+		}
+		if !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("expected %v, got %v", test.exp, got)
+		}
+	}
+
+	if _, err := branchSyncProjects(phraseapp.Config{}, ""); err == nil {
+		t.Errorf("expected an error without any project")
+	}
+}
+
+func TestBranchSyncCompareAndFinish(t *testing.T) {
+	ct, done := newCleanupTest(t)
+	defer done()
+
+	ct.upload("en:\n  a: A\n  b: B\n  c: C\n")
+	if _, err := ct.client.BranchCreate("project", &phraseapp.BranchParams{Name: optionalString("feature")}); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	ct.upload("en:\n  d: D\n")
+	ct.writeFile("en.yml", "en:\n  b: Changed\n  e: E\n")
+	path, format, update := filepath.Join(ct.dir, "en.yml"), "yml", true
+	if _, err := ct.client.UploadCreate("project", &phraseapp.UploadParams{File: &path, FileFormat: &format, Branch: optionalString("feature"), UpdateTranslations: &update}); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	cleanup := &keysCleanup{ProjectID: "project", Branch: "feature", Queries: []string{"name:c"}, Confirm: true}
+	if err := cleanup.run(ct.client); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+
+	comparison, err := compareBranch(ct.client, "project", "feature")
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if !reflect.DeepEqual(comparison.Added, []string{"e"}) || !reflect.DeepEqual(comparison.Changed, []string{"b"}) || !reflect.DeepEqual(comparison.Deleted, []string{"c", "d"}) {
+		t.Errorf("expected e added, b changed and c and d deleted, got %+v", comparison)
+	}
+
+	if err := finishBranch(ct.client, "project", "feature", "use_main", true); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if _, err := ct.client.BranchShow("project", "feature"); err == nil {
+		t.Errorf("expected the branch to be deleted")
+	}
+	if names := ct.keyNames(); len(names) != 5 || !names["e"] {
+		t.Errorf("expected merging to add key e, got %v", names)
+	}
+
+	if _, err := compareBranch(ct.client, "project", "feature"); err == nil {
+		t.Errorf("expected an error comparing a deleted branch")
+	}
+}
@@ -2,37 +2,192 @@ package main
 
 import (
 	"errors"
+	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
 	"strings"
+
+	"github.com/phrase/phraseapp-go/phraseapp"
 )
 
-func usedBranchName(useLocalBranchNameFlag bool, branchParam string) (string, error) {
-	if useLocalBranchName(useLocalBranchNameFlag) && branchParam == "" {
-		gitBranch, gitErr := checkedOutGitBranch()
-		if gitErr != nil || gitBranch == "" {
-			mercurialBranch, mercurialErr := checkedOutMercurialBranch()
-			if mercurialErr != nil || mercurialBranch == "" {
-				return "", errors.New("could not determine neither a git nor a mercurial branch")
-			} else if mercurialErr != nil {
-				return "", mercurialErr
+var defaultBranchNamePattern = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// BranchNameConfig contains the settings for turning the names of local branches into valid branch names. The zero
+// value keeps names as they are. Sanitizing replaces anything but letters, digits, "_", "." and "-" with "-" unless
+// another pattern or replacement is set.
+type BranchNameConfig struct {
+	Sanitize    bool           // Whether names are sanitized, implied by a pattern or replacement.
+	Pattern     *regexp.Regexp // Matches the parts of a name to replace, defaultBranchNamePattern if nil.
+	Replacement *string        // Replaces the matches of Pattern, "-" if nil.
+	Lowercase   bool           // Whether names are converted to lower case.
+}
+
+// branchNameConfig is applied to the names of local branches used with --use-local-branch-name.
+var branchNameConfig BranchNameConfig
+
+// branchNameSettings are the keys of the branch name settings in the phrase block, with the schema of their values.
+// branch_name_sanitize comes last, as it overrides the sanitizing implied by a pattern or replacement.
+var branchNameSettings = []struct {
+	key    string
+	schema jsonSchema
+}{
+	{"branch_name_pattern", jsonSchema{"type": "string", "format": "regex"}},
+	{"branch_name_replacement", jsonSchema{"type": "string"}},
+	{"branch_name_lowercase", jsonSchema{"type": "boolean"}},
+	{"branch_name_sanitize", jsonSchema{"type": "boolean"}},
+}
+
+// extract reads and removes the branch name settings from the given phrase block.
+func (bc *BranchNameConfig) extract(block map[string]interface{}) error {
+	for _, setting := range branchNameSettings {
+		v, found := block[setting.key]
+		if !found {
+			continue
+		}
+		delete(block, setting.key)
+
+		if err := bc.set(setting.key, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// set validates the value of the setting with the given key and applies it.
+func (bc *BranchNameConfig) set(key string, v interface{}) error {
+	var err error
+	switch key {
+	case "branch_name_sanitize":
+		bc.Sanitize, err = phraseapp.ValidateIsBool(key, v)
+	case "branch_name_pattern":
+		pattern, err := phraseapp.ValidateIsString(key, v)
+		if err != nil {
+			return err
+		}
+		if pattern != "" {
+			if bc.Pattern, err = regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("branch_name_pattern is no valid regular expression: %s", err)
 			}
+			bc.Sanitize = true
+		}
+	case "branch_name_replacement":
+		replacement, err := phraseapp.ValidateIsString(key, v)
+		if err != nil {
+			return err
+		}
+		bc.Replacement = &replacement
+		bc.Sanitize = true
+	case "branch_name_lowercase":
+		bc.Lowercase, err = phraseapp.ValidateIsBool(key, v)
+	default:
+		return fmt.Errorf("unknown branch name setting %q", key)
+	}
+	return err
+}
 
-			return mercurialBranch, nil
-		} else if gitErr != nil {
-			return "", gitErr
+// sanitize returns the branch name to use for a local branch, e.g. feature-ABC-1 for feature/ABC-1 if sanitizing.
+func (bc BranchNameConfig) sanitize(name string) string {
+	if bc.Sanitize {
+		pattern, replacement := bc.Pattern, "-"
+		if pattern == nil {
+			pattern = defaultBranchNamePattern
+		}
+		if bc.Replacement != nil {
+			replacement = *bc.Replacement
+		}
+		name = pattern.ReplaceAllString(name, replacement)
+		// replacements at the start or end, e.g. for a trailing slash, are left out
+		for replacement != "" && strings.HasPrefix(name, replacement) {
+			name = strings.TrimPrefix(name, replacement)
 		}
+		for replacement != "" && strings.HasSuffix(name, replacement) {
+			name = strings.TrimSuffix(name, replacement)
+		}
+	}
+	if bc.Lowercase {
+		name = strings.ToLower(name)
+	}
+	return name
+}
 
-		return gitBranch, nil
+func usedBranchName(useLocalBranchNameFlag bool, branchParam string) (string, error) {
+	if useLocalBranchName(useLocalBranchNameFlag) && branchParam == "" {
+		branch, err := localBranchName()
+		if err != nil {
+			return "", err
+		}
+		return branchNameConfig.sanitize(branch), nil
 	}
 
 	return branchParam, nil
 }
 
+// localBranchName returns the name of the branch being built on CI or checked out locally.
+func localBranchName() (string, error) {
+	if ciBranch := ciBranchName(); ciBranch != "" {
+		return ciBranch, nil
+	}
+
+	if gitBranch, err := checkedOutGitBranch(); err == nil && gitBranch != "" {
+		return gitBranch, nil
+	}
+
+	// CI images often come without git, but with the checkout of the repository.
+	if gitBranch, err := gitHeadBranch("."); err == nil && gitBranch != "" {
+		return gitBranch, nil
+	}
+
+	mercurialBranch, err := checkedOutMercurialBranch()
+	if err != nil || mercurialBranch == "" {
+		return "", errors.New("could not determine neither a git nor a mercurial branch")
+	}
+	return mercurialBranch, nil
+}
+
 func useLocalBranchName(useLocalBranchNameFlag bool) bool {
 	return os.Getenv("PHRASEAPP_USE_LOCAL_BRANCH_NAME") == "true" || useLocalBranchNameFlag
 }
 
+// ciBranchVariables are the environment variables CI services set to the name of the branch being built, in the
+// order they are looked at. The source branches of pull requests come first, as builds of pull requests check out
+// a merge commit. Variables with generic names are only looked at if the marker variable of their service is set.
+var ciBranchVariables = []struct {
+	name, marker string
+}{
+	{"GITHUB_HEAD_REF", ""},                     // GitHub Actions, pull requests
+	{"CI_MERGE_REQUEST_SOURCE_BRANCH_NAME", ""}, // GitLab, merge requests
+	{"CI_COMMIT_REF_NAME", ""},                  // GitLab
+	{"BITBUCKET_BRANCH", ""},                    // Bitbucket Pipelines
+	{"CHANGE_BRANCH", "JENKINS_URL"},            // Jenkins, pull requests
+	{"BRANCH_NAME", "JENKINS_URL"},              // Jenkins multibranch pipelines
+	{"GIT_BRANCH", "JENKINS_URL"},               // Jenkins git plugin, e.g. origin/main
+	{"CIRCLE_BRANCH", ""},                       // CircleCI
+}
+
+// ciBranchName returns the name of the branch being built if running on one of the known CI services.
+func ciBranchName() string {
+	for _, v := range ciBranchVariables {
+		if v.marker != "" && os.Getenv(v.marker) == "" {
+			continue
+		}
+		if branch := os.Getenv(v.name); branch != "" {
+			if v.name == "GIT_BRANCH" {
+				branch = strings.TrimPrefix(branch, "origin/")
+			}
+			return branch
+		}
+	}
+
+	// GitHub Actions builds of branches only set the full ref.
+	if ref := os.Getenv("GITHUB_REF"); strings.HasPrefix(ref, "refs/heads/") {
+		return strings.TrimPrefix(ref, "refs/heads/")
+	}
+	return ""
+}
+
 func checkedOutGitBranch() (string, error) {
 	gitPath := os.Getenv("PHRASEAPP_GIT_BINARY")
 	if gitPath == "" {
@@ -57,6 +212,65 @@ func checkedOutGitBranch() (string, error) {
 	return gitBranch, nil
 }
 
+// gitHeadBranch returns the branch checked out in the git repository containing dir by reading its HEAD file. In
+// worktrees and submodules .git is a file pointing to the directory with the HEAD file.
+func gitHeadBranch(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		gitDir := filepath.Join(dir, ".git")
+		info, err := os.Stat(gitDir)
+		if err == nil {
+			if !info.IsDir() {
+				if gitDir, err = readGitDirFile(gitDir); err != nil {
+					return "", err
+				}
+			}
+			return readGitHead(gitDir)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.New("no git repository found")
+		}
+		dir = parent
+	}
+}
+
+// readGitDirFile returns the directory a .git file points to with its "gitdir: <path>" line.
+func readGitDirFile(path string) (string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	line := strings.TrimSpace(string(content))
+	if !strings.HasPrefix(line, "gitdir:") {
+		return "", fmt.Errorf("%s: gitdir line missing", path)
+	}
+	gitDir := strings.TrimSpace(strings.TrimPrefix(line, "gitdir:"))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(path), gitDir)
+	}
+	return gitDir, nil
+}
+
+func readGitHead(gitDir string) (string, error) {
+	content, err := ioutil.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return "", err
+	}
+
+	head := strings.TrimSpace(string(content))
+	if !strings.HasPrefix(head, "ref: refs/heads/") {
+		return "", errors.New("no git branched checked out")
+	}
+	return strings.TrimPrefix(head, "ref: refs/heads/"), nil
+}
+
 func checkedOutMercurialBranch() (string, error) {
 	hgPath := os.Getenv("PHRASEAPP_MERCURIAL_BINARY")
 	if hgPath == "" {
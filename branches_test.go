@@ -0,0 +1,129 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCIBranchName(t *testing.T) {
+	names := []string{"GITHUB_REF", "JENKINS_URL"}
+	for _, v := range ciBranchVariables {
+		names = append(names, v.name)
+	}
+	for _, name := range names {
+		defer os.Setenv(name, os.Getenv(name))
+		os.Unsetenv(name)
+	}
+
+	if got := ciBranchName(); got != "" {
+		t.Errorf("expected no branch outside of CI, got %q", got)
+	}
+
+	os.Setenv("GITHUB_REF", "refs/heads/feature/a")
+	if got := ciBranchName(); got != "feature/a" {
+		t.Errorf("expected %q, got %q", "feature/a", got)
+	}
+
+	// generic names are ignored outside of Jenkins
+	os.Setenv("GIT_BRANCH", "origin/feature/b")
+	if got := ciBranchName(); got != "feature/a" {
+		t.Errorf("expected %q, got %q", "feature/a", got)
+	}
+
+	os.Setenv("JENKINS_URL", "https://jenkins.example.com/")
+	if got := ciBranchName(); got != "feature/b" {
+		t.Errorf("expected %q, got %q", "feature/b", got)
+	}
+
+	os.Setenv("GITHUB_HEAD_REF", "feature/c")
+	if got := ciBranchName(); got != "feature/c" {
+		t.Errorf("expected %q, got %q", "feature/c", got)
+	}
+}
+
+func TestGitHeadBranch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "phraseapp-branches")
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeFile := func(path, content string) {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("didn't expect an error, got: %s", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("didn't expect an error, got: %s", err)
+		}
+	}
+
+	writeFile("repo/.git/HEAD", "ref: refs/heads/feature/a\n")
+	writeFile("repo/.git/worktrees/wt/HEAD", "ref: refs/heads/feature/b\n")
+	writeFile("wt/.git", "gitdir: ../repo/.git/worktrees/wt\n")
+	writeFile("detached/.git/HEAD", "0123456789abcdef0123456789abcdef01234567\n")
+	if err := os.MkdirAll(filepath.Join(dir, "repo", "config", "locales"), 0755); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+
+	tests := []struct {
+		dir string
+		exp string
+	}{
+		{"repo", "feature/a"},
+		{"repo/config/locales", "feature/a"},
+		{"wt", "feature/b"},
+	}
+	for _, test := range tests {
+		got, err := gitHeadBranch(filepath.Join(dir, test.dir))
+		if err != nil {
+			t.Errorf("%s: didn't expect an error, got: %s", test.dir, err)
+		} else if got != test.exp {
+			t.Errorf("%s: expected %q, got %q", test.dir, test.exp, got)
+		}
+	}
+
+	if _, err := gitHeadBranch(filepath.Join(dir, "detached")); err == nil {
+		t.Errorf("expected an error for a detached HEAD")
+	}
+}
+
+func TestBranchNameSanitize(t *testing.T) {
+	underscore := "_"
+	tests := []struct {
+		block map[string]interface{}
+		name  string
+		exp   string
+	}{
+		{map[string]interface{}{}, "feature/ABC-1", "feature/ABC-1"},
+		{map[string]interface{}{"branch_name_sanitize": true}, "feature/ABC-1", "feature-ABC-1"},
+		{map[string]interface{}{"branch_name_sanitize": true}, "feature/ä ö/", "feature"},
+		{map[string]interface{}{"branch_name_lowercase": true}, "feature/ABC-1", "feature/abc-1"},
+		{map[string]interface{}{"branch_name_sanitize": true, "branch_name_lowercase": true}, "feature/ABC-1", "feature-abc-1"},
+		{map[string]interface{}{"branch_name_replacement": underscore}, "feature/ABC-1", "feature_ABC-1"},
+		{map[string]interface{}{"branch_name_replacement": "--"}, "/feature/ABC-1/", "feature--ABC-1"},
+		{map[string]interface{}{"branch_name_replacement": "ab"}, "/b/", "b"},
+		{map[string]interface{}{"branch_name_pattern": "[/]"}, "feature/ABC 1", "feature-ABC 1"},
+		{map[string]interface{}{"branch_name_pattern": "[/]", "branch_name_sanitize": false}, "feature/ABC 1", "feature/ABC 1"},
+	}
+
+	for _, test := range tests {
+		bc := BranchNameConfig{}
+		if err := bc.extract(test.block); err != nil {
+			t.Fatalf("didn't expect an error, got: %s", err)
+		}
+		if len(test.block) != 0 {
+			t.Errorf("expected the settings to be removed, got %v", test.block)
+		}
+		if got := bc.sanitize(test.name); got != test.exp {
+			t.Errorf("%v: expected %q, got %q", test.block, test.exp, got)
+		}
+	}
+
+	bc := BranchNameConfig{}
+	if err := bc.extract(map[string]interface{}{"branch_name_pattern": "["}); err == nil {
+		t.Errorf("expected an error for an invalid pattern")
+	}
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/phrase/phraseapp-client/internal/print"
+	"github.com/phrase/phraseapp-go/phraseapp"
+)
+
+const defaultCacheMaxSize = 100 // megabytes
+
+// CacheConfig contains the settings for caching API responses on disk.
+type CacheConfig struct {
+	Enabled bool
+	Dir     string // Directory of the cache, by default phraseapp in the user's cache directory.
+	MaxSize int64  // Size limit in bytes, the least recently stored responses are removed when exceeding it.
+}
+
+// cacheConfig is applied to all clients created with newClient. Caching is enabled with the global --cache option or
+// "cache: true" in the config file.
+var cacheConfig CacheConfig
+
+// cacheSettings are the keys of the cache settings in the phrase block, with the schema of their values.
+var cacheSettings = []struct {
+	key    string
+	schema jsonSchema
+}{
+	{"cache", jsonSchema{"type": "boolean"}},
+	{"cache_dir", jsonSchema{"type": "string"}},
+	{"cache_max_size", jsonSchema{"type": "integer", "minimum": 1}},
+}
+
+// extract reads and removes the cache settings from the given phrase block.
+func (cc *CacheConfig) extract(block map[string]interface{}, baseDir string) error {
+	for _, setting := range cacheSettings {
+		v, found := block[setting.key]
+		if !found {
+			continue
+		}
+		delete(block, setting.key)
+
+		if err := cc.set(setting.key, v, baseDir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// set validates the value of the setting with the given key and applies it. A relative cache_dir is resolved against
+// baseDir.
+func (cc *CacheConfig) set(key string, v interface{}, baseDir string) error {
+	var err error
+	switch key {
+	case "cache":
+		cc.Enabled, err = phraseapp.ValidateIsBool(key, v)
+	case "cache_dir":
+		if cc.Dir, err = phraseapp.ValidateIsString(key, v); err != nil {
+			return err
+		}
+		if baseDir != "" && cc.Dir != "" && !filepath.IsAbs(cc.Dir) {
+			cc.Dir = filepath.Join(baseDir, cc.Dir)
+		}
+	case "cache_max_size":
+		megabytes, err := phraseapp.ValidateIsInt(key, v)
+		if err != nil {
+			return err
+		}
+		if megabytes <= 0 {
+			return fmt.Errorf("cache_max_size must be a positive number of megabytes, was %d", megabytes)
+		}
+		cc.MaxSize = int64(megabytes) * 1024 * 1024
+	default:
+		return fmt.Errorf("unknown cache setting %q", key)
+	}
+	return err
+}
+
+func (cc CacheConfig) dir() (string, error) {
+	if cc.Dir != "" {
+		return cc.Dir, nil
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "phraseapp"), nil
+}
+
+func (cc CacheConfig) maxSize() int64 {
+	if cc.MaxSize > 0 {
+		return cc.MaxSize
+	}
+	return defaultCacheMaxSize * 1024 * 1024
+}
+
+// cacheEntry is a response stored in the cache.
+type cacheEntry struct {
+	URL        string
+	ETag       string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// cacheTransport caches the responses of GET requests with an ETag. Cached responses are revalidated with the API on
+// every request, so they are only used if the API confirms they are still up to date.
+type cacheTransport struct {
+	Transport http.RoundTripper // Transport used to send the requests, http.DefaultTransport if nil.
+	Dir       string
+	MaxSize   int64
+}
+
+func newCacheTransport(transport http.RoundTripper, cc CacheConfig) (*cacheTransport, error) {
+	dir, err := cc.dir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &cacheTransport{Transport: transport, Dir: dir, MaxSize: cc.maxSize()}, nil
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	if req.Method != "" && req.Method != "GET" {
+		return transport.RoundTrip(req)
+	}
+
+	path := filepath.Join(t.Dir, cacheKey(req))
+	entry := readCacheEntry(path)
+	if entry != nil {
+		// Don't modify the request given, as demanded by http.RoundTripper.
+		revalidation := *req
+		revalidation.Header = req.Header.Clone()
+		revalidation.Header.Set("If-None-Match", entry.ETag)
+		req = &revalidation
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		if Debug {
+			fmt.Fprintf(os.Stderr, "Using cached response for %s\n", req.URL)
+		}
+		resp.Body.Close()
+		now := time.Now()
+		os.Chtimes(path, now, now)
+		return entry.response(req), nil
+	}
+
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") == "" {
+		return resp, nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	entry = &cacheEntry{URL: req.URL.String(), ETag: resp.Header.Get("ETag"), StatusCode: resp.StatusCode, Header: resp.Header, Body: body}
+	if err := t.store(path, entry); err != nil && Debug {
+		fmt.Fprintf(os.Stderr, "Failed to cache response for %s: %s\n", req.URL, err)
+	}
+	return resp, nil
+}
+
+// cacheKey identifies the response to a request. The credentials are part of the key, so that responses aren't
+// shared between users with different permissions.
+func cacheKey(req *http.Request) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s", req.URL, req.Header.Get("Authorization"), req.Header.Get("Accept"))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func readCacheEntry(path string) *cacheEntry {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	entry := &cacheEntry{}
+	if err := gob.NewDecoder(f).Decode(entry); err != nil || entry.ETag == "" {
+		return nil
+	}
+	return entry
+}
+
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header,
+		Body:          ioutil.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+func (t *cacheTransport) store(path string, entry *cacheEntry) error {
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(entry); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so that concurrent runs never read partially written entries.
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	return t.evict()
+}
+
+// evict removes the least recently used entries until the cache doesn't exceed its size limit anymore.
+func (t *cacheTransport) evict() error {
+	files, err := cacheFiles(t.Dir)
+	if err != nil {
+		return err
+	}
+
+	var size int64
+	for _, f := range files {
+		size += f.Size()
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].ModTime().Before(files[j].ModTime()) })
+	for _, f := range files {
+		if size <= t.MaxSize {
+			break
+		}
+		if err := os.Remove(filepath.Join(t.Dir, f.Name())); err != nil {
+			return err
+		}
+		size -= f.Size()
+	}
+	return nil
+}
+
+func cacheFiles(dir string) ([]os.FileInfo, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	files := []os.FileInfo{}
+	for _, info := range infos {
+		if info.Mode().IsRegular() && filepath.Ext(info.Name()) == "" {
+			files = append(files, info)
+		}
+	}
+	return files, nil
+}
+
+type CacheClearCommand struct {
+	cfg CacheConfig
+}
+
+func (cmd *CacheClearCommand) Run() error {
+	dir, err := cmd.cfg.dir()
+	if err != nil {
+		return err
+	}
+
+	files, err := cacheFiles(dir)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := os.Remove(filepath.Join(dir, f.Name())); err != nil {
+			return err
+		}
+	}
+
+	print.Success("Removed %d cached responses from %s", len(files), dir)
+	return nil
+}
+
+type CacheStatsCommand struct {
+	cfg CacheConfig
+}
+
+func (cmd *CacheStatsCommand) Run() error {
+	dir, err := cmd.cfg.dir()
+	if err != nil {
+		return err
+	}
+
+	files, err := cacheFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	var size int64
+	var oldest, newest time.Time
+	for _, f := range files {
+		size += f.Size()
+		if oldest.IsZero() || f.ModTime().Before(oldest) {
+			oldest = f.ModTime()
+		}
+		if f.ModTime().After(newest) {
+			newest = f.ModTime()
+		}
+	}
+
+	enabled := "no (enable with --cache or 'cache: true' in the config file)"
+	if cmd.cfg.Enabled {
+		enabled = "yes"
+	}
+
+	fmt.Printf("Enabled:   %s\n", enabled)
+	fmt.Printf("Directory: %s\n", dir)
+	fmt.Printf("Entries:   %d\n", len(files))
+	fmt.Printf("Size:      %s of %s\n", formatSize(size), formatSize(cmd.cfg.maxSize()))
+	if len(files) > 0 {
+		fmt.Printf("Oldest:    %s\n", oldest.Format(time.RFC3339))
+		fmt.Printf("Newest:    %s\n", newest.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func formatSize(bytes int64) string {
+	switch {
+	case bytes >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(bytes)/(1024*1024))
+	case bytes >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(bytes)/1024)
+	}
+	return fmt.Sprintf("%d bytes", bytes)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/phrase/phraseapp-go/phraseapp"
+)
+
+func TestCacheTransport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "phraseapp-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	downloads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("en:\n  hello: Hello\n"))
+	}))
+	defer server.Close()
+
+	transport, err := newCacheTransport(nil, CacheConfig{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: transport}
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL + "/locales/en/download")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK || string(body) != "en:\n  hello: Hello\n" {
+			t.Errorf("request %d: expected the locale to be returned, got %d %q", i+1, resp.StatusCode, body)
+		}
+	}
+
+	if downloads != 1 {
+		t.Errorf("expected the locale to be downloaded once, got %d downloads", downloads)
+	}
+
+	resp, err := client.Post(server.URL+"/uploads", "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if downloads != 2 {
+		t.Errorf("expected POST requests to bypass the cache")
+	}
+}
+
+func TestCacheTransportEvict(t *testing.T) {
+	dir, err := ioutil.TempDir("", "phraseapp-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", r.URL.Path)
+		w.Write(make([]byte, 600))
+	}))
+	defer server.Close()
+
+	transport := &cacheTransport{Dir: dir, MaxSize: 1024}
+	client := &http.Client{Transport: transport}
+	for _, path := range []string{"/a", "/b", "/c"} {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	files, err := cacheFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("expected entries to be evicted down to the size limit, got %d entries", len(files))
+	}
+}
+
+func TestConfigParseCache(t *testing.T) {
+	cfg := &Config{Config: new(phraseapp.Config), Path: "/project/.phraseapp.yml"}
+	err := cfg.parse([]byte("phrase:\n  cache: true\n  cache_dir: tmp/cache\n  cache_max_size: 20\n"))
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+
+	expected := CacheConfig{Enabled: true, Dir: "/project/tmp/cache", MaxSize: 20 * 1024 * 1024}
+	if cfg.Cache != expected {
+		t.Errorf("expected cache config %+v, got %+v", expected, cfg.Cache)
+	}
+
+	if err := cfg.parse([]byte("phrase:\n  cache_max_size: 0\n")); err == nil {
+		t.Errorf("expected an error for an invalid size limit")
+	}
+}
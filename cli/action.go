@@ -15,6 +15,7 @@ type action struct {
 	args        []*argument        // List of arguments accepted.
 	runner      Runner             // Who's connected to the action.
 	description string             // Description of the action.
+	hidden      bool               // Whether the action is left out of help, routes and completion.
 	value       reflect.Value
 }
 
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// An alias is a shortcut for a command line, like a route with some options or arguments already given.
+type alias struct {
+	command string   // Command line as given on registration.
+	args    []string // Command line split into arguments.
+}
+
+// Register name as alias for the given command line. The command line is split into arguments like a shell would do,
+// i.e. single and double quotes can be used to group words. Aliases are expanded before a route is looked up and may
+// refer to other aliases.
+func (r *Router) RegisterAlias(name, command string) error {
+	if name == "" || strings.IndexFunc(name, unicode.IsSpace) >= 0 || strings.Contains(name, "/") {
+		return fmt.Errorf("invalid alias name %q", name)
+	}
+
+	if _, found := r.root.children[name]; found {
+		return fmt.Errorf("alias %q conflicts with the command of the same name", name)
+	}
+
+	args, e := SplitCommandLine(command)
+	if e != nil {
+		return fmt.Errorf("alias %q: %s", name, e)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("alias %q has an empty command", name)
+	}
+
+	r.aliases[name] = &alias{command: command, args: args}
+	return nil
+}
+
+// Replace a leading alias in args with the command line it stands for, until the first argument is no alias anymore.
+// An alias that (directly or via other aliases) expands to itself results in an error.
+func (r *Router) expandAliases(args []string) ([]string, error) {
+	seen := map[string]bool{}
+	for len(args) > 0 {
+		a, found := r.aliases[args[0]]
+		if !found {
+			break
+		}
+		if seen[args[0]] {
+			return nil, fmt.Errorf("alias %q is defined recursively", args[0])
+		}
+		seen[args[0]] = true
+
+		expanded := make([]string, 0, len(a.args)+len(args)-1)
+		expanded = append(expanded, a.args...)
+		args = append(expanded, args[1:]...)
+	}
+	return args, nil
+}
+
+func (r *Router) showAliases() {
+	if len(r.aliases) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(r.aliases))
+	for name := range r.aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	t := &table{}
+	for _, name := range names {
+		t.addRow(row{"  " + name, r.aliases[name].command})
+	}
+
+	fmt.Fprintln(DefaultWriter, "ALIASES")
+	fmt.Fprintln(DefaultWriter, t)
+}
+
+// SplitCommandLine splits the given command line into arguments. Whitespace separates arguments, unless quoted with
+// single or double quotes. A backslash escapes the following character outside of single quotes.
+func SplitCommandLine(line string) ([]string, error) {
+	args := []string{}
+	current := &strings.Builder{}
+	inArg := false
+	var quote rune
+	escaped := false
+
+	for _, c := range line {
+		switch {
+		case escaped:
+			current.WriteRune(c)
+			escaped = false
+		case c == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				current.WriteRune(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inArg = true
+		case unicode.IsSpace(c):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(c)
+			inArg = true
+		}
+	}
+
+	switch {
+	case escaped:
+		return nil, fmt.Errorf("unfinished escape sequence at end of %q", line)
+	case quote != 0:
+		return nil, fmt.Errorf("missing closing %c in %q", quote, line)
+	case inArg:
+		args = append(args, current.String())
+	}
+	return args, nil
+}
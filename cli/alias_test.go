@@ -0,0 +1,201 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type AliasTestAction struct {
+	Flag  bool   `cli:"opt --flag"`
+	Query string `cli:"opt -q"`
+	ID    string `cli:"arg"`
+}
+
+func (a *AliasTestAction) Run() error {
+	return nil
+}
+
+func TestSplitCommandLine(t *testing.T) {
+	Convey("SplitCommandLine", t, func() {
+		for line, expected := range map[string][]string{
+			"":                                  {},
+			"pull --use-local-branch-name":      {"pull", "--use-local-branch-name"},
+			"  keys   search  ":                 {"keys", "search"},
+			"keys search -q 'translated:false'": {"keys", "search", "-q", "translated:false"},
+			`a "b c" 'd "e"' f\ g`:              {"a", "b c", `d "e"`, "f g"},
+			`a '' ""`:                           {"a", "", ""},
+		} {
+			args, e := SplitCommandLine(line)
+			So(e, ShouldBeNil)
+			So(args, ShouldResemble, expected)
+		}
+
+		for _, line := range []string{`a 'b`, `a "b`, `a \`} {
+			_, e := SplitCommandLine(line)
+			So(e, ShouldNotBeNil)
+		}
+	})
+}
+
+func TestAliases(t *testing.T) {
+	Convey("Given a router with an action and some aliases", t, func() {
+		a := &AliasTestAction{}
+		r := NewRouter()
+		r.Register("keys/search", a, "search keys")
+
+		So(r.RegisterAlias("missing-de", "keys search -q 'translated:false'"), ShouldBeNil)
+		So(r.RegisterAlias("md", "missing-de --flag"), ShouldBeNil)
+		So(r.RegisterAlias("loop", "loop2"), ShouldBeNil)
+		So(r.RegisterAlias("loop2", "loop"), ShouldBeNil)
+
+		Convey("When an alias is run with an additional argument", func() {
+			e := r.Run("missing-de", "project-id")
+			Convey("Then the expanded action is run", func() {
+				So(e, ShouldBeNil)
+				So(a.Query, ShouldEqual, "translated:false")
+				So(a.ID, ShouldEqual, "project-id")
+				So(a.Flag, ShouldBeFalse)
+			})
+		})
+
+		Convey("When an alias referring to another alias is run", func() {
+			e := r.Run("md")
+			Convey("Then both aliases are expanded", func() {
+				So(e, ShouldBeNil)
+				So(a.Query, ShouldEqual, "translated:false")
+				So(a.Flag, ShouldBeTrue)
+			})
+		})
+
+		Convey("When a recursive alias is run", func() {
+			e := r.Run("loop")
+			Convey("Then there is an error", func() {
+				So(e, ShouldNotBeNil)
+				So(e.Error(), ShouldEqual, `alias "loop" is defined recursively`)
+			})
+		})
+
+		Convey("When an alias with the name of a command is registered", func() {
+			e := r.RegisterAlias("keys", "keys search")
+			Convey("Then there is an error", func() {
+				So(e, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When no route matches", func() {
+			old := DefaultWriter
+			buf := &bytes.Buffer{}
+			DefaultWriter = buf
+			e := r.Run("unknown")
+			DefaultWriter = old
+			Convey("Then the aliases are listed in the help output", func() {
+				So(e, ShouldEqual, ErrorNoRoute)
+				So(buf.String(), ShouldContainSubstring, "ALIASES")
+				So(strings.Contains(buf.String(), "missing-de keys search -q 'translated:false'"), ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func TestHiddenRoutes(t *testing.T) {
+	Convey("Hidden routes", t, func() {
+		r := NewRouter()
+		r.Register("docs/generate", &AliasTestAction{}, "generate docs")
+		r.RegisterHidden("dev/fake-server", &AliasTestAction{}, "run fake server")
+
+		buf := &bytes.Buffer{}
+		DefaultWriter = buf
+		defer func() { DefaultWriter = os.Stderr }()
+
+		Convey("are left out of help, routes and completion", func() {
+			r.Run()
+			So(buf.String(), ShouldContainSubstring, "docs generate")
+			So(buf.String(), ShouldNotContainSubstring, "fake-server")
+
+			So(len(r.Routes()), ShouldEqual, 1)
+			So(r.Complete(nil, "d"), ShouldResemble, []string{"docs"})
+		})
+
+		Convey("are not matched fuzzy", func() {
+			route, _, e := r.Lookup("d", "g")
+			So(e, ShouldBeNil)
+			So(route.Path, ShouldEqual, "docs/generate")
+		})
+
+		Convey("can be run with their full path", func() {
+			So(r.Run("dev", "fake-server", "id"), ShouldBeNil)
+		})
+	})
+}
+
+func TestNestedRoutes(t *testing.T) {
+	Convey("Given a route registered below the route of another action", t, func() {
+		cleanup, restore := &AliasTestAction{}, &AliasTestAction{}
+		r := NewRouter()
+		r.Register("upload/cleanup", cleanup, "cleanup keys")
+		r.Register("upload/cleanup/restore", restore, "restore keys")
+
+		buf := &bytes.Buffer{}
+		DefaultWriter = buf
+		defer func() { DefaultWriter = os.Stderr }()
+
+		Convey("Then both actions are registered", func() {
+			So(buf.String(), ShouldEqual, "")
+			So(len(r.Routes()), ShouldEqual, 2)
+		})
+
+		Convey("When the nested route is run", func() {
+			e := r.Run("upload", "cleanup", "restore", "backup.json")
+			Convey("Then its action gets the arguments", func() {
+				So(e, ShouldBeNil)
+				So(restore.ID, ShouldEqual, "backup.json")
+				So(cleanup.ID, ShouldEqual, "")
+			})
+		})
+
+		Convey("When the parent route is run with an argument", func() {
+			e := r.Run("u", "c", "r")
+			Convey("Then the argument is not matched fuzzy against the nested route", func() {
+				So(e, ShouldBeNil)
+				So(cleanup.ID, ShouldEqual, "r")
+				So(restore.ID, ShouldEqual, "")
+			})
+		})
+
+		Convey("Then the nested route is listed in the help and completed", func() {
+			r.Run()
+			So(buf.String(), ShouldContainSubstring, "upload cleanup restore")
+			So(r.Complete([]string{"upload", "cleanup"}, "r"), ShouldResemble, []string{"restore"})
+			So(r.Complete([]string{"upload", "cleanup"}, "--f"), ShouldResemble, []string{"--flag"})
+		})
+
+		Convey("When the same route is registered again", func() {
+			r.Register("upload/cleanup", &AliasTestAction{}, "cleanup keys")
+			Convey("Then there is an error", func() {
+				So(buf.String(), ShouldContainSubstring, "already registered")
+			})
+		})
+	})
+}
+
+func TestGlobalOptions(t *testing.T) {
+	Convey("Given a router with global options", t, func() {
+		r := NewRouter()
+		r.Register("keys/search", &AliasTestAction{}, "search keys")
+		r.RegisterGlobalOption("--profile <name>", "use the named profile")
+
+		buf := &bytes.Buffer{}
+		DefaultWriter = buf
+		defer func() { DefaultWriter = os.Stderr }()
+
+		Convey("Then they are listed in the help output", func() {
+			So(r.Run(), ShouldEqual, ErrorNoRoute)
+			So(buf.String(), ShouldContainSubstring, "GLOBAL OPTIONS")
+			So(buf.String(), ShouldContainSubstring, "--profile <name>")
+		})
+	})
+}
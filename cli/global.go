@@ -0,0 +1,29 @@
+package cli
+
+import "fmt"
+
+// A globalOption is an option applying to all routes, handled by the application before the arguments are run.
+type globalOption struct {
+	usage       string // Option as given on the command line, e.g. "--profile <name>".
+	description string
+}
+
+// RegisterGlobalOption adds an option applying to all routes to the help. The router doesn't parse global options,
+// the application has to remove them from the arguments before running them.
+func (r *Router) RegisterGlobalOption(usage, description string) {
+	r.globalOptions = append(r.globalOptions, &globalOption{usage: usage, description: description})
+}
+
+func (r *Router) showGlobalOptions() {
+	if len(r.globalOptions) == 0 {
+		return
+	}
+
+	t := &table{}
+	for _, o := range r.globalOptions {
+		t.addRow(row{"  " + o.usage, o.description})
+	}
+
+	fmt.Fprintln(DefaultWriter, "GLOBAL OPTIONS")
+	fmt.Fprintln(DefaultWriter, t)
+}
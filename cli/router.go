@@ -10,7 +10,9 @@ import (
 // The basic datastructure used in cli. Actions are added for different paths to a router via the "Register" and
 // "RegisterFunc" methods. These actions can be executed using the "Run" and "RunWithArgs" methods.
 type Router struct {
-	root *routingTreeNode
+	root          *routingTreeNode
+	aliases       map[string]*alias
+	globalOptions []*globalOption
 
 	initFailed bool
 }
@@ -27,7 +29,7 @@ func New(routes ...func(*Router) error) (*Router, error) {
 
 // Create a new router that will be used to register and run the actions of the application.
 func NewRouter() *Router {
-	r := &Router{}
+	r := &Router{aliases: map[string]*alias{}}
 	r.root = &routingTreeNode{children: map[string]*routingTreeNode{}}
 	return r
 }
@@ -39,6 +41,11 @@ func (r *Router) Run(args ...string) (e error) {
 		fmt.Fprintln(DefaultWriter, "errors found during initialization")
 		os.Exit(1)
 	}
+	args, e = r.expandAliases(args)
+	if e != nil {
+		return e
+	}
+
 	// Find action and parse args.
 	node, args := r.findNode(args, true)
 	if node != nil && node.action != nil {
@@ -46,6 +53,9 @@ func (r *Router) Run(args ...string) (e error) {
 			node.showHelp()
 			return e
 		}
+	} else if node == r.root { // Failed to match even a single segment.
+		r.showHelp()
+		return ErrorNoRoute
 	} else { // Failed to find node.
 		node.showHelp()
 		return ErrorNoRoute
@@ -78,6 +88,16 @@ func (r *Router) RegisterFunc(path string, f func() error, desc string) {
 
 // Register the given action (some struct implementing the Runner interface) for the given route.
 func (r *Router) Register(path string, runner Runner, desc string) {
+	r.register(path, runner, desc, false)
+}
+
+// RegisterHidden registers the given action like Register, but leaves it out of the help, the routes and the
+// completion. Hidden actions can only be run using their full path, e.g. for development tools.
+func (r *Router) RegisterHidden(path string, runner Runner, desc string) {
+	r.register(path, runner, desc, true)
+}
+
+func (r *Router) register(path string, runner Runner, desc string, hidden bool) {
 	a, e := newAction(path, runner, desc)
 	if e != nil {
 		fmt.Fprintln(DefaultWriter, e)
@@ -87,18 +107,10 @@ func (r *Router) Register(path string, runner Runner, desc string) {
 
 	pathSegments := strings.Split(a.path, "/")
 	node, pathSegments := r.findNode(pathSegments, false)
-	if node != nil {
-		if node.action != nil {
-			fmt.Fprintf(DefaultWriter, "failed to register action for path %q: action for path %q already registered\n", a.path, node.action.path)
-			r.initFailed = true
-			return
-		} else if len(pathSegments) == 0 && len(node.children) > 0 {
-			fmt.Fprintf(DefaultWriter, "failed to register action for path %q: longer paths with this prefix exist\n", a.path)
-			r.initFailed = true
-			return
-		}
-	} else {
-		node = r.root
+	if len(pathSegments) == 0 && node.action != nil {
+		fmt.Fprintf(DefaultWriter, "failed to register action for path %q: action for path %q already registered\n", a.path, node.action.path)
+		r.initFailed = true
+		return
 	}
 
 	for _, p := range pathSegments {
@@ -107,14 +119,24 @@ func (r *Router) Register(path string, runner Runner, desc string) {
 		node = newNode
 	}
 
+	a.hidden = hidden
 	node.action = a
 }
 
 func (r *Router) showHelp() {
+	r.root.showHelp()
+	if len(r.globalOptions) > 0 {
+		fmt.Fprintln(DefaultWriter)
+		r.showGlobalOptions()
+	}
+	if len(r.aliases) > 0 {
+		fmt.Fprintln(DefaultWriter)
+		r.showAliases()
+	}
 }
 
-// A tree used for easy access to the matching action. An action can only be set if there are no children, i.e. only
-// leaf nodes can have actions.
+// A tree used for easy access to the matching action. Nodes with an action can have children as well, e.g. for
+// actions working on the result of their parent's action.
 type routingTreeNode struct {
 	children map[string]*routingTreeNode
 	action   *action
@@ -133,31 +155,46 @@ func (rt *routingTreeNode) showHelp() {
 func (rt *routingTreeNode) showTabularHelp(t *table) {
 	if rt.action != nil {
 		rt.action.showTabularHelp(t)
-	} else {
-		pathSegments := make([]string, 0, len(rt.children))
-		for k, _ := range rt.children {
-			pathSegments = append(pathSegments, k)
-		}
-		sort.Strings(pathSegments)
+	}
+
+	pathSegments := make([]string, 0, len(rt.children))
+	for k, _ := range rt.children {
+		pathSegments = append(pathSegments, k)
+	}
+	sort.Strings(pathSegments)
 
-		for _, ps := range pathSegments {
+	for _, ps := range pathSegments {
+		if !rt.children[ps].hidden() {
 			rt.children[ps].showTabularHelp(t)
 		}
 	}
 }
 
+// A node is hidden if its action is, or if all the nodes below it are.
+func (rt *routingTreeNode) hidden() bool {
+	if rt.action != nil {
+		return rt.action.hidden
+	}
+	for _, child := range rt.children {
+		if !child.hidden() {
+			return false
+		}
+	}
+	return len(rt.children) > 0
+}
+
 // Find the node matching most segments of the given path. Will return the according tree node and the remaining (non
-// matched) path segments.
+// matched) path segments. Below a node with an action segments are only matched exactly, as they might be arguments.
 func (r *Router) findNode(pathSegments []string, fuzzy bool) (*routingTreeNode, []string) {
 	node := r.root
 	for i, p := range pathSegments {
 		if c, found := node.children[p]; found {
 			node = c
 		} else {
-			if fuzzy { // try fuzzy search
+			if fuzzy && node.action == nil { // try fuzzy search
 				candidates := []string{}
-				for key, _ := range node.children {
-					if strings.HasPrefix(key, p) {
+				for key, child := range node.children {
+					if strings.HasPrefix(key, p) && !child.hidden() {
 						candidates = append(candidates, key)
 					}
 				}
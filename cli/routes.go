@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"sort"
+	"strings"
+)
+
+// Route describes a registered action, e.g. to generate documentation or to complete command lines.
+type Route struct {
+	Path        string // Path of the route with segments separated by "/".
+	Description string
+	Options     []*RouteOption
+	Arguments   []*RouteArgument
+	Runner      Runner // Runner registered for the route, e.g. to reflect on its fields.
+}
+
+// RouteOption describes an option of a route.
+type RouteOption struct {
+	Name        string // Name of the field the option is reflected into.
+	Short       string
+	Long        string
+	Description string
+	Default     string // Value preset when the route was registered.
+	Flag        bool
+	Required    bool
+}
+
+// RouteArgument describes an argument of a route.
+type RouteArgument struct {
+	Name        string // Name of the field the argument is reflected into.
+	Description string
+	Required    bool
+	Variadic    bool
+}
+
+// Option returns the option with the given long name, or nil if the route has no such option.
+func (rt *Route) Option(long string) *RouteOption {
+	for _, o := range rt.Options {
+		if o.Long == long {
+			return o
+		}
+	}
+	return nil
+}
+
+func (a *action) route() *Route {
+	rt := &Route{Path: a.path, Description: a.description, Runner: a.runner}
+	for _, o := range a.opts {
+		rt.Options = append(rt.Options, &RouteOption{
+			Name:        o.field,
+			Short:       o.short,
+			Long:        o.long,
+			Description: o.desc,
+			Default:     o.value,
+			Flag:        o.isFlag,
+			Required:    o.required,
+		})
+	}
+	for _, arg := range a.args {
+		rt.Arguments = append(rt.Arguments, &RouteArgument{
+			Name:        arg.field,
+			Description: arg.desc,
+			Required:    arg.required,
+			Variadic:    arg.variadic,
+		})
+	}
+	return rt
+}
+
+// Routes returns all registered routes sorted by path, except the hidden ones.
+func (r *Router) Routes() []*Route {
+	routes := []*Route{}
+	r.root.walk(func(a *action) {
+		if !a.hidden {
+			routes = append(routes, a.route())
+		}
+	})
+	sort.Slice(routes, func(i, j int) bool { return routes[i].Path < routes[j].Path })
+	return routes
+}
+
+// Lookup returns the route the given arguments would be run against, together with the arguments remaining after the
+// route's path. Aliases are expanded and route segments are matched fuzzy like Run does. ErrorNoRoute is returned if
+// the arguments don't match any route.
+func (r *Router) Lookup(args ...string) (*Route, []string, error) {
+	args, e := r.expandAliases(args)
+	if e != nil {
+		return nil, nil, e
+	}
+
+	node, args := r.findNode(args, true)
+	if node.action == nil {
+		return nil, nil, ErrorNoRoute
+	}
+	return node.action.route(), args, nil
+}
+
+// Complete returns the candidates for word, given the preceding arguments of a command line. Candidates are the
+// route segments (and aliases) following the arguments, or, if these already select a route, its options and the
+// segments of the routes below it.
+func (r *Router) Complete(args []string, word string) []string {
+	args, e := r.expandAliases(args)
+	if e != nil {
+		return nil
+	}
+
+	node, rest := r.findNode(args, true)
+	candidates := []string{}
+
+	switch {
+	case node.action != nil && strings.HasPrefix(word, "-"):
+		for _, o := range node.action.opts {
+			if o.long != "" {
+				candidates = append(candidates, "--"+o.long)
+			}
+		}
+	case len(rest) == 0:
+		for segment, child := range node.children {
+			if !child.hidden() {
+				candidates = append(candidates, segment)
+			}
+		}
+		if node == r.root {
+			for name := range r.aliases {
+				candidates = append(candidates, name)
+			}
+		}
+	}
+
+	matching := []string{}
+	for _, c := range candidates {
+		if strings.HasPrefix(c, word) {
+			matching = append(matching, c)
+		}
+	}
+	sort.Strings(matching)
+	return matching
+}
+
+func (rt *routingTreeNode) walk(f func(*action)) {
+	if rt.action != nil {
+		f(rt.action)
+	}
+	for _, child := range rt.children {
+		child.walk(f)
+	}
+}
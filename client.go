@@ -1,32 +1,73 @@
 package main
 
 import (
-	"crypto/tls"
-	"net"
 	"net/http"
 	"os"
-	"time"
 
 	"github.com/phrase/phraseapp-go/phraseapp"
 )
 
+type sessionKey struct {
+	creds phraseapp.Credentials
+	debug bool
+}
+
+// sessionClients is set while an interactive shell runs, so that all commands run in it share their clients instead
+// of authenticating on their own.
+var sessionClients map[sessionKey]*phraseapp.Client
+
 func newClient(creds phraseapp.Credentials, debug bool) (*phraseapp.Client, error) {
+	key := sessionKey{creds: creds, debug: debug}
+	if c, found := sessionClients[key]; found {
+		return c, nil
+	}
+
+	c, err := buildClient(creds, debug)
+	if err != nil {
+		return nil, err
+	}
+
+	if sessionClients != nil {
+		sessionClients[key] = c
+	}
+	return c, nil
+}
+
+func buildClient(creds phraseapp.Credentials, debug bool) (*phraseapp.Client, error) {
+	if ReplayDir != "" && creds.Token == "" && creds.Username == "" {
+		// Replayed requests don't need valid credentials, as the recorded ones are scrubbed anyway.
+		creds.Token = scrubbedValue
+	}
+	if creds.Token == "" && creds.Username == "" && os.Getenv("PHRASEAPP_ACCESS_TOKEN") == "" {
+		token, err := storedToken(creds.Host)
+		if err != nil {
+			return nil, err
+		}
+		creds.Token = token
+	}
+
 	c, err := phraseapp.NewClient(creds, debug)
 	if err != nil {
 		return nil, err
 	}
-	if os.Getenv("PHRASEAPP_INSECURE_SKIP_VERIFY") == "true" {
-		tr := &http.Transport{
-			Proxy: http.ProxyFromEnvironment,
-			Dial: (&net.Dialer{
-				Timeout:   30 * time.Second,
-				KeepAlive: 30 * time.Second,
-			}).Dial,
-			TLSHandshakeTimeout:   10 * time.Second,
-			ExpectContinueTimeout: 1 * time.Second,
-			TLSClientConfig:       &tls.Config{InsecureSkipVerify: true},
+
+	tr, err := newTransport(transportConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var transport http.RoundTripper
+	if transport, err = fixturesTransport(tr); err != nil {
+		return nil, err
+	}
+	transport = newRetryTransport(transport, MaxRetries, RetryTimeout)
+	if cacheConfig.Enabled {
+		transport, err = newCacheTransport(transport, cacheConfig)
+		if err != nil {
+			return nil, err
 		}
-		c.Client = http.Client{Transport: tr}
 	}
+
+	c.Client = http.Client{Transport: transport}
 	return c, nil
 }
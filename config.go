@@ -0,0 +1,369 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/phrase/phraseapp-go/phraseapp"
+	yaml "gopkg.in/yaml.v2"
+)
+
+var configNames = []string{".phrase.yml", ".phraseapp.yml"}
+
+// Config contains all information from a .phraseapp.yml config file. Besides the settings handled by the phraseapp
+// library it holds the ones only known to the client.
+type Config struct {
+	*phraseapp.Config
+
+	// Path of the config file used, empty if none was found.
+	Path string
+
+	// Aliases maps the name of an alias to the command line it stands for.
+	Aliases map[string]string
+
+	// Transport contains the settings for the connection to the API.
+	Transport TransportConfig
+
+	// Cache contains the settings for caching API responses on disk.
+	Cache CacheConfig
+
+	// BranchNames contains the settings for turning the names of local branches into valid branch names.
+	BranchNames BranchNameConfig
+
+	// Profiles maps the name of a profile to the settings it overrides in the phrase block.
+	Profiles map[string]map[string]interface{}
+
+	// Profile is the name of the selected profile, taken from PHRASEAPP_PROFILE or the global --profile option.
+	Profile string
+
+	// base is the phrase block without the client settings, the profiles are applied to.
+	base map[string]interface{}
+
+	// origins maps the keys of the phrase block to where their values came from, e.g. "file", "env PHRASEAPP_PROXY"
+	// or "flag --cache". Keys without origin have their default value.
+	origins map[string]string
+}
+
+// setOrigin records where the value of the given key came from.
+func (cfg *Config) setOrigin(key, origin string) {
+	if cfg.origins == nil {
+		cfg.origins = map[string]string{}
+	}
+	cfg.origins[key] = origin
+}
+
+// Origin returns where the value of the given key of the phrase block came from, or an empty string if the key has
+// its default value.
+func (cfg *Config) Origin(key string) string {
+	return cfg.origins[key]
+}
+
+// profileKeys are the settings of the phrase block a profile may override.
+var profileKeys = []string{"access_token", "host", "project_id", "push", "pull"}
+
+// ReadConfig reads the config file the same way phraseapp.ReadConfig does. The keys only known to the client are
+// removed from the phrase block before it is handed to the library, as that would reject them.
+func ReadConfig() (*Config, error) {
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{Config: new(phraseapp.Config), Path: path, Profile: os.Getenv("PHRASEAPP_PROFILE")}
+	if cfg.Profile != "" {
+		cfg.setOrigin("profile", "env PHRASEAPP_PROFILE")
+	}
+	if path != "" {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := cfg.parse(content); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, setting := range transportSettings {
+		if !cfg.Transport.isSet(setting.key) && os.Getenv(setting.env) != "" {
+			cfg.setOrigin(setting.key, "env "+setting.env)
+		}
+	}
+	if err := cfg.Transport.applyEnv(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (cfg *Config) parse(content []byte) error {
+	rawCfg := map[string]interface{}{}
+	if err := yaml.Unmarshal(content, &rawCfg); err != nil {
+		return err
+	}
+
+	var block map[string]interface{}
+	for _, key := range []string{"phrase", "phraseapp"} {
+		v, found := rawCfg[key]
+		if !found {
+			continue
+		}
+		if v == nil {
+			return nil
+		}
+
+		m, err := phraseapp.ValidateIsRawMap(key, v)
+		if err != nil {
+			return err
+		}
+		for k, v := range m {
+			// alias bodies are kept as written and profiles are expanded when applied, so that variables only an
+			// unused profile references needn't be set
+			if k != "aliases" && k != "profiles" {
+				if m[k], err = expandEnv(key+"."+k, v); err != nil {
+					return err
+				}
+			}
+			cfg.setOrigin(k, "file")
+		}
+		block = m
+		break
+	}
+
+	if block == nil {
+		return fmt.Errorf("'phrase' key is missing in config")
+	}
+
+	if err := cfg.extractClientSettings(block); err != nil {
+		return err
+	}
+
+	cfg.base = block
+	return cfg.unmarshal(block)
+}
+
+// unmarshal hands the given phrase block to the library.
+func (cfg *Config) unmarshal(block map[string]interface{}) error {
+	libContent, err := yaml.Marshal(block)
+	if err != nil {
+		return err
+	}
+
+	*cfg.Config = phraseapp.Config{}
+	return yaml.Unmarshal(libContent, cfg.Config)
+}
+
+// applyProfile overrides the settings of the phrase block with the ones of the selected profile. Settings the profile
+// leaves out are inherited from the phrase block.
+func (cfg *Config) applyProfile() error {
+	if cfg.Profile == "" {
+		return nil
+	}
+
+	profile, found := cfg.Profiles[cfg.Profile]
+	if !found {
+		names := make([]string, 0, len(cfg.Profiles))
+		for name := range cfg.Profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		if len(names) == 0 {
+			return fmt.Errorf("profile %q not found, the config doesn't define any profiles", cfg.Profile)
+		}
+		return fmt.Errorf("profile %q not found, available profiles: %s", cfg.Profile, strings.Join(names, ", "))
+	}
+
+	block := map[string]interface{}{}
+	for k, v := range cfg.base {
+		block[k] = v
+	}
+	for k, v := range profile {
+		expanded, err := expandEnv("profiles."+cfg.Profile+"."+k, v)
+		if err != nil {
+			return err
+		}
+		block[k] = expanded
+		cfg.setOrigin(k, "file, profile "+cfg.Profile)
+	}
+	return cfg.unmarshal(block)
+}
+
+// extractClientSettings reads and removes the keys only known to the client from the given phrase block.
+func (cfg *Config) extractClientSettings(block map[string]interface{}) error {
+	if v, found := block["aliases"]; found {
+		delete(block, "aliases")
+
+		rawAliases, err := phraseapp.ValidateIsRawMap("aliases", v)
+		if err != nil {
+			return err
+		}
+
+		cfg.Aliases = map[string]string{}
+		for name, rawCommand := range rawAliases {
+			cfg.Aliases[name], err = phraseapp.ValidateIsString("aliases."+name, rawCommand)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if v, found := block["profiles"]; found {
+		delete(block, "profiles")
+		if err := cfg.extractProfiles(v); err != nil {
+			return err
+		}
+	}
+
+	baseDir := ""
+	if cfg.Path != "" {
+		baseDir = filepath.Dir(cfg.Path)
+	}
+	if err := cfg.Transport.extract(block, baseDir); err != nil {
+		return err
+	}
+	if err := cfg.BranchNames.extract(block); err != nil {
+		return err
+	}
+	return cfg.Cache.extract(block, baseDir)
+}
+
+func (cfg *Config) extractProfiles(v interface{}) error {
+	rawProfiles, err := phraseapp.ValidateIsRawMap("profiles", v)
+	if err != nil {
+		return err
+	}
+
+	cfg.Profiles = map[string]map[string]interface{}{}
+	for name, rawProfile := range rawProfiles {
+		profile, err := phraseapp.ValidateIsRawMap("profiles."+name, rawProfile)
+		if err != nil {
+			return err
+		}
+
+		for key := range profile {
+			if !isProfileKey(key) {
+				return fmt.Errorf("configuration key %q unknown, profiles can only override %s", "profiles."+name+"."+key, strings.Join(profileKeys, ", "))
+			}
+		}
+		cfg.Profiles[name] = profile
+	}
+	return nil
+}
+
+func isProfileKey(key string) bool {
+	for _, k := range profileKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// expandEnv replaces references to environment variables in all strings of the given config value, see expandString.
+// The path of the value is used in errors.
+func expandEnv(path string, v interface{}) (interface{}, error) {
+	var err error
+	switch value := v.(type) {
+	case string:
+		return expandString(path, value)
+	case map[interface{}]interface{}:
+		for k, child := range value {
+			if value[k], err = expandEnv(fmt.Sprintf("%s.%v", path, k), child); err != nil {
+				return nil, err
+			}
+		}
+	case []interface{}:
+		for i, child := range value {
+			if value[i], err = expandEnv(fmt.Sprintf("%s[%d]", path, i), child); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return v, nil
+}
+
+// expandString replaces ${VAR} with the value of the environment variable VAR, which has to be set, and ${VAR:-default}
+// with the value of VAR or default if VAR is unset or empty. A "$" not followed by "{" is kept as is.
+func expandString(path, s string) (string, error) {
+	var expanded strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(s[start:], "}")
+		if end < 0 {
+			return "", fmt.Errorf("configuration key %q has an unterminated variable reference: %q", path, s)
+		}
+		end += start
+
+		name, def, hasDefault := s[start+2:end], "", false
+		if idx := strings.Index(name, ":-"); idx >= 0 {
+			name, def, hasDefault = name[:idx], name[idx+2:], true
+		}
+		if name == "" {
+			return "", fmt.Errorf("configuration key %q references a variable without name: %q", path, s)
+		}
+
+		value, found := os.LookupEnv(name)
+		switch {
+		case hasDefault && value == "":
+			value = def
+		case !found:
+			return "", fmt.Errorf("configuration key %q requires the environment variable %s, which isn't set", path, name)
+		}
+
+		expanded.WriteString(s[:start])
+		expanded.WriteString(value)
+		s = s[end+1:]
+	}
+	expanded.WriteString(s)
+	return expanded.String(), nil
+}
+
+func configPath() (string, error) {
+	if possiblePath := os.Getenv("PHRASEAPP_CONFIG"); possiblePath != "" {
+		_, err := os.Stat(possiblePath)
+		if err == nil {
+			return possiblePath, nil
+		}
+
+		if os.IsNotExist(err) {
+			err = fmt.Errorf("file %q (from PHRASEAPP_CONFIG environment variable) doesn't exist", possiblePath)
+		}
+
+		return "", err
+	}
+
+	workingDir, err := os.Getwd()
+	if err != nil {
+		return "", nil
+	}
+
+	for _, dir := range []string{workingDir, homeDir()} {
+		for _, configName := range configNames {
+			possiblePath := filepath.Join(dir, configName)
+			if _, err := os.Stat(possiblePath); err == nil {
+				return possiblePath, nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// homeDir returns the home directory of the user, which is HOME on unix systems and USERPROFILE on Windows, falling
+// back to HOMEDRIVE and HOMEPATH. An empty string is returned if none of them is set.
+func homeDir() string {
+	if dir, err := os.UserHomeDir(); err == nil {
+		return dir
+	}
+	if drive, path := os.Getenv("HOMEDRIVE"), os.Getenv("HOMEPATH"); drive != "" && path != "" {
+		return drive + path
+	}
+	return ""
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/phrase/phraseapp-go/phraseapp"
+)
+
+type ConfigSchemaCommand struct {
+	Out string `cli:"opt --out desc='File to write the schema to, printed if not set'"`
+}
+
+func (cmd *ConfigSchemaCommand) Run() error {
+	schema, err := configSchema()
+	if err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return err
+	}
+	content = append(content, '\n')
+
+	if cmd.Out == "" {
+		_, err = os.Stdout.Write(content)
+		return err
+	}
+	return ioutil.WriteFile(cmd.Out, content, 0644)
+}
+
+type jsonSchema map[string]interface{}
+
+// phraseBlockFields maps the keys of the phrase block read by phraseapp.Config.UnmarshalYAML to the fields they are
+// parsed into. The library doesn't export them, TestConfigSchemaKeys checks that they match.
+func phraseBlockFields(cfg *phraseapp.Config) map[string]interface{} {
+	return map[string]interface{}{
+		"access_token": &cfg.Credentials.Token,
+		"host":         &cfg.Credentials.Host,
+		"debug":        &cfg.Debug,
+		"page":         &cfg.Page,
+		"per_page":     &cfg.PerPage,
+		"project_id":   &cfg.DefaultProjectID,
+		"file_format":  &cfg.DefaultFileFormat,
+		"push":         &cfg.Sources,
+		"pull":         &cfg.Targets,
+		"defaults":     &cfg.Defaults,
+	}
+}
+
+// configSchema returns a JSON Schema of the config file. It is derived from the fields the keys are parsed into and
+// from the params types of the commands, so that it stays in sync with the client.
+func configSchema() (jsonSchema, error) {
+	r, err := newRouter(&Config{Config: new(phraseapp.Config)})
+	if err != nil {
+		return nil, err
+	}
+
+	properties := jsonSchema{}
+	for key, field := range phraseBlockFields(new(phraseapp.Config)) {
+		properties[key] = schemaForType(reflect.TypeOf(field))
+	}
+
+	var params map[string]interface{}
+	properties["push"] = objectSchema(jsonSchema{
+		"sources": jsonSchema{"type": "array", "items": jsonSchema{"$ref": "#/definitions/source"}},
+	}, "sources")
+	properties["pull"] = objectSchema(jsonSchema{
+		"targets": jsonSchema{"type": "array", "items": jsonSchema{"$ref": "#/definitions/target"}},
+	}, "targets")
+
+	defaults := jsonSchema{}
+	for _, route := range r.Routes() {
+		if t := routeParamsType(route); t != nil {
+			defaults[route.Path] = paramsSchema(t)
+		}
+	}
+	properties["defaults"] = jsonSchema{"type": "object", "properties": defaults, "additionalProperties": false}
+
+	properties["aliases"] = jsonSchema{"type": "object", "additionalProperties": jsonSchema{"type": "string"}}
+	for _, setting := range cacheSettings {
+		properties[setting.key] = setting.schema
+	}
+	for _, setting := range branchNameSettings {
+		properties[setting.key] = setting.schema
+	}
+	for _, setting := range transportSettings {
+		if strings.HasSuffix(setting.key, "_timeout") {
+			properties[setting.key] = jsonSchema{"type": []string{"string", "integer"}}
+		} else {
+			properties[setting.key] = jsonSchema{"type": "string"}
+		}
+	}
+
+	profile := jsonSchema{}
+	for _, key := range profileKeys {
+		profile[key] = properties[key]
+	}
+	properties["profiles"] = jsonSchema{"type": "object", "additionalProperties": objectSchema(profile)}
+
+	source := jsonSchema{}
+	for key, field := range new(Source).yamlFields(&params) {
+		source[key] = schemaForType(reflect.TypeOf(field))
+	}
+	source["params"] = paramsSchema(reflect.TypeOf(phraseapp.UploadParams{}))
+
+	target := jsonSchema{}
+	for key, field := range new(Target).yamlFields(&params) {
+		target[key] = schemaForType(reflect.TypeOf(field))
+	}
+	targetParams := paramsSchema(reflect.TypeOf(phraseapp.LocaleDownloadParams{}))
+	targetParams["properties"].(jsonSchema)["locale_id"] = jsonSchema{"type": "string"}
+	target["params"] = targetParams
+
+	return jsonSchema{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"title":   "PhraseApp client configuration",
+		"type":    "object",
+		"properties": jsonSchema{
+			"phrase":    jsonSchema{"$ref": "#/definitions/phrase"},
+			"phraseapp": jsonSchema{"$ref": "#/definitions/phrase"},
+		},
+		"definitions": jsonSchema{
+			"phrase": objectSchema(properties),
+			"source": objectSchema(source, "file"),
+			"target": objectSchema(target, "file"),
+		},
+	}, nil
+}
+
+// objectSchema returns the schema of an object with the given properties and no others.
+func objectSchema(properties jsonSchema, required ...string) jsonSchema {
+	schema := jsonSchema{"type": "object", "properties": properties, "additionalProperties": false}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+// paramsSchema returns the schema of the keys accepted by the ApplyValuesFromMap method of a params type.
+func paramsSchema(t reflect.Type) jsonSchema {
+	properties := jsonSchema{}
+	for _, field := range paramsFields(t) {
+		properties[field.Key] = schemaForType(field.Type)
+	}
+	return objectSchema(properties)
+}
+
+func schemaForType(t reflect.Type) jsonSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return jsonSchema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return jsonSchema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return jsonSchema{"type": "number"}
+	case reflect.String:
+		return jsonSchema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return jsonSchema{"type": "array", "items": schemaForType(t.Elem())}
+	case reflect.Map:
+		return jsonSchema{"type": "object", "additionalProperties": schemaForType(t.Elem())}
+	}
+	return jsonSchema{}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/phrase/phraseapp-go/phraseapp"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestConfigSchema(t *testing.T) {
+	schema, err := configSchema()
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+
+	// Round trip through JSON, so that the schema can be walked as generic maps.
+	content, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	generic := map[string]interface{}{}
+	if err := json.Unmarshal(content, &generic); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+
+	lookup := func(path ...string) interface{} {
+		var v interface{} = generic
+		for _, key := range path {
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			v = m[key]
+		}
+		return v
+	}
+
+	tests := []struct {
+		path []string
+		exp  interface{}
+	}{
+		{[]string{"properties", "phrase", "$ref"}, "#/definitions/phrase"},
+		{[]string{"definitions", "phrase", "properties", "access_token", "type"}, "string"},
+		{[]string{"definitions", "phrase", "properties", "per_page", "type"}, "integer"},
+		{[]string{"definitions", "phrase", "properties", "cache", "type"}, "boolean"},
+		{[]string{"definitions", "phrase", "properties", "push", "properties", "sources", "items", "$ref"}, "#/definitions/source"},
+		{[]string{"definitions", "phrase", "properties", "defaults", "properties", "upload/create", "properties", "autotranslate", "type"}, "boolean"},
+		{[]string{"definitions", "phrase", "properties", "defaults", "properties", "locale/download", "properties", "file_format", "type"}, "string"},
+		{[]string{"definitions", "phrase", "properties", "profiles", "additionalProperties", "properties", "project_id", "type"}, "string"},
+		{[]string{"definitions", "source", "properties", "params", "properties", "update_translations", "type"}, "boolean"},
+		{[]string{"definitions", "target", "properties", "params", "properties", "locale_id", "type"}, "string"},
+		{[]string{"definitions", "target", "properties", "params", "properties", "format_options", "type"}, "object"},
+		{[]string{"definitions", "target", "properties", "params", "additionalProperties"}, false},
+	}
+	for _, test := range tests {
+		if got := lookup(test.path...); got != test.exp {
+			t.Errorf("%v: expected %v, got %v", test.path, test.exp, got)
+		}
+	}
+
+	var params map[string]interface{}
+	for key := range new(Source).yamlFields(&params) {
+		if lookup("definitions", "source", "properties", key) == nil {
+			t.Errorf("expected source key %q in schema", key)
+		}
+	}
+}
+
+// TestConfigSchemaKeys checks that the keys of the schema are the ones the client reads: every key is either read by
+// the library or extracted by the client before, and the config is accepted with each of them.
+func TestConfigSchemaKeys(t *testing.T) {
+	schema, err := configSchema()
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	properties := schema["definitions"].(jsonSchema)["phrase"].(jsonSchema)["properties"].(jsonSchema)
+
+	samples := map[string]interface{}{"boolean": true, "integer": 1, "string": "", "object": map[string]interface{}{}}
+	libraryKeys := phraseBlockFields(new(phraseapp.Config))
+	for key, property := range properties {
+		var sample interface{}
+		switch typ := property.(jsonSchema)["type"].(type) {
+		case string:
+			sample = samples[typ]
+		case []string:
+			sample = samples[typ[len(typ)-1]]
+		}
+
+		content, err := yaml.Marshal(map[string]interface{}{key: sample})
+		if err != nil {
+			t.Fatalf("didn't expect an error, got: %s", err)
+		}
+		libErr := yaml.Unmarshal(content, new(phraseapp.Config))
+		if _, found := libraryKeys[key]; found && libErr != nil {
+			t.Errorf("expected the library to read %q, got: %s", key, libErr)
+		} else if !found && (libErr == nil || !strings.Contains(libErr.Error(), "unknown")) {
+			t.Errorf("expected %q to be read by the library, as it doesn't reject it (%v)", key, libErr)
+		}
+
+		content, err = yaml.Marshal(map[string]interface{}{"phrase": map[string]interface{}{key: sample}})
+		if err != nil {
+			t.Fatalf("didn't expect an error, got: %s", err)
+		}
+		if err := (&Config{Config: new(phraseapp.Config)}).parse(content); err != nil {
+			t.Errorf("expected the config to accept %q of the schema, got: %s", key, err)
+		}
+	}
+
+	for key := range libraryKeys {
+		if properties[key] == nil {
+			t.Errorf("expected library key %q in schema", key)
+		}
+	}
+
+	// Every field of the library's config is read from a key, except the credentials only given as flags.
+	cfg := new(phraseapp.Config)
+	read := map[uintptr]bool{}
+	for _, field := range phraseBlockFields(cfg) {
+		read[reflect.ValueOf(field).Pointer()] = true
+	}
+	for _, v := range []reflect.Value{reflect.ValueOf(cfg).Elem(), reflect.ValueOf(&cfg.Credentials).Elem()} {
+		for i := 0; i < v.NumField(); i++ {
+			name := v.Type().Field(i).Name
+			if name == "Credentials" || name == "Username" || name == "TFA" {
+				continue
+			}
+			if !read[v.Field(i).Addr().Pointer()] {
+				t.Errorf("expected a key of phraseBlockFields for field %s of the library's config", name)
+			}
+		}
+	}
+
+	if err := (&Config{Config: new(phraseapp.Config)}).parse([]byte("phrase:\n  unknown_key: true\n")); err == nil {
+		t.Errorf("expected the config to reject a key missing in the schema")
+	}
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/phrase/phraseapp-go/phraseapp"
+)
+
+const defaultHost = "https://api.phrase.com"
+
+type ConfigShowCommand struct {
+	phraseapp.Config
+
+	cfg *Config
+}
+
+func (cmd *ConfigShowCommand) Run() error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	if err := cmd.write(w); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+func (cmd *ConfigShowCommand) write(w io.Writer) error {
+	cfg := cmd.cfg
+	if cfg.Path == "" {
+		fmt.Fprintf(w, "Config file:\tnone found\n")
+	} else {
+		fmt.Fprintf(w, "Config file:\t%s\t(%s)\n", cfg.Path, configPathOrigin(cfg.Path))
+	}
+	if cfg.Profile != "" {
+		fmt.Fprintf(w, "Profile:\t%s\t(%s)\n", cfg.Profile, cfg.Origin("profile"))
+	}
+
+	fmt.Fprintf(w, "\nCredentials\n")
+	token, origin := cmd.resolve("access_token", cfg.Credentials.Token, cmd.Credentials.Token, "--access-token", "PHRASEAPP_ACCESS_TOKEN", "")
+	fmt.Fprintf(w, "  access_token\t%s\t(%s)\n", maskToken(token), origin)
+	host, origin := cmd.resolve("host", cfg.Credentials.Host, cmd.Credentials.Host, "--host", "PHRASEAPP_HOST", defaultHost)
+	fmt.Fprintf(w, "  host\t%s\t(%s)\n", host, origin)
+	if cmd.Credentials.Username != "" {
+		fmt.Fprintf(w, "  username\t%s\t(flag --username)\n", cmd.Credentials.Username)
+	}
+
+	fmt.Fprintf(w, "\nDefaults\n")
+	for _, key := range []string{"project_id", "file_format"} {
+		value := cmd.DefaultProjectID
+		if key == "file_format" {
+			value = cmd.DefaultFileFormat
+		}
+		fmt.Fprintf(w, "  %s\t%s\t(%s)\n", key, orNotSet(value), cmd.valueOrigin(key, value))
+	}
+
+	if err := cmd.writeSources(w); err != nil {
+		return err
+	}
+	if err := cmd.writeTargets(w); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\nSettings\n")
+	for _, setting := range transportSettings {
+		if value := cfg.Transport.get(setting.key); value != "" {
+			fmt.Fprintf(w, "  %s\t%s\t(%s)\n", setting.key, value, cfg.Origin(setting.key))
+		}
+	}
+	fmt.Fprintf(w, "  cache\t%t\t(%s)\n", cfg.Cache.Enabled, cmd.valueOrigin("cache", ""))
+	if cfg.Cache.Enabled {
+		dir, err := cfg.Cache.dir()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "  cache_dir\t%s\t(%s)\n", dir, cmd.valueOrigin("cache_dir", ""))
+	}
+	if cfg.BranchNames.Sanitize {
+		origin := cfg.Origin("branch_name_sanitize")
+		if origin == "" {
+			origin = "implied by branch_name_pattern or branch_name_replacement"
+		}
+		fmt.Fprintf(w, "  branch_name_sanitize\ttrue\t(%s)\n", origin)
+	}
+	if pattern := cfg.BranchNames.Pattern; pattern != nil {
+		fmt.Fprintf(w, "  branch_name_pattern\t%s\t(%s)\n", pattern, cfg.Origin("branch_name_pattern"))
+	}
+	if replacement := cfg.BranchNames.Replacement; replacement != nil {
+		fmt.Fprintf(w, "  branch_name_replacement\t%q\t(%s)\n", *replacement, cfg.Origin("branch_name_replacement"))
+	}
+	if cfg.BranchNames.Lowercase {
+		fmt.Fprintf(w, "  branch_name_lowercase\ttrue\t(%s)\n", cfg.Origin("branch_name_lowercase"))
+	}
+
+	if len(cfg.Aliases) > 0 {
+		fmt.Fprintf(w, "\nAliases\t\t(%s)\n", cfg.Origin("aliases"))
+		names := make([]string, 0, len(cfg.Aliases))
+		for name := range cfg.Aliases {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(w, "  %s\t%s\n", name, cfg.Aliases[name])
+		}
+	}
+	return nil
+}
+
+// resolve returns the value of a credential the way the client determines it, with where it came from: a flag
+// overrides the config file, which overrides the environment, which overrides the credentials store.
+func (cmd *ConfigShowCommand) resolve(key, fromConfig, value, flag, env, def string) (string, string) {
+	switch {
+	case value != fromConfig:
+		return value, "flag " + flag
+	case value != "":
+		return value, cmd.cfg.Origin(key)
+	case os.Getenv(env) != "":
+		return os.Getenv(env), "env " + env
+	case key == "access_token":
+		if token, err := storedToken(cmd.Credentials.Host); err == nil && token != "" {
+			return token, "credentials store"
+		}
+	case def != "":
+		return def, "default"
+	}
+	return "", "not set"
+}
+
+func (cmd *ConfigShowCommand) valueOrigin(key, value string) string {
+	if origin := cmd.cfg.Origin(key); origin != "" {
+		return origin
+	}
+	if value != "" {
+		return "flag"
+	}
+	return "default"
+}
+
+func (cmd *ConfigShowCommand) writeSources(w io.Writer) error {
+	fmt.Fprintf(w, "\nSources\t\t(%s)\n", cmd.valueOrigin("push", ""))
+	if len(cmd.Sources) == 0 {
+		fmt.Fprintf(w, "  none\n")
+		return nil
+	}
+
+	sources, err := SourcesFromConfig(cmd.Config)
+	if err != nil {
+		return err
+	}
+	// Parsed again without the defaults of the phrase block, to tell which values the sources set themselves.
+	own, err := SourcesFromConfig(phraseapp.Config{Sources: cmd.Sources})
+	if err != nil {
+		return err
+	}
+
+	for i, source := range sources {
+		fmt.Fprintf(w, "  %s\n", source.File)
+		cmd.writeItemValue(w, "push", "project_id", source.ProjectID, own[i].ProjectID)
+		cmd.writeItemValue(w, "push", "file_format", source.GetFileFormat(), own[i].GetFileFormat())
+		if source.AccessToken != "" {
+			fmt.Fprintf(w, "    access_token\t%s\t(%s)\n", maskToken(source.AccessToken), cmd.cfg.Origin("push"))
+		}
+		if err := cmd.writeParams(w, source.Params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cmd *ConfigShowCommand) writeTargets(w io.Writer) error {
+	fmt.Fprintf(w, "\nTargets\t\t(%s)\n", cmd.valueOrigin("pull", ""))
+	if len(cmd.Targets) == 0 {
+		fmt.Fprintf(w, "  none\n")
+		return nil
+	}
+
+	targets, err := TargetsFromConfig(cmd.Config)
+	if err != nil {
+		return err
+	}
+	own, err := TargetsFromConfig(phraseapp.Config{Targets: cmd.Targets})
+	if err != nil {
+		return err
+	}
+
+	for i, target := range targets {
+		fmt.Fprintf(w, "  %s\n", target.File)
+		cmd.writeItemValue(w, "pull", "project_id", target.ProjectID, own[i].ProjectID)
+		cmd.writeItemValue(w, "pull", "file_format", target.GetFormat(), own[i].GetFormat())
+		if target.AccessToken != "" {
+			fmt.Fprintf(w, "    access_token\t%s\t(%s)\n", maskToken(target.AccessToken), cmd.cfg.Origin("pull"))
+		}
+		if target.Params != nil && target.Params.LocaleID != "" {
+			fmt.Fprintf(w, "    params.locale_id\t%s\n", target.Params.LocaleID)
+		}
+		if target.Params != nil {
+			if err := cmd.writeParams(w, &target.Params.LocaleDownloadParams); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeItemValue prints a value of a source or target, which is either set for it in the given push or pull block, or
+// inherited from the phrase block.
+func (cmd *ConfigShowCommand) writeItemValue(w io.Writer, block, key, value, own string) {
+	origin := "not set"
+	switch {
+	case own != "":
+		origin = cmd.cfg.Origin(block)
+	case value != "":
+		origin = cmd.valueOrigin(key, value) + ", inherited from " + key
+	}
+	fmt.Fprintf(w, "    %s\t%s\t(%s)\n", key, orNotSet(value), origin)
+}
+
+// writeParams prints the params set for a source or target, except the file format, which is printed separately.
+func (cmd *ConfigShowCommand) writeParams(w io.Writer, params interface{}) error {
+	content, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	values := map[string]interface{}{}
+	if err := json.Unmarshal(content, &values); err != nil {
+		return err
+	}
+	delete(values, "file_format")
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(w, "    params.%s\t%v\n", key, values[key])
+	}
+	return nil
+}
+
+// configPathOrigin tells how the config file at path was found, see configPath.
+func configPathOrigin(path string) string {
+	if os.Getenv("PHRASEAPP_CONFIG") != "" {
+		return "env PHRASEAPP_CONFIG"
+	}
+	if wd, err := os.Getwd(); err == nil && filepath.Dir(path) == wd {
+		return "found in working directory"
+	}
+	return "found in home directory"
+}
+
+// maskToken hides all but the first and last four characters of a token, or all of it if the token is short.
+func maskToken(token string) string {
+	switch {
+	case token == "":
+		return "not set"
+	case len(token) < 12:
+		return strings.Repeat("*", len(token))
+	}
+	return token[:4] + strings.Repeat("*", len(token)-8) + token[len(token)-4:]
+}
+
+func orNotSet(value string) string {
+	if value == "" {
+		return "not set"
+	}
+	return value
+}
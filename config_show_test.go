@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/phrase/phraseapp-go/phraseapp"
+)
+
+func TestConfigShow(t *testing.T) {
+	os.Unsetenv("PHRASEAPP_HOST")
+
+	cfg := &Config{Config: new(phraseapp.Config), Path: "/project/.phraseapp.yml", Profile: "staging"}
+	err := cfg.parse([]byte(`
+phrase:
+  access_token: abcdefghijklmnopqrstuvwxyz
+  project_id: base
+  file_format: yml
+  push:
+    sources:
+      - file: ./config/locales/<locale_code>.yml
+        params:
+          tags: web
+      - file: ./other/<locale_code>.json
+        project_id: other
+        file_format: simple_json
+  profiles:
+    staging:
+      project_id: staging
+`))
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	cfg.setOrigin("profile", "flag --profile")
+	if err := cfg.applyProfile(); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+
+	cmd := &ConfigShowCommand{Config: *cfg.Config, cfg: cfg}
+	cmd.Credentials.Host = "http://localhost:8765"
+
+	buf := &bytes.Buffer{}
+	if err := cmd.write(buf); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	output := buf.String()
+
+	for _, exp := range []string{
+		"Profile:\tstaging\t(flag --profile)",
+		"access_token\tabcd******************wxyz\t(file)",
+		"host\thttp://localhost:8765\t(flag --host)",
+		"project_id\tstaging\t(file, profile staging)",
+		"project_id\tstaging\t(file, profile staging, inherited from project_id)",
+		"file_format\tyml\t(file, inherited from file_format)",
+		"params.tags\tweb",
+		"project_id\tother\t(file)",
+		"file_format\tsimple_json\t(file)",
+		"Targets\t\t(default)\n  none",
+	} {
+		if !strings.Contains(output, exp) {
+			t.Errorf("expected output to contain %q, got:\n%s", exp, output)
+		}
+	}
+	if strings.Contains(output, "abcdefghijklmnopqrstuvwxyz") {
+		t.Errorf("expected the token to be masked")
+	}
+}
+
+func TestMaskToken(t *testing.T) {
+	for token, exp := range map[string]string{
+		"":                 "not set",
+		"short":            "*****",
+		"0123456789abcdef": "0123********cdef",
+	} {
+		if got := maskToken(token); got != exp {
+			t.Errorf("%q: expected %q, got %q", token, exp, got)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/phrase/phraseapp-go/phraseapp"
+)
+
+func TestConfigParseAliases(t *testing.T) {
+	content := []byte(`
+phrase:
+  access_token: some-token
+  project_id: project-id
+  aliases:
+    pr-pull: pull --use-local-branch-name
+    missing-de: keys search --locale-id de -q 'translated:false'
+`)
+
+	cfg := &Config{Config: new(phraseapp.Config)}
+	if err := cfg.parse(content); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+
+	if cfg.Credentials.Token != "some-token" {
+		t.Errorf("expected token %q, got %q", "some-token", cfg.Credentials.Token)
+	}
+	if cfg.DefaultProjectID != "project-id" {
+		t.Errorf("expected project id %q, got %q", "project-id", cfg.DefaultProjectID)
+	}
+
+	exp := map[string]string{
+		"pr-pull":    "pull --use-local-branch-name",
+		"missing-de": "keys search --locale-id de -q 'translated:false'",
+	}
+	if len(cfg.Aliases) != len(exp) {
+		t.Errorf("expected %d aliases, got %d", len(exp), len(cfg.Aliases))
+	}
+	for name, command := range exp {
+		if cfg.Aliases[name] != command {
+			t.Errorf("expected alias %q to be %q, got %q", name, command, cfg.Aliases[name])
+		}
+	}
+}
+
+func TestConfigParseInvalidAliases(t *testing.T) {
+	for _, content := range []string{
+		"phrase:\n  aliases: pull\n",
+		"phrase:\n  aliases:\n    a: 1\n",
+	} {
+		cfg := &Config{Config: new(phraseapp.Config)}
+		if err := cfg.parse([]byte(content)); err == nil {
+			t.Errorf("expected an error for %q, got none", content)
+		}
+	}
+}
+
+func TestConfigProfiles(t *testing.T) {
+	content := []byte(`
+phrase:
+  access_token: base-token
+  project_id: base-project
+  file_format: yml
+  push:
+    sources:
+      - file: ./config/locales/<locale_code>.yml
+  profiles:
+    staging:
+      project_id: staging-project
+    onprem:
+      access_token: onprem-token
+      host: https://phrase.example.com/api
+      push:
+        sources:
+          - file: ./locales/<locale_code>.yml
+`)
+
+	cfg := &Config{Config: new(phraseapp.Config)}
+	if err := cfg.parse(content); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if err := cfg.applyProfile(); err != nil {
+		t.Fatalf("didn't expect an error without profile, got: %s", err)
+	}
+	if cfg.DefaultProjectID != "base-project" || cfg.Credentials.Token != "base-token" {
+		t.Errorf("expected the base settings without profile, got %q and %q", cfg.DefaultProjectID, cfg.Credentials.Token)
+	}
+
+	cfg.Profile = "staging"
+	if err := cfg.applyProfile(); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if cfg.DefaultProjectID != "staging-project" {
+		t.Errorf("expected project id %q, got %q", "staging-project", cfg.DefaultProjectID)
+	}
+	if cfg.Credentials.Token != "base-token" || cfg.DefaultFileFormat != "yml" {
+		t.Errorf("expected token and file format to be inherited, got %q and %q", cfg.Credentials.Token, cfg.DefaultFileFormat)
+	}
+	if !strings.Contains(string(cfg.Sources), "config/locales") {
+		t.Errorf("expected sources to be inherited, got %s", cfg.Sources)
+	}
+
+	cfg.Profile = "onprem"
+	if err := cfg.applyProfile(); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if cfg.Credentials.Token != "onprem-token" || cfg.Credentials.Host != "https://phrase.example.com/api" {
+		t.Errorf("expected the credentials of the profile, got %q and %q", cfg.Credentials.Token, cfg.Credentials.Host)
+	}
+	if cfg.DefaultProjectID != "base-project" {
+		t.Errorf("expected the project id of a previous profile not to stick, got %q", cfg.DefaultProjectID)
+	}
+	if strings.Contains(string(cfg.Sources), "config/locales") {
+		t.Errorf("expected sources to be overridden, got %s", cfg.Sources)
+	}
+
+	cfg.Profile = "production"
+	if err := cfg.applyProfile(); err == nil || !strings.Contains(err.Error(), "onprem, staging") {
+		t.Errorf("expected an error listing the available profiles, got %v", err)
+	}
+}
+
+func TestConfigParseInvalidProfiles(t *testing.T) {
+	for _, content := range []string{
+		"phrase:\n  profiles: staging\n",
+		"phrase:\n  profiles:\n    staging: project\n",
+		"phrase:\n  profiles:\n    staging:\n      file_format: json\n",
+	} {
+		cfg := &Config{Config: new(phraseapp.Config)}
+		if err := cfg.parse([]byte(content)); err == nil {
+			t.Errorf("expected an error for %q, got none", content)
+		}
+	}
+}
+
+func TestConfigParseExpandsEnv(t *testing.T) {
+	os.Setenv("PHRASEAPP_TEST_TOKEN", "secret")
+	os.Setenv("PHRASEAPP_TEST_DIR", "locales")
+	os.Setenv("PHRASEAPP_TEST_EMPTY", "")
+	defer os.Unsetenv("PHRASEAPP_TEST_TOKEN")
+	defer os.Unsetenv("PHRASEAPP_TEST_DIR")
+	defer os.Unsetenv("PHRASEAPP_TEST_EMPTY")
+
+	content := []byte(`
+phrase:
+  access_token: ${PHRASEAPP_TEST_TOKEN}
+  project_id: ${PHRASEAPP_TEST_PROJECT:-default-project}
+  file_format: ${PHRASEAPP_TEST_EMPTY:-yml}
+  push:
+    sources:
+      - file: ./${PHRASEAPP_TEST_DIR}/<locale_code>.yml
+        params:
+          tags: ${PHRASEAPP_TEST_DIR}-$HOME
+`)
+
+	cfg := &Config{Config: new(phraseapp.Config)}
+	if err := cfg.parse(content); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+
+	if cfg.Credentials.Token != "secret" {
+		t.Errorf("expected token %q, got %q", "secret", cfg.Credentials.Token)
+	}
+	if cfg.DefaultProjectID != "default-project" {
+		t.Errorf("expected project id %q, got %q", "default-project", cfg.DefaultProjectID)
+	}
+	if cfg.DefaultFileFormat != "yml" {
+		t.Errorf("expected the default for an empty variable, got %q", cfg.DefaultFileFormat)
+	}
+	for _, exp := range []string{"./locales/<locale_code>.yml", "locales-$HOME"} {
+		if !strings.Contains(string(cfg.Sources), exp) {
+			t.Errorf("expected sources to contain %q, got %s", exp, cfg.Sources)
+		}
+	}
+}
+
+func TestConfigParseExpandsSelectedProfileOnly(t *testing.T) {
+	os.Setenv("PHRASEAPP_TEST_TOKEN", "secret")
+	defer os.Unsetenv("PHRASEAPP_TEST_TOKEN")
+
+	content := []byte(`
+phrase:
+  access_token: base-token
+  aliases:
+    deploy: push --branch ${PHRASEAPP_TEST_MISSING}
+  profiles:
+    staging:
+      access_token: ${PHRASEAPP_TEST_TOKEN}
+    production:
+      access_token: ${PHRASEAPP_TEST_MISSING}
+`)
+
+	cfg := &Config{Config: new(phraseapp.Config)}
+	if err := cfg.parse(content); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if cfg.Aliases["deploy"] != "push --branch ${PHRASEAPP_TEST_MISSING}" {
+		t.Errorf("expected the alias to be kept as written, got %q", cfg.Aliases["deploy"])
+	}
+
+	cfg.Profile = "staging"
+	if err := cfg.applyProfile(); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if cfg.Credentials.Token != "secret" {
+		t.Errorf("expected token %q, got %q", "secret", cfg.Credentials.Token)
+	}
+
+	cfg.Profile = "production"
+	if err := cfg.applyProfile(); err == nil || !strings.Contains(err.Error(), "profiles.production.access_token") {
+		t.Errorf("expected an error naming the setting of the profile, got %v", err)
+	}
+}
+
+func TestConfigParseMissingEnv(t *testing.T) {
+	for content, path := range map[string]string{
+		"phrase:\n  access_token: ${PHRASEAPP_TEST_MISSING}\n":                                        "phrase.access_token",
+		"phrase:\n  pull:\n    targets:\n      - file: ${PHRASEAPP_TEST_MISSING}/<locale_code>.yml\n": "phrase.pull.targets[0].file",
+		"phrase:\n  project_id: ${PHRASEAPP_TEST_MISSING\n":                                           "phrase.project_id",
+	} {
+		cfg := &Config{Config: new(phraseapp.Config)}
+		err := cfg.parse([]byte(content))
+		if err == nil || !strings.Contains(err.Error(), path) {
+			t.Errorf("expected an error naming %q for %q, got %v", path, content, err)
+		}
+	}
+}
+
+func TestHomeDir(t *testing.T) {
+	for _, name := range []string{"HOME", "USERPROFILE", "HOMEDRIVE", "HOMEPATH"} {
+		defer os.Setenv(name, os.Getenv(name))
+		os.Unsetenv(name)
+	}
+
+	if dir := homeDir(); dir != "" {
+		t.Errorf("expected no home directory, got %q", dir)
+	}
+
+	os.Setenv("HOMEDRIVE", "C:")
+	os.Setenv("HOMEPATH", `\Users\someone`)
+	if dir := homeDir(); dir != `C:\Users\someone` {
+		t.Errorf("expected the home directory from HOMEDRIVE and HOMEPATH, got %q", dir)
+	}
+
+	os.Setenv("HOME", "/home/someone")
+	os.Setenv("USERPROFILE", `C:\Users\someone`)
+	if dir := homeDir(); dir != "/home/someone" && dir != `C:\Users\someone` {
+		t.Errorf("expected the home directory of the platform, got %q", dir)
+	}
+}
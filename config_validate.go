@@ -0,0 +1,449 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/phrase/phraseapp-client/internal/placeholders"
+	"github.com/phrase/phraseapp-client/internal/print"
+	"github.com/phrase/phraseapp-client/internal/yamlpos"
+	"github.com/phrase/phraseapp-go/phraseapp"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// knownFormats are the API names of the file formats supported by PhraseApp, used to check the config without
+// connecting to the API.
+var knownFormats = []string{
+	"angular_translate", "arb", "chrome_json", "csv", "ember_js", "episerver", "gettext", "gettext_mo",
+	"gettext_template", "go_i18n", "i18next", "ini", "json", "laravel", "mozilla_properties", "nested_json",
+	"node_json", "php_array", "plist", "properties", "properties_xml", "qph", "react_nested_json",
+	"react_simple_json", "resx", "resx_windowsphone", "simple_json", "strings", "stringsdict", "tmx", "ts",
+	"windows8_resource", "xlf", "xliff_2", "xlsx", "xml", "yml", "yml_symfony", "yml_symfony2", "zendesk_csv",
+}
+
+type ConfigValidateCommand struct {
+	Online bool `cli:"opt --online desc='Check file formats against the formats supported by the API instead of the built-in list'"`
+
+	cfg *Config
+}
+
+func (cmd *ConfigValidateCommand) Run() error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		return fmt.Errorf("no config file found, run 'phraseapp init' to create one")
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	v := newConfigValidator(content, filepath.Dir(path))
+	v.validate()
+
+	formats := knownFormats
+	if cmd.Online && len(v.formats) > 0 {
+		if formats, err = cmd.remoteFormats(); err != nil {
+			return err
+		}
+	}
+	v.validateFormats(formats, !cmd.Online)
+
+	if len(v.problems) == 0 {
+		print.Success("%s is valid", path)
+		return nil
+	}
+
+	for _, p := range v.sortedProblems() {
+		fmt.Printf("%s:%s %s\n", path, p.position(), p.message)
+	}
+	if len(v.problems) == 1 {
+		return fmt.Errorf("found 1 problem in %s", path)
+	}
+	return fmt.Errorf("found %d problems in %s", len(v.problems), path)
+}
+
+func (cmd *ConfigValidateCommand) remoteFormats() ([]string, error) {
+	client, err := newClient(cmd.cfg.Credentials, cmd.cfg.Debug)
+	if err != nil {
+		return nil, err
+	}
+
+	formats, err := client.FormatsList(1, 100)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(formats))
+	for _, f := range formats {
+		names = append(names, f.ApiName)
+	}
+	return names, nil
+}
+
+// configProblem is an error found in the config file, at the position of the key it concerns.
+type configProblem struct {
+	path    string
+	message string
+	pos     yamlpos.Position
+}
+
+func (p *configProblem) position() string {
+	switch {
+	case p.pos.Line == 0:
+		return ""
+	case p.pos.Column == 0:
+		return fmt.Sprintf("%d:", p.pos.Line)
+	default:
+		return fmt.Sprintf("%d:%d:", p.pos.Line, p.pos.Column)
+	}
+}
+
+// formatUse is a file format named in the config.
+type formatUse struct {
+	path string
+	name string
+}
+
+// configValidator collects all problems of a config file, instead of stopping at the first one like ReadConfig.
+type configValidator struct {
+	content   []byte
+	baseDir   string
+	positions yamlpos.Positions
+
+	problems []*configProblem
+	formats  []formatUse
+}
+
+func newConfigValidator(content []byte, baseDir string) *configValidator {
+	return &configValidator{content: content, baseDir: baseDir, positions: yamlpos.Index(content)}
+}
+
+var (
+	yamlLineRegexp   = regexp.MustCompile(`line (\d+): ([^\n]+)`)
+	configKeyRegexp  = regexp.MustCompile(`configuration key "([^"]+)"`)
+	configHelpSuffix = "\nsee https://help.phrase.com/articles/2185247-configuration"
+)
+
+// add records err as problem of the key at path. Errors of the phraseapp library only name the key relative to the
+// map it is in, so the keys below path are searched for the one named in the error.
+func (v *configValidator) add(path string, err error) {
+	message := strings.TrimSuffix(err.Error(), configHelpSuffix)
+
+	if m := configKeyRegexp.FindStringSubmatch(message); m != nil {
+		for _, candidate := range []string{m[1], path + "." + m[1], path + ".params." + m[1]} {
+			if _, found := v.positions[candidate]; found {
+				path = candidate
+				break
+			}
+		}
+	}
+
+	for _, p := range v.problems {
+		if p.path == path && p.message == message {
+			return
+		}
+	}
+
+	pos, _ := v.positions.Locate(path)
+	v.problems = append(v.problems, &configProblem{path: path, message: message, pos: pos})
+}
+
+func (v *configValidator) addf(path, format string, args ...interface{}) {
+	v.add(path, fmt.Errorf(format, args...))
+}
+
+func (v *configValidator) sortedProblems() []*configProblem {
+	problems := append([]*configProblem{}, v.problems...)
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].pos.Line != problems[j].pos.Line {
+			return problems[i].pos.Line < problems[j].pos.Line
+		}
+		return problems[i].pos.Column < problems[j].pos.Column
+	})
+	return problems
+}
+
+// configScope is the phrase block or a profile, with the settings the sources and targets inherit.
+type configScope struct {
+	pushPath, pullPath    string
+	push, pull            interface{}
+	projectID, fileFormat string
+}
+
+func (v *configValidator) validate() {
+	raw := map[string]interface{}{}
+	if err := yaml.Unmarshal(v.content, &raw); err != nil {
+		v.addYAMLError(err)
+		return
+	}
+
+	key := ""
+	for _, k := range []string{"phrase", "phraseapp"} {
+		if _, found := raw[k]; found {
+			key = k
+			break
+		}
+	}
+	if key == "" {
+		v.addf("", "'phrase' key is missing in config")
+		return
+	}
+
+	block, err := phraseapp.ValidateIsRawMap(key, raw[key])
+	if err != nil {
+		v.add(key, err)
+		return
+	}
+
+	names := make([]string, 0, len(block))
+	for k := range block {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	valid := map[string]interface{}{}
+	for _, k := range names {
+		path := key + "." + k
+		value := block[k]
+		// alias bodies are command lines, kept as written
+		if k != "aliases" {
+			var err error
+			if value, err = expandEnv(path, value); err != nil {
+				v.add(path, err)
+				continue
+			}
+		}
+		if v.validateKey(path, k, value) {
+			valid[k] = value
+		}
+	}
+
+	base := configScope{pushPath: key + ".push", pullPath: key + ".pull", push: valid["push"], pull: valid["pull"]}
+	base.projectID, _ = valid["project_id"].(string)
+	base.fileFormat, _ = valid["file_format"].(string)
+	if base.fileFormat != "" {
+		v.formats = append(v.formats, formatUse{path: key + ".file_format", name: base.fileFormat})
+	}
+	v.validateScope(base)
+
+	profiles, _ := valid["profiles"].(map[interface{}]interface{})
+	for name, rawProfile := range profiles {
+		profile, _ := rawProfile.(map[interface{}]interface{})
+		path := fmt.Sprintf("%s.profiles.%v", key, name)
+
+		scope, overrides := base, false
+		if push, found := profile["push"]; found {
+			scope.push, scope.pushPath, overrides = push, path+".push", true
+		}
+		if pull, found := profile["pull"]; found {
+			scope.pull, scope.pullPath, overrides = pull, path+".pull", true
+		}
+		if projectID, ok := profile["project_id"].(string); ok {
+			scope.projectID, overrides = projectID, true
+		}
+		if overrides {
+			v.validateScope(scope)
+		}
+	}
+}
+
+// validateKey checks a single key of the phrase block the same way ReadConfig does and reports whether it is valid.
+func (v *configValidator) validateKey(path, key string, value interface{}) bool {
+	single := map[string]interface{}{key: value}
+
+	cfg := &Config{Config: new(phraseapp.Config), Path: filepath.Join(v.baseDir, configNames[0])}
+	if err := cfg.extractClientSettings(single); err != nil {
+		v.add(path, err)
+		return false
+	}
+	if len(single) == 0 {
+		return true
+	}
+
+	content, err := yaml.Marshal(single)
+	if err == nil {
+		err = yaml.Unmarshal(content, cfg.Config)
+	}
+	if err != nil {
+		v.add(path, err)
+		return false
+	}
+	return true
+}
+
+// addYAMLError reports the syntax or type errors of the YAML parser at the lines they name.
+func (v *configValidator) addYAMLError(err error) {
+	matches := yamlLineRegexp.FindAllStringSubmatch(err.Error(), -1)
+	if len(matches) == 0 {
+		v.add("", err)
+		return
+	}
+
+	for _, m := range matches {
+		line, _ := strconv.Atoi(m[1])
+		v.problems = append(v.problems, &configProblem{message: m[2], pos: yamlpos.Position{Line: line}})
+	}
+}
+
+func (v *configValidator) validateScope(scope configScope) {
+	var sources Sources
+	var sourcePaths []string
+	if scope.push != nil {
+		v.eachItem(scope.pushPath, scope.push, "sources", func(path string, item map[interface{}]interface{}) {
+			content, err := yaml.Marshal(map[string]interface{}{"sources": []interface{}{item}})
+			if err != nil {
+				v.add(path, err)
+				return
+			}
+
+			srcs, err := SourcesFromConfig(phraseapp.Config{Sources: content, DefaultProjectID: scope.projectID, DefaultFileFormat: scope.fileFormat})
+			if err != nil {
+				v.add(path, err)
+				return
+			}
+
+			source := srcs[0]
+			if err := source.CheckPreconditions(); err != nil {
+				v.add(path+".file", err)
+			}
+			if source.ProjectID == "" {
+				v.addf(path, "no project_id set for the source or in the phrase block")
+			}
+			v.addFormat(path, item, source.GetFileFormat())
+
+			sources = append(sources, source)
+			sourcePaths = append(sourcePaths, path)
+		})
+	}
+
+	if scope.pull != nil {
+		v.eachItem(scope.pullPath, scope.pull, "targets", func(path string, item map[interface{}]interface{}) {
+			content, err := yaml.Marshal(map[string]interface{}{"targets": []interface{}{item}})
+			if err != nil {
+				v.add(path, err)
+				return
+			}
+
+			tgts, err := TargetsFromConfig(phraseapp.Config{Targets: content, DefaultProjectID: scope.projectID, DefaultFileFormat: scope.fileFormat})
+			if err != nil {
+				v.add(path, err)
+				return
+			}
+
+			target := tgts[0]
+			if err := target.CheckPreconditions(); err != nil {
+				v.add(path+".file", err)
+			}
+			if target.ProjectID == "" {
+				v.addf(path, "no project_id set for the target or in the phrase block")
+			}
+			v.addFormat(path, item, target.GetFormat())
+
+			for i, source := range sources {
+				v.comparePlaceholders(path, target, sourcePaths[i], source)
+			}
+		})
+	}
+}
+
+// eachItem calls f for every entry of the list under key in the given push or pull block.
+func (v *configValidator) eachItem(path string, block interface{}, key string, f func(string, map[interface{}]interface{})) {
+	m, err := phraseapp.ValidateIsRawMap(path, block)
+	if err != nil {
+		v.add(path, err)
+		return
+	}
+
+	rawList, found := m[key]
+	if !found {
+		v.addf(path, "no %s specified", key)
+		return
+	}
+	list, ok := rawList.([]interface{})
+	if !ok {
+		v.addf(path+"."+key, "expected a list of %s", key)
+		return
+	}
+
+	for i, rawItem := range list {
+		path := fmt.Sprintf("%s.%s[%d]", path, key, i)
+		item, ok := rawItem.(map[interface{}]interface{})
+		if !ok {
+			v.addf(path, "expected a map with at least the key 'file', got %v", rawItem)
+			continue
+		}
+		f(path, item)
+	}
+}
+
+// addFormat records the file format of a source or target at the key it is set with.
+func (v *configValidator) addFormat(path string, item map[interface{}]interface{}, name string) {
+	if name == "" {
+		return
+	}
+	if params, ok := item["params"].(map[interface{}]interface{}); ok && params["file_format"] != nil {
+		path += ".params.file_format"
+	} else if item["file_format"] != nil {
+		path += ".file_format"
+	} else {
+		return // inherited from the phrase block, recorded there
+	}
+
+	for _, f := range v.formats {
+		if f.path == path {
+			return
+		}
+	}
+	v.formats = append(v.formats, formatUse{path: path, name: name})
+}
+
+func (v *configValidator) validateFormats(formats []string, offline bool) {
+	supported := map[string]bool{}
+	for _, name := range formats {
+		supported[name] = true
+	}
+
+	for _, f := range v.formats {
+		if supported[f.name] {
+			continue
+		}
+		if offline {
+			v.addf(f.path, "unknown file format %q, use --online to check against the formats supported by the API", f.name)
+		} else {
+			v.addf(f.path, "file format %q isn't supported by the API", f.name)
+		}
+	}
+}
+
+// comparePlaceholders reports a target writing the same files a source reads with different placeholders, as the
+// pulled files would then be pushed to other locales or tags than they were downloaded from.
+func (v *configValidator) comparePlaceholders(targetPath string, target *Target, sourcePath string, source *Source) {
+	if target.ProjectID != source.ProjectID {
+		return
+	}
+
+	targetGlob := filepath.Clean(placeholders.ToGlobbingPattern(target.File))
+	sourceGlob := filepath.Clean(placeholders.ToGlobbingPattern(source.File))
+	if targetGlob != sourceGlob {
+		return
+	}
+
+	targetPlaceholders := placeholders.FindAll(target.File)
+	sourcePlaceholders := placeholders.FindAll(source.File)
+	if strings.Join(targetPlaceholders, "") == strings.Join(sourcePlaceholders, "") {
+		return
+	}
+
+	sourcePos, _ := v.positions.Locate(sourcePath + ".file")
+	v.addf(targetPath+".file", "placeholders %s don't match %s of the source for the same files at line %d",
+		strings.Join(targetPlaceholders, ""), strings.Join(sourcePlaceholders, ""), sourcePos.Line)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfigValidator(t *testing.T) {
+	content := []byte(`phrase:
+  access_token: abc
+  project_id: proj
+  file_format: yml
+  colour: red
+  push:
+    sources:
+      - file: ./config/locales/<locale_code>.yml
+      - file: ./config/<locale_code>/<locale_code>.yml
+        params:
+          file_format: ymlx
+  pull:
+    targets:
+      - file: ./config/locales/<locale_name>.yml
+      - file: ./out/*.yml
+`)
+
+	v := newConfigValidator(content, ".")
+	v.validate()
+	v.validateFormats(knownFormats, true)
+
+	exp := []struct {
+		line, column int
+		message      string
+	}{
+		{5, 3, `configuration key "colour" unknown`},
+		{9, 9, "<locale_code> can only occur once"},
+		{11, 11, `unknown file format "ymlx"`},
+		{14, 9, "placeholders <locale_name> don't match <locale_code> of the source for the same files at line 8"},
+		{15, 9, "cannot include any 'stars'"},
+	}
+
+	problems := v.sortedProblems()
+	if len(problems) != len(exp) {
+		for _, p := range problems {
+			t.Logf("%s %s", p.position(), p.message)
+		}
+		t.Fatalf("expected %d problems, got %d", len(exp), len(problems))
+	}
+	for i, e := range exp {
+		p := problems[i]
+		if p.pos.Line != e.line || p.pos.Column != e.column || !strings.Contains(p.message, e.message) {
+			t.Errorf("expected %q at %d:%d, got %q at %s", e.message, e.line, e.column, p.message, p.position())
+		}
+	}
+}
+
+func TestConfigValidatorValidConfig(t *testing.T) {
+	content := []byte(`phrase:
+  access_token: abc
+  project_id: proj
+  file_format: yml
+  aliases:
+    pr-pull: pull --use-local-branch-name
+  push:
+    sources:
+      - file: ./config/locales/<locale_code>.yml
+  pull:
+    targets:
+      - file: ./config/locales/<locale_code>.yml
+  profiles:
+    staging:
+      project_id: staging-proj
+`)
+
+	v := newConfigValidator(content, ".")
+	v.validate()
+	v.validateFormats(knownFormats, true)
+
+	for _, p := range v.problems {
+		t.Errorf("didn't expect a problem, got %q at %s", p.message, p.position())
+	}
+}
+
+func TestConfigValidatorSyntaxError(t *testing.T) {
+	v := newConfigValidator([]byte("phrase:\n  project_id: proj\n  push: [\n"), ".")
+	v.validate()
+
+	if len(v.problems) != 1 || v.problems[0].pos.Line != 3 {
+		t.Fatalf("expected a syntax error in line 3, got %v", v.problems)
+	}
+}
+
+func TestValidatesConfig(t *testing.T) {
+	for args, exp := range map[string]bool{
+		"config validate":                      true,
+		"--profile x config validate --online": true,
+		"config validate --online":             true,
+		"config":                               false,
+		"pull":                                 false,
+	} {
+		if got := validatesConfig(strings.Fields(args)); got != exp {
+			t.Errorf("%q: expected %t, got %t", args, exp, got)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/phrase/phraseapp-client/internal/localefile"
+	"github.com/phrase/phraseapp-client/internal/paths"
+	"github.com/phrase/phraseapp-client/internal/print"
+)
+
+type ConvertCommand struct {
+	From              string            `cli:"opt --from required desc='API name of the format of the input files, e.g. yml'"`
+	To                string            `cli:"opt --to required desc='API name of the format to convert to, e.g. nested_json'"`
+	FromFormatOptions map[string]string `cli:"opt --from-format-options desc='Format options for reading, like the format_options of uploads'"`
+	ToFormatOptions   map[string]string `cli:"opt --to-format-options desc='Format options for writing, like the format_options of downloads'"`
+	Locale            string            `cli:"opt --locale desc='Locale code for formats including it, defaults to the one of the input file or its name if that is a locale code'"`
+
+	In  string `cli:"arg required"`
+	Out string `cli:"arg required"`
+}
+
+func (cmd *ConvertCommand) Run() error {
+	from, err := localefile.LookupWithOptions(cmd.From, cmd.FromFormatOptions)
+	if err != nil {
+		return err
+	}
+	to, err := localefile.LookupWithOptions(cmd.To, cmd.ToFormatOptions)
+	if err != nil {
+		return err
+	}
+
+	conversions, err := cmd.conversions()
+	if err != nil {
+		return err
+	}
+	for _, c := range conversions {
+		if err := cmd.convert(from, to, c.in, c.out); err != nil {
+			return fmt.Errorf("%s: %s", c.in, err)
+		}
+		print.Success("Converted %s to %s", c.in, c.out)
+	}
+	return nil
+}
+
+type conversion struct {
+	in, out string
+}
+
+// conversions returns the files to convert. If the input is a glob pattern, all matching files are converted to the
+// output directory, named like the input files with the extension of the output format.
+func (cmd *ConvertCommand) conversions() ([]conversion, error) {
+	if !strings.Contains(cmd.In, "*") {
+		return []conversion{{in: cmd.In, out: cmd.Out}}, nil
+	}
+
+	matches, err := paths.Glob(cmd.In)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no files match %s", cmd.In)
+	}
+
+	extension := localefile.Extension(cmd.To)
+	conversions := []conversion{}
+	inputs := map[string]string{}
+	for _, in := range matches {
+		name := strings.TrimSuffix(filepath.Base(in), filepath.Ext(in)) + "." + extension
+		out := filepath.Join(cmd.Out, name)
+		if other, found := inputs[out]; found {
+			return nil, fmt.Errorf("both %s and %s would be converted to %s", other, in, out)
+		}
+		inputs[out] = in
+		conversions = append(conversions, conversion{in: in, out: out})
+	}
+	return conversions, nil
+}
+
+// localeCodeRegexp matches file names that are locale codes, like de or pt-BR.
+var localeCodeRegexp = regexp.MustCompile(`^[a-zA-Z]{2,3}([-_][a-zA-Z0-9]{2,8})*$`)
+
+func (cmd *ConvertCommand) convert(from, to localefile.Format, in, out string) error {
+	content, err := ioutil.ReadFile(in)
+	if err != nil {
+		return err
+	}
+
+	f, err := from.Parse(content)
+	if err != nil {
+		return err
+	}
+	name := strings.TrimSuffix(filepath.Base(in), filepath.Ext(in))
+	switch {
+	case cmd.Locale != "":
+		f.Locale = cmd.Locale
+	case f.Locale == "" && localeCodeRegexp.MatchString(name):
+		f.Locale = name
+	}
+	if err := localefile.ConvertPlurals(f, cmd.To); err != nil {
+		return withLocaleHint(f, err)
+	}
+
+	converted, err := to.Write(f)
+	if err != nil {
+		return withLocaleHint(f, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(out, converted, 0644)
+}
+
+// withLocaleHint points to the --locale option in errors converting files without a locale.
+func withLocaleHint(f *localefile.File, err error) error {
+	if f.Locale != "" {
+		return err
+	}
+	return fmt.Errorf("%s, the locale can be set with --locale", err)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConvertCommand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "phraseapp-convert")
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("didn't expect an error, got: %s", err)
+		}
+		return path
+	}
+	read := func(name string) string {
+		content, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("didn't expect an error, got: %s", err)
+		}
+		return string(content)
+	}
+
+	in := write("in/en.yml", "en:\n  a:\n    b: B\n")
+	write("in/de.yml", "de:\n  a:\n    b: Bé\n")
+
+	cmd := &ConvertCommand{From: "yml", To: "nested_json", In: in, Out: filepath.Join(dir, "out.json")}
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if got, exp := read("out.json"), "{\n  \"a\": {\n    \"b\": \"B\"\n  }\n}\n"; got != exp {
+		t.Errorf("expected %q, got %q", exp, got)
+	}
+
+	cmd = &ConvertCommand{From: "yml", To: "properties", In: filepath.Join(dir, "in", "*.yml"), Out: filepath.Join(dir, "out")}
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if got, exp := read("out/de.properties"), "a.b=B\\u00e9\n"; got != exp {
+		t.Errorf("expected %q, got %q", exp, got)
+	}
+
+	// the locale is taken from the file name if the input doesn't include it
+	cmd = &ConvertCommand{From: "properties", To: "yml", In: filepath.Join(dir, "out", "de.properties"), Out: filepath.Join(dir, "de.yml")}
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if got, exp := read("de.yml"), "de:\n  a:\n    b: \"Bé\"\n"; got != exp {
+		t.Errorf("expected %q, got %q", exp, got)
+	}
+
+	write("other/en.json", "{}")
+	cmd = &ConvertCommand{From: "simple_json", To: "yml", In: filepath.Join(dir, "**", "en.*"), Out: filepath.Join(dir, "out")}
+	if err := cmd.Run(); err == nil || !strings.Contains(err.Error(), "would be converted to") {
+		t.Errorf("expected an error about files converted to the same path, got: %v", err)
+	}
+
+	cmd = &ConvertCommand{From: "yml", To: "nested_json", ToFormatOptions: map[string]string{"root_key": "x"}, In: in, Out: "unused"}
+	if err := cmd.Run(); err == nil || !strings.Contains(err.Error(), `format option "root_key" is not supported`) {
+		t.Errorf("expected an error about the format option, got: %v", err)
+	}
+
+	po := write("in/s.po", `msgid ""
+msgstr ""
+"Language: de\n"
+
+msgid "Hello"
+msgstr "Hallo"
+
+msgid "apple"
+msgid_plural "apples"
+msgstr[0] "Apfel"
+msgstr[1] "Äpfel"
+`)
+	cmd = &ConvertCommand{From: "gettext", To: "xlf", In: po, Out: filepath.Join(dir, "s.xlf")}
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	xlf := read("s.xlf")
+	for _, exp := range []string{
+		`<file datatype="plaintext" original="phraseapp" source-language="de" target-language="de">`,
+		"<trans-unit id=\"Hello\" resname=\"Hello\">\n        <source>Hello</source>\n        <target>Hallo</target>",
+		`<group id="apple" resname="apple" restype="x-gettext-plurals">`,
+		"<trans-unit id=\"apple[1]\">\n          <source>apples</source>\n          <target>Äpfel</target>",
+	} {
+		if !strings.Contains(xlf, exp) {
+			t.Errorf("expected %q in:\n%s", exp, xlf)
+		}
+	}
+
+	cmd = &ConvertCommand{From: "gettext", To: "yml", In: po, Out: filepath.Join(dir, "s.yml")}
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if got, exp := read("s.yml"), "de:\n  Hello: \"Hallo\"\n  apple:\n    one: \"Apfel\"\n    other: \"Äpfel\"\n"; got != exp {
+		t.Errorf("expected %q, got %q", exp, got)
+	}
+
+	cmd = &ConvertCommand{From: "yml", To: "gettext", In: filepath.Join(dir, "s.yml"), Out: filepath.Join(dir, "s2.po")}
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if got := read("s2.po"); !strings.Contains(got, "msgstr[0] \"Apfel\"\nmsgstr[1] \"Äpfel\"\n") {
+		t.Errorf("expected the plural forms as msgstr indices, got:\n%s", got)
+	}
+
+	// the name of s.po isn't a locale code
+	plain := write("in/s.po", "msgid \"Hello\"\nmsgstr \"Hallo\"\n")
+	cmd = &ConvertCommand{From: "gettext", To: "xlf", In: plain, Out: filepath.Join(dir, "s.xlf")}
+	if err := cmd.Run(); err == nil || !strings.Contains(err.Error(), "the locale can be set with --locale") {
+		t.Errorf("expected an error about the missing locale, got: %v", err)
+	}
+	cmd.Locale = "de"
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if got := read("s.xlf"); !strings.Contains(got, `target-language="de"`) || !strings.Contains(got, "<source>Hello</source>") {
+		t.Errorf("expected the locale and source of the PO file, got:\n%s", got)
+	}
+
+	ru := write("in/ru.po", "msgid \"apple\"\nmsgid_plural \"apples\"\nmsgstr[0] \"яблоко\"\nmsgstr[1] \"яблока\"\nmsgstr[2] \"яблок\"\n")
+	cmd = &ConvertCommand{From: "gettext", To: "yml", In: ru, Out: filepath.Join(dir, "ru.yml")}
+	if err := cmd.Run(); err == nil || !strings.Contains(err.Error(), "can't be mapped between gettext and CLDR") {
+		t.Errorf("expected an error about the plural forms, got: %v", err)
+	}
+}
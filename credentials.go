@@ -0,0 +1,355 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/phrase/phraseapp-client/internal/lineedit"
+)
+
+const (
+	credentialsFileName          = "credentials.json"
+	encryptedCredentialsFileName = "credentials.enc.json"
+	credentialsPassphraseEnv     = "PHRASEAPP_CREDENTIALS_PASSPHRASE"
+)
+
+// credentialsDir is the directory of the credentials store. It is the phraseapp directory in the user config dir if
+// empty, and only set in tests.
+var credentialsDir string
+
+// storedCredential is the access token stored for a host.
+type storedCredential struct {
+	Token     string    `json:"token"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// credentialStore stores access tokens per host, so that they don't have to be kept in config files. The tokens are
+// either kept in a file only readable by the user, or in a file encrypted with a passphrase.
+type credentialStore struct {
+	Path      string
+	Encrypted bool
+}
+
+// openCredentialStore returns the store in use. The encrypted store is used if it exists, the plain one otherwise.
+func openCredentialStore() (*credentialStore, error) {
+	dir := credentialsDir
+	if dir == "" {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		dir = filepath.Join(configDir, "phraseapp")
+	}
+
+	encrypted := filepath.Join(dir, encryptedCredentialsFileName)
+	if _, err := os.Stat(encrypted); err == nil {
+		return &credentialStore{Path: encrypted, Encrypted: true}, nil
+	}
+	return &credentialStore{Path: filepath.Join(dir, credentialsFileName)}, nil
+}
+
+func (s *credentialStore) backend() string {
+	if s.Encrypted {
+		return "encrypted file"
+	}
+	return "file"
+}
+
+// encryptedCredentials is the content of the encrypted store. The hosts are kept in plain text, so that the
+// passphrase is only asked for if the store has a token for the host in question.
+type encryptedCredentials struct {
+	Hosts []string `json:"hosts"`
+	Salt  []byte   `json:"salt"`
+	Nonce []byte   `json:"nonce"`
+	Data  []byte   `json:"data"`
+}
+
+// Hosts returns the hosts with a stored token, without decrypting the store.
+func (s *credentialStore) Hosts() ([]string, error) {
+	content, err := ioutil.ReadFile(s.Path)
+	switch {
+	case os.IsNotExist(err):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+
+	if s.Encrypted {
+		ec := &encryptedCredentials{}
+		if err := json.Unmarshal(content, ec); err != nil {
+			return nil, fmt.Errorf("%s: %s", s.Path, err)
+		}
+		return ec.Hosts, nil
+	}
+
+	creds := map[string]*storedCredential{}
+	if err := json.Unmarshal(content, &creds); err != nil {
+		return nil, fmt.Errorf("%s: %s", s.Path, err)
+	}
+	return sortedHosts(creds), nil
+}
+
+func (s *credentialStore) hasHost(host string) (bool, error) {
+	hosts, err := s.Hosts()
+	if err != nil {
+		return false, err
+	}
+	for _, h := range hosts {
+		if h == normalizeHost(host) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Token returns the token stored for host, or an empty string if there is none.
+func (s *credentialStore) Token(host string) (string, error) {
+	if found, err := s.hasHost(host); !found {
+		return "", err
+	}
+
+	creds, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	if c := creds[normalizeHost(host)]; c != nil {
+		return c.Token, nil
+	}
+	return "", nil
+}
+
+// Set stores the token for host, replacing the one stored before.
+func (s *credentialStore) Set(host, token string) error {
+	creds, err := s.load()
+	if err != nil {
+		return err
+	}
+	creds[normalizeHost(host)] = &storedCredential{Token: token, CreatedAt: time.Now().UTC().Truncate(time.Second)}
+	return s.save(creds)
+}
+
+// Delete removes the token of host and reports whether there was one.
+func (s *credentialStore) Delete(host string) (bool, error) {
+	if found, err := s.hasHost(host); !found {
+		return false, err
+	}
+
+	creds, err := s.load()
+	if err != nil {
+		return false, err
+	}
+	delete(creds, normalizeHost(host))
+	return true, s.save(creds)
+}
+
+func (s *credentialStore) load() (map[string]*storedCredential, error) {
+	creds := map[string]*storedCredential{}
+
+	content, err := ioutil.ReadFile(s.Path)
+	switch {
+	case os.IsNotExist(err):
+		return creds, nil
+	case err != nil:
+		return nil, err
+	}
+
+	if s.Encrypted {
+		if content, err = decryptCredentials(content); err != nil {
+			return nil, fmt.Errorf("%s: %s", s.Path, err)
+		}
+	}
+	if err := json.Unmarshal(content, &creds); err != nil {
+		return nil, fmt.Errorf("%s: %s", s.Path, err)
+	}
+	return creds, nil
+}
+
+func (s *credentialStore) save(creds map[string]*storedCredential) error {
+	content, err := json.MarshalIndent(creds, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if s.Encrypted {
+		if content, err = encryptCredentials(content, sortedHosts(creds)); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0700); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(s.Path, append(content, '\n'), 0600); err != nil {
+		return err
+	}
+	// WriteFile keeps the permissions of an existing file.
+	return os.Chmod(s.Path, 0600)
+}
+
+// encrypt moves the tokens of the plain store into an encrypted one and removes the plain file.
+func (s *credentialStore) encrypt() (*credentialStore, error) {
+	creds, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted := &credentialStore{Path: filepath.Join(filepath.Dir(s.Path), encryptedCredentialsFileName), Encrypted: true}
+	if err := encrypted.save(creds); err != nil {
+		return nil, err
+	}
+	if err := os.Remove(s.Path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return encrypted, nil
+}
+
+func sortedHosts(creds map[string]*storedCredential) []string {
+	hosts := make([]string, 0, len(creds))
+	for host := range creds {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// normalizeHost returns the host the way tokens are stored for it, the default host if empty.
+func normalizeHost(host string) string {
+	if host == "" {
+		host = os.Getenv("PHRASEAPP_HOST")
+	}
+	if host == "" {
+		host = defaultHost
+	}
+	return strings.TrimRight(host, "/")
+}
+
+const (
+	pbkdf2Iterations  = 100000
+	credentialsKeyLen = 32
+)
+
+func encryptCredentials(plaintext []byte, hosts []string) ([]byte, error) {
+	passphrase, err := credentialsPassphrase()
+	if err != nil {
+		return nil, err
+	}
+
+	ec := &encryptedCredentials{Hosts: hosts, Salt: make([]byte, 16)}
+	if _, err := rand.Read(ec.Salt); err != nil {
+		return nil, err
+	}
+
+	gcm, err := newCredentialsCipher(passphrase, ec.Salt)
+	if err != nil {
+		return nil, err
+	}
+	ec.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(ec.Nonce); err != nil {
+		return nil, err
+	}
+	ec.Data = gcm.Seal(nil, ec.Nonce, plaintext, nil)
+
+	return json.MarshalIndent(ec, "", "  ")
+}
+
+func decryptCredentials(content []byte) ([]byte, error) {
+	ec := &encryptedCredentials{}
+	if err := json.Unmarshal(content, ec); err != nil {
+		return nil, err
+	}
+
+	passphrase, err := credentialsPassphrase()
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newCredentialsCipher(passphrase, ec.Salt)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, ec.Nonce, ec.Data, nil)
+	if err != nil {
+		return nil, errors.New("wrong passphrase or damaged file")
+	}
+	return plaintext, nil
+}
+
+func newCredentialsCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(pbkdf2SHA256([]byte(passphrase), salt, pbkdf2Iterations, credentialsKeyLen))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// credentialsPassphrase returns the passphrase of the encrypted store, taken from the environment or asked for if the
+// client runs in a terminal.
+func credentialsPassphrase() (string, error) {
+	if passphrase := os.Getenv(credentialsPassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+	if !lineedit.IsTerminal(os.Stdin) {
+		return "", fmt.Errorf("the credentials store is encrypted, set %s to its passphrase", credentialsPassphraseEnv)
+	}
+
+	passphrase, err := lineedit.New(os.Stdin, os.Stderr).ReadSecret("Passphrase of the credentials store: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", errors.New("no passphrase entered")
+	}
+	return passphrase, nil
+}
+
+// pbkdf2SHA256 derives a key from a password as specified in RFC 8018, using HMAC-SHA256.
+func pbkdf2SHA256(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	key := make([]byte, 0, keyLen)
+
+	for block := uint32(1); len(key) < keyLen; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.Write(prf, binary.BigEndian, block)
+		u := prf.Sum(nil)
+
+		t := append([]byte{}, u...)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen]
+}
+
+// storedToken returns the token in the credentials store for host, or an empty string if there is none.
+func storedToken(host string) (string, error) {
+	store, err := openCredentialStore()
+	if err != nil {
+		// Without a config dir there is no store to read from.
+		return "", nil
+	}
+	token, err := store.Token(host)
+	if err != nil {
+		return "", fmt.Errorf("reading the credentials store failed: %s", err)
+	}
+	return token, nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func withCredentialsDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "phraseapp-credentials")
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	credentialsDir = dir
+	return dir
+}
+
+func cleanupCredentialsDir(dir string) {
+	credentialsDir = ""
+	os.RemoveAll(dir)
+}
+
+func TestCredentialStore(t *testing.T) {
+	dir := withCredentialsDir(t)
+	defer cleanupCredentialsDir(dir)
+	os.Unsetenv("PHRASEAPP_HOST")
+
+	store, err := openCredentialStore()
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if token, err := store.Token(""); err != nil || token != "" {
+		t.Errorf("expected no token in an empty store, got %q (%v)", token, err)
+	}
+
+	if err := store.Set("", "default-token"); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if err := store.Set("http://localhost:8765/", "local-token"); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, credentialsFileName))
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("expected mode 0600, got %o", mode)
+	}
+
+	for host, exp := range map[string]string{
+		"":                       "default-token",
+		"https://api.phrase.com": "default-token",
+		"http://localhost:8765":  "local-token",
+		"http://localhost:9999":  "",
+	} {
+		if token, err := storedToken(host); err != nil || token != exp {
+			t.Errorf("%q: expected %q, got %q (%v)", host, exp, token, err)
+		}
+	}
+
+	if found, err := store.Delete("http://localhost:8765"); err != nil || !found {
+		t.Errorf("expected the token to be deleted, got %t (%v)", found, err)
+	}
+	if found, err := store.Delete("http://localhost:8765"); err != nil || found {
+		t.Errorf("expected no token to be deleted, got %t (%v)", found, err)
+	}
+	if hosts, err := store.Hosts(); err != nil || strings.Join(hosts, ",") != defaultHost {
+		t.Errorf("expected only the default host, got %v (%v)", hosts, err)
+	}
+}
+
+func TestEncryptedCredentialStore(t *testing.T) {
+	dir := withCredentialsDir(t)
+	defer cleanupCredentialsDir(dir)
+	os.Setenv(credentialsPassphraseEnv, "secret")
+	defer os.Unsetenv(credentialsPassphraseEnv)
+
+	store, err := openCredentialStore()
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if err := store.Set("http://localhost:8765", "local-token"); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+
+	if store, err = store.encrypt(); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, credentialsFileName)); !os.IsNotExist(err) {
+		t.Errorf("expected the plain store to be removed, got %v", err)
+	}
+
+	content, err := ioutil.ReadFile(store.Path)
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if strings.Contains(string(content), "local-token") {
+		t.Errorf("expected the token to be encrypted, got:\n%s", content)
+	}
+
+	if store, err = openCredentialStore(); err != nil || !store.Encrypted {
+		t.Fatalf("expected the encrypted store to be used, got %+v (%v)", store, err)
+	}
+	if token, err := store.Token("http://localhost:8765"); err != nil || token != "local-token" {
+		t.Errorf("expected the stored token, got %q (%v)", token, err)
+	}
+
+	os.Setenv(credentialsPassphraseEnv, "wrong")
+	if _, err := store.Token("http://localhost:8765"); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Errorf("expected a passphrase error, got %v", err)
+	}
+	// Hosts without a stored token don't need the passphrase.
+	if token, err := store.Token("http://localhost:9999"); err != nil || token != "" {
+		t.Errorf("expected no token, got %q (%v)", token, err)
+	}
+}
+
+func TestPBKDF2SHA256(t *testing.T) {
+	// Test vector from RFC 7914, section 11.
+	key := pbkdf2SHA256([]byte("passwd"), []byte("salt"), 1, 64)
+	exp := "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"
+	if got := hex.EncodeToString(key); got != exp {
+		t.Errorf("expected %s, got %s", exp, got)
+	}
+}
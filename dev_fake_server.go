@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/phrase/phraseapp-client/internal/fakeapi"
+)
+
+type DevFakeServerCommand struct {
+	Addr  string `cli:"opt --addr default=localhost:8765 desc='Address to listen on'"`
+	Token string `cli:"opt --token desc='Access token required for requests, any token is accepted if empty'"`
+}
+
+func (cmd *DevFakeServerCommand) Run() error {
+	listener, err := net.Listen("tcp", cmd.Addr)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	server := fakeapi.New()
+	server.Token = cmd.Token
+
+	url := "http://" + listener.Addr().String()
+	fmt.Printf("Fake API listening on %s\n", url)
+	fmt.Printf("Run commands against it with PHRASEAPP_HOST=%s, or set host: %s in your .phraseapp.yml\n", url, url)
+
+	return http.Serve(listener, server)
+}
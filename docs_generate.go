@@ -0,0 +1,339 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/phrase/phraseapp-client/cli"
+	"github.com/phrase/phraseapp-client/internal/print"
+	"github.com/phrase/phraseapp-go/phraseapp"
+)
+
+type DocsGenerateCommand struct {
+	Format string `cli:"opt --format default=markdown desc='Format of the pages: markdown or man'"`
+	Out    string `cli:"opt --out default=docs desc='Directory to write the pages to'"`
+}
+
+func (cmd *DocsGenerateCommand) Run() error {
+	var render func(*cli.Route) (string, []byte)
+	var index func([]*cli.Route) (string, []byte)
+	switch cmd.Format {
+	case "markdown":
+		render, index = markdownPage, markdownIndex
+	case "man":
+		render, index = manPage, manIndex
+	default:
+		return fmt.Errorf("unknown format %q, supported formats are markdown and man", cmd.Format)
+	}
+
+	// Use an empty config, so that neither the local settings nor credentials end up in the documentation.
+	r, err := newRouter(&Config{Config: new(phraseapp.Config)})
+	if err != nil {
+		return err
+	}
+	routes := r.Routes()
+
+	if err := os.MkdirAll(cmd.Out, 0755); err != nil {
+		return err
+	}
+
+	write := func(name string, content []byte) error {
+		return ioutil.WriteFile(filepath.Join(cmd.Out, name), content, 0644)
+	}
+
+	for _, route := range routes {
+		if err := write(render(route)); err != nil {
+			return err
+		}
+	}
+	if err := write(index(routes)); err != nil {
+		return err
+	}
+
+	print.Success("Generated %d pages in %s", len(routes)+1, cmd.Out)
+	return nil
+}
+
+// Keys of the config file the fields of a command are preset from, besides the route specific defaults.
+var configKeysByField = map[string]string{
+	"Token":     "access_token",
+	"Host":      "host",
+	"Debug":     "debug",
+	"Page":      "page",
+	"PerPage":   "per_page",
+	"ProjectID": "project_id",
+}
+
+type defaultsApplier interface {
+	ApplyValuesFromMap(map[string]interface{}) error
+}
+
+// routeConfigKeys maps the fields of the route's runner to the config keys that preset them. Fields of an embedded
+// params type are preset by the key of its ApplyValuesFromMap method in the route's defaults section.
+func routeConfigKeys(route *cli.Route) map[string]string {
+	keys := map[string]string{}
+	for field, key := range configKeysByField {
+		keys[field] = key
+	}
+
+	if t := routeParamsType(route); t != nil {
+		for _, paramsField := range paramsFields(t) {
+			keys[paramsField.Name] = "defaults." + route.Path + "." + paramsField.Key
+		}
+	}
+	return keys
+}
+
+// routeParamsType returns the params type embedded in the route's runner, whose fields are preset from the route's
+// defaults section, or nil if the runner has none.
+func routeParamsType(route *cli.Route) reflect.Type {
+	v := reflect.Indirect(reflect.ValueOf(route.Runner))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	applierType := reflect.TypeOf((*defaultsApplier)(nil)).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.Anonymous && reflect.PtrTo(field.Type).Implements(applierType) {
+			return field.Type
+		}
+	}
+	return nil
+}
+
+// paramsField is a field of a params type that can be set via its ApplyValuesFromMap method.
+type paramsField struct {
+	Name string
+	Key  string
+	Type reflect.Type
+}
+
+// paramsFields returns the fields of a params type. The keys used in ApplyValuesFromMap match the names used for
+// JSON encoding.
+func paramsFields(t reflect.Type) []*paramsField {
+	fields := []*paramsField{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := strings.Split(field.Tag.Get("json"), ",")[0]
+		if key == "" || key == "-" {
+			continue
+		}
+		fields = append(fields, &paramsField{Name: field.Name, Key: key, Type: field.Type})
+	}
+	return fields
+}
+
+func commandName(route *cli.Route) string {
+	return "phraseapp " + strings.Replace(route.Path, "/", " ", -1)
+}
+
+func optionUsage(o *cli.RouteOption, sep string) string {
+	names := []string{}
+	if o.Short != "" {
+		names = append(names, "-"+o.Short)
+	}
+	if o.Long != "" {
+		names = append(names, "--"+o.Long)
+	}
+	usage := strings.Join(names, sep)
+	if !o.Flag {
+		usage += " <" + o.Name + ">"
+	}
+	return usage
+}
+
+func argumentUsage(arg *cli.RouteArgument) string {
+	usage := "<" + arg.Name + ">"
+	if !arg.Required {
+		usage += "?"
+	}
+	if arg.Variadic {
+		usage += "..."
+	}
+	return usage
+}
+
+func synopsis(route *cli.Route) string {
+	parts := []string{commandName(route)}
+	if len(route.Options) > 0 {
+		parts = append(parts, "[options]")
+	}
+	for _, arg := range route.Arguments {
+		parts = append(parts, argumentUsage(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+func markdownFilename(route *cli.Route) string {
+	return strings.Replace(route.Path, "/", "_", -1) + ".md"
+}
+
+func markdownPage(route *cli.Route) (string, []byte) {
+	keys := routeConfigKeys(route)
+	cell := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		return "`" + strings.Replace(s, "|", "\\|", -1) + "`"
+	}
+	text := func(s string) string {
+		return strings.Replace(strings.Replace(s, "|", "\\|", -1), "\n", " ", -1)
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "# %s\n\n", commandName(route))
+	fmt.Fprintf(buf, "%s\n\n", route.Description)
+	fmt.Fprintf(buf, "## Usage\n\n    %s\n", synopsis(route))
+
+	if len(route.Arguments) > 0 {
+		fmt.Fprintf(buf, "\n## Arguments\n\n")
+		fmt.Fprintf(buf, "| Argument | Required | Description | Config key |\n|---|---|---|---|\n")
+		for _, arg := range route.Arguments {
+			required := "no"
+			if arg.Required {
+				required = "yes"
+			}
+			fmt.Fprintf(buf, "| %s | %s | %s | %s |\n", cell("<"+arg.Name+">"), required, text(arg.Description), cell(keys[arg.Name]))
+		}
+	}
+
+	if len(route.Options) > 0 {
+		fmt.Fprintf(buf, "\n## Options\n\n")
+		fmt.Fprintf(buf, "| Option | Description | Default | Config key |\n|---|---|---|---|\n")
+		for _, o := range route.Options {
+			fmt.Fprintf(buf, "| %s | %s | %s | %s |\n", cell(optionUsage(o, ", ")), text(o.Description), cell(o.Default), cell(keys[o.Name]))
+		}
+	}
+
+	return markdownFilename(route), buf.Bytes()
+}
+
+func markdownIndex(routes []*cli.Route) (string, []byte) {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "# PhraseApp Client Commands\n\n")
+	fmt.Fprintf(buf, "Generated from PhraseApp client version %s.\n\n", PHRASEAPP_CLIENT_VERSION)
+	for _, route := range routes {
+		summary := strings.SplitN(route.Description, "\n", 2)[0]
+		fmt.Fprintf(buf, "* [%s](%s) - %s\n", commandName(route), markdownFilename(route), summary)
+	}
+
+	fmt.Fprintf(buf, "\n## Global options\n\nThese options can be given with all commands.\n\n")
+	fmt.Fprintf(buf, "| Option | Description |\n|---|---|\n")
+	for _, o := range globalOptions {
+		fmt.Fprintf(buf, "| `%s` | %s |\n", globalOptionUsage(o.name, o.value), strings.Replace(o.description, "|", "\\|", -1))
+	}
+	return "index.md", buf.Bytes()
+}
+
+func manName(route *cli.Route) string {
+	return "phraseapp-" + strings.Replace(strings.Replace(route.Path, "/", "-", -1), "_", "-", -1)
+}
+
+// manEscape escapes s for use in a roff document.
+func manEscape(s string) string {
+	s = strings.Replace(s, `\`, `\e`, -1)
+	s = strings.Replace(s, "-", `\-`, -1)
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			line = `\&` + line
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// sentences joins the non-empty parts to sentences, e.g. "access token used for authentication. Config key:
+// access_token."
+func sentences(parts ...string) string {
+	nonEmpty := []string{}
+	for _, part := range parts {
+		if part = strings.TrimSuffix(strings.TrimSpace(part), "."); part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	if len(nonEmpty) == 0 {
+		return ""
+	}
+	return strings.Join(nonEmpty, ". ") + "."
+}
+
+func manHeader(buf *bytes.Buffer, name string) {
+	fmt.Fprintf(buf, ".TH %s 1 \"\" \"phraseapp %s\" \"PhraseApp Client Manual\"\n", strings.ToUpper(manEscape(name)), manEscape(PHRASEAPP_CLIENT_VERSION))
+}
+
+func manPage(route *cli.Route) (string, []byte) {
+	keys := routeConfigKeys(route)
+	name := manName(route)
+	summary := strings.SplitN(route.Description, "\n", 2)[0]
+
+	buf := &bytes.Buffer{}
+	manHeader(buf, name)
+	fmt.Fprintf(buf, ".SH NAME\n%s \\- %s\n", manEscape(name), manEscape(summary))
+	fmt.Fprintf(buf, ".SH SYNOPSIS\n.B %s\n", manEscape(commandName(route)))
+	if len(route.Options) > 0 {
+		fmt.Fprintf(buf, "[\\fIoptions\\fR]\n")
+	}
+	for _, arg := range route.Arguments {
+		fmt.Fprintf(buf, "\\fI%s\\fR\n", manEscape(argumentUsage(arg)))
+	}
+	fmt.Fprintf(buf, ".SH DESCRIPTION\n%s\n", manEscape(route.Description))
+
+	if len(route.Arguments) > 0 {
+		fmt.Fprintf(buf, ".SH ARGUMENTS\n")
+		for _, arg := range route.Arguments {
+			fmt.Fprintf(buf, ".TP\n.B %s\n", manEscape("<"+arg.Name+">"))
+			details := []string{arg.Description}
+			if arg.Required {
+				details = append(details, "Required")
+			}
+			if key := keys[arg.Name]; key != "" {
+				details = append(details, "Config key: "+key)
+			}
+			fmt.Fprintf(buf, "%s\n", manEscape(sentences(details...)))
+		}
+	}
+
+	if len(route.Options) > 0 {
+		fmt.Fprintf(buf, ".SH OPTIONS\n")
+		for _, o := range route.Options {
+			fmt.Fprintf(buf, ".TP\n.B %s\n", manEscape(optionUsage(o, ", ")))
+			details := []string{o.Description}
+			if o.Default != "" {
+				details = append(details, "Default: "+o.Default)
+			}
+			if key := keys[o.Name]; key != "" {
+				details = append(details, "Config key: "+key)
+			}
+			fmt.Fprintf(buf, "%s\n", manEscape(sentences(details...)))
+		}
+	}
+
+	fmt.Fprintf(buf, ".SH SEE ALSO\n.BR phraseapp (1)\n")
+	return name + ".1", buf.Bytes()
+}
+
+func manIndex(routes []*cli.Route) (string, []byte) {
+	buf := &bytes.Buffer{}
+	manHeader(buf, "phraseapp")
+	fmt.Fprintf(buf, ".SH NAME\nphraseapp \\- PhraseApp API client\n")
+	fmt.Fprintf(buf, ".SH SYNOPSIS\n.B phraseapp\n\\fIcommand\\fR [\\fIoptions\\fR] [\\fIarguments\\fR]\n")
+	fmt.Fprintf(buf, ".SH COMMANDS\n")
+	for _, route := range routes {
+		summary := strings.SplitN(route.Description, "\n", 2)[0]
+		fmt.Fprintf(buf, ".TP\n.BR %s (1)\n%s\n", manName(route), manEscape(summary))
+	}
+	fmt.Fprintf(buf, ".SH GLOBAL OPTIONS\nThese options can be given with all commands.\n")
+	for _, o := range globalOptions {
+		fmt.Fprintf(buf, ".TP\n.B %s\n%s\n", manEscape(globalOptionUsage(o.name, o.value)), manEscape(sentences(o.description)))
+	}
+	return "phraseapp.1", buf.Bytes()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDocsGenerateMarkdown(t *testing.T) {
+	dir, err := ioutil.TempDir("", "phraseapp-docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := (&DocsGenerateCommand{Format: "markdown", Out: dir}).Run(); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+
+	page, err := ioutil.ReadFile(filepath.Join(dir, "keys_search.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"# phraseapp keys search", "`-q, --query <Q>`", "`defaults.keys/search.q`", "`<ProjectID>`", "`project_id`"} {
+		if !strings.Contains(string(page), expected) {
+			t.Errorf("expected page to contain %q, got:\n%s", expected, page)
+		}
+	}
+
+	index, err := ioutil.ReadFile(filepath.Join(dir, "index.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), "[phraseapp keys search](keys_search.md)") {
+		t.Errorf("expected index to link the keys search page, got:\n%s", index)
+	}
+	for _, expected := range []string{"## Global options", "`--profile <name>`", "`--max-retries <n>`", "`--retry-timeout <duration>`", "`--cache`", "`--record <dir>`", "`--replay <dir>`"} {
+		if !strings.Contains(string(index), expected) {
+			t.Errorf("expected index to contain %q, got:\n%s", expected, index)
+		}
+	}
+}
+
+func TestDocsGenerateMan(t *testing.T) {
+	dir, err := ioutil.TempDir("", "phraseapp-docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := (&DocsGenerateCommand{Format: "man", Out: dir}).Run(); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+
+	page, err := ioutil.ReadFile(filepath.Join(dir, "phraseapp-upload-cleanup.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(page), ".TH PHRASEAPP\\-UPLOAD\\-CLEANUP 1") {
+		t.Errorf("expected page to start with a title line, got:\n%s", page)
+	}
+
+	if !strings.Contains(string(page), "access token used for authentication. Config key: access_token.") {
+		t.Errorf("expected the description and config key as sentences, got:\n%s", page)
+	}
+
+	index, err := ioutil.ReadFile(filepath.Join(dir, "phraseapp.1"))
+	if err != nil {
+		t.Fatalf("expected overview page to be generated: %s", err)
+	}
+	if !strings.Contains(string(index), ".SH GLOBAL OPTIONS") || !strings.Contains(string(index), `\-\-replay <dir>`) {
+		t.Errorf("expected overview page to list the global options, got:\n%s", index)
+	}
+}
+
+func TestDocsGenerateUnknownFormat(t *testing.T) {
+	if err := (&DocsGenerateCommand{Format: "html", Out: os.TempDir()}).Run(); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Directories to record the HTTP traffic of the client to, or to replay it from. Set with the global --record and
+// --replay options.
+var (
+	RecordDir string
+	ReplayDir string
+)
+
+// The recorder and replayer are shared by all clients, so that the fixtures are numbered and used in the order of
+// the requests of a run.
+var (
+	recorder *recordTransport
+	replay   *replayer
+)
+
+// fixturesTransport wraps the given transport to record its traffic to RecordDir, or replaces it with the replay of
+// the fixtures in ReplayDir. The transport is returned as is if neither is set.
+func fixturesTransport(transport http.RoundTripper) (http.RoundTripper, error) {
+	var err error
+	switch {
+	case ReplayDir != "":
+		if replay == nil {
+			replay, err = newReplayer(ReplayDir)
+		}
+		return replay, err
+	case RecordDir != "":
+		if recorder == nil {
+			recorder, err = newRecordTransport(transport, RecordDir)
+		}
+		return recorder, err
+	}
+	return transport, nil
+}
+
+// Headers replaced in recorded requests, as they contain credentials.
+var scrubbedHeaders = []string{"Authorization", "X-PhraseApp-OTP"}
+
+const scrubbedValue = "[FILTERED]"
+
+// fixture is a request and the response to it, stored as JSON file.
+type fixture struct {
+	Request  fixtureRequest  `json:"request"`
+	Response fixtureResponse `json:"response"`
+}
+
+type fixtureRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header"`
+	fixtureBody
+}
+
+type fixtureResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	fixtureBody
+}
+
+// fixtureBody holds a body as text, or base64 encoded if it isn't valid UTF-8.
+type fixtureBody struct {
+	Body         string `json:"body,omitempty"`
+	BodyEncoding string `json:"body_encoding,omitempty"`
+}
+
+func newFixtureBody(b []byte) fixtureBody {
+	if utf8.Valid(b) {
+		return fixtureBody{Body: string(b)}
+	}
+	return fixtureBody{Body: base64.StdEncoding.EncodeToString(b), BodyEncoding: "base64"}
+}
+
+func (b fixtureBody) bytes() ([]byte, error) {
+	if b.BodyEncoding == "base64" {
+		return base64.StdEncoding.DecodeString(b.Body)
+	}
+	return []byte(b.Body), nil
+}
+
+// matches reports whether a request has the same method, path and query as the recorded one. The host is ignored, so
+// that fixtures can be replayed against a test server.
+func (r *fixtureRequest) matches(req *http.Request) bool {
+	recorded := r.URL
+	if i := strings.Index(recorded, "://"); i >= 0 {
+		recorded = recorded[i+3:]
+		if j := strings.Index(recorded, "/"); j >= 0 {
+			recorded = recorded[j:]
+		} else {
+			recorded = "/"
+		}
+	}
+	return r.Method == req.Method && recorded == req.URL.RequestURI()
+}
+
+// recordTransport stores every request sent and the response received as fixture in a directory. The files are
+// numbered in the order of the requests, continuing after the fixtures already in the directory.
+type recordTransport struct {
+	Transport http.RoundTripper // Transport used to send the requests, http.DefaultTransport if nil.
+	Dir       string
+
+	mutex sync.Mutex
+	next  int
+}
+
+func newRecordTransport(transport http.RoundTripper, dir string) (*recordTransport, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	files, err := fixtureFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &recordTransport{Transport: transport, Dir: dir, next: len(files) + 1}, nil
+}
+
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		reqBody = body
+
+		sent := *req
+		sent.Body = ioutil.NopCloser(bytes.NewReader(body))
+		req = &sent
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	header := req.Header.Clone()
+	for _, name := range scrubbedHeaders {
+		if header.Get(name) != "" {
+			header.Set(name, scrubbedValue)
+		}
+	}
+
+	f := &fixture{
+		Request: fixtureRequest{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			Header:      header,
+			fixtureBody: newFixtureBody(reqBody),
+		},
+		Response: fixtureResponse{
+			StatusCode:  resp.StatusCode,
+			Header:      resp.Header,
+			fixtureBody: newFixtureBody(respBody),
+		},
+	}
+	if err := t.write(f); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (t *recordTransport) write(f *fixture) error {
+	content, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	name := fmt.Sprintf("%04d_%s.json", t.next, strings.ToLower(f.Request.Method))
+	t.next++
+	return ioutil.WriteFile(filepath.Join(t.Dir, name), append(content, '\n'), 0600)
+}
+
+// replayer serves the responses of recorded fixtures, either as http.RoundTripper or, e.g. for tests with
+// httptest.NewServer, as http.Handler. Each request is answered with the first fixture not used yet that has the same
+// method, path and query.
+type replayer struct {
+	mutex    sync.Mutex
+	fixtures []*fixture
+	used     []bool
+}
+
+func newReplayer(dir string) (*replayer, error) {
+	files, err := fixtureFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no fixtures found in %s", dir)
+	}
+
+	r := &replayer{used: make([]bool, len(files))}
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		f := &fixture{}
+		if err := json.Unmarshal(content, f); err != nil {
+			return nil, fmt.Errorf("%s: %s", file, err)
+		}
+		r.fixtures = append(r.fixtures, f)
+	}
+	return r, nil
+}
+
+func (r *replayer) take(req *http.Request) (*fixture, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i, f := range r.fixtures {
+		if !r.used[i] && f.Request.matches(req) {
+			r.used[i] = true
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("no recorded response left for %s %s", req.Method, req.URL.RequestURI())
+}
+
+func (r *replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	f, err := r.take(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := f.Response.bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Response.StatusCode, http.StatusText(f.Response.StatusCode)),
+		StatusCode:    f.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        f.Response.Header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func (r *replayer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	f, err := r.take(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+		return
+	}
+
+	body, err := f.Response.bytes()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for name, values := range f.Response.Header {
+		if name == "Content-Length" {
+			continue
+		}
+		w.Header()[name] = values
+	}
+	w.WriteHeader(f.Response.StatusCode)
+	w.Write(body)
+}
+
+func fixtureFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "phraseapp-fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"method":"` + r.Method + `","body":"` + string(body) + `"}`))
+	}))
+
+	recorder, err := newRecordTransport(nil, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: recorder}
+
+	req, _ := http.NewRequest("POST", server.URL+"/v2/projects/abc/uploads?branch=feature", strings.NewReader("first"))
+	req.Header.Set("Authorization", "token secret")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	resp, err = client.Get(server.URL + "/v2/projects/abc/locales")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	server.Close()
+
+	files, err := fixtureFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || !strings.HasSuffix(files[0], "0001_post.json") || !strings.HasSuffix(files[1], "0002_get.json") {
+		t.Fatalf("expected two numbered fixtures, got %q", files)
+	}
+
+	content, err := ioutil.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "secret") {
+		t.Errorf("expected the token to be scrubbed, got:\n%s", content)
+	}
+
+	replay, err := newReplayer(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayServer := httptest.NewServer(replay)
+	defer replayServer.Close()
+
+	resp, err = http.Post(replayServer.URL+"/v2/projects/abc/uploads?branch=feature", "text/plain", strings.NewReader("other"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || string(body) != `{"method":"POST","body":"first"}` {
+		t.Errorf("expected the recorded response, got %d %q", resp.StatusCode, body)
+	}
+
+	// Each fixture is only used once.
+	resp, err = http.Post(replayServer.URL+"/v2/projects/abc/uploads?branch=feature", "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotImplemented {
+		t.Errorf("expected no recorded response to be left, got status %d", resp.StatusCode)
+	}
+
+	resp, err = (&http.Client{Transport: replay}).Get("https://api.phrase.com/v2/projects/abc/locales")
+	if err != nil {
+		t.Fatalf("expected the recorded response to be replayed for any host, got: %s", err)
+	}
+	resp.Body.Close()
+}
@@ -80,9 +80,17 @@ type TargetsYAML SourcesYAML
 type InitCommand struct {
 	phraseapp.Config
 
+	Token     string `cli:"opt --token desc='Access token to use, asked for if not set'"`
+	ProjectID string `cli:"opt --project-id desc='Project to configure, asked for if not set'"`
+	Format    string `cli:"opt --format desc='File format of the locale files, e.g. yml'"`
+	Source    string `cli:"opt --source desc='File pattern of the locale files to push'"`
+	Target    string `cli:"opt --target desc='File pattern of the locale files to pull'"`
+	NoPush    bool   `cli:"opt --no-push desc='Don’t offer to push the locale files after writing the config'"`
+
 	client     *phraseapp.Client
 	YAML       ConfigYAML
 	FileFormat *phraseapp.Format
+	layout     *projectLayout
 }
 
 func (cmd *InitCommand) Run() error {
@@ -91,6 +99,10 @@ func (cmd *InitCommand) Run() error {
 		cmd.YAML.Host = cmd.Config.Credentials.Host
 	}
 
+	if wd, err := os.Getwd(); err == nil {
+		cmd.layout = detectLayout(wd)
+	}
+
 	step := StepAskForToken
 
 	for step != StepFinished {
@@ -112,6 +124,13 @@ func (cmd *InitCommand) askForToken() error {
 	fmt.Println("phrase.com API Client Setup")
 	fmt.Println()
 
+	if cmd.Token != "" {
+		if !validToken(cmd.Token) {
+			return fmt.Errorf("invalid access token %q: a valid access token is 64 characters long and contains only a-f, 0-9", cmd.Token)
+		}
+		return cmd.useToken(strings.ToLower(cmd.Token))
+	}
+
 	token := ""
 	for {
 		err := prompt.P("Please enter your API access token (you can generate one in your profile at phrase.com):", &token)
@@ -120,12 +139,7 @@ func (cmd *InitCommand) askForToken() error {
 		}
 
 		token = strings.ToLower(token)
-		success, err := regexp.MatchString("^[0-9a-f]{64}$", token)
-		if err != nil {
-			continue
-		}
-
-		if !success {
+		if !validToken(token) {
 			print.Failure("Invalid access token! A valid access token is 64 characters long and contains only a-f, 0-9.")
 			continue
 		}
@@ -133,6 +147,14 @@ func (cmd *InitCommand) askForToken() error {
 		break
 	}
 
+	return cmd.useToken(token)
+}
+
+func validToken(token string) bool {
+	return regexp.MustCompile("^[0-9a-fA-F]{64}$").MatchString(token)
+}
+
+func (cmd *InitCommand) useToken(token string) error {
 	cmd.YAML.AccessToken = token
 
 	cmd.Credentials.Token = token
@@ -146,6 +168,12 @@ func (cmd *InitCommand) askForToken() error {
 }
 
 func (cmd *InitCommand) selectProject() error {
+	if cmd.ProjectID != "" {
+		cmd.YAML.ProjectID = cmd.ProjectID
+		print.Success("Using project %v", cmd.ProjectID)
+		return nil
+	}
+
 	taskResult := make(chan []*phraseapp.Project, 1)
 	taskErr := make(chan error, 1)
 
@@ -240,9 +268,31 @@ func (cmd *InitCommand) selectFormat() error {
 		return err
 	}
 
-	// ensure that the default file format from the config file is a valid format
+	if cmd.Format != "" {
+		for _, format := range formats {
+			if format.ApiName == cmd.Format {
+				cmd.FileFormat = format
+				print.Success("Using format %v", format.Name)
+				return nil
+			}
+		}
+		names := make([]string, 0, len(formats))
+		for _, format := range formats {
+			names = append(names, format.ApiName)
+		}
+		return fmt.Errorf("unknown format %q, valid formats are: %s", cmd.Format, strings.Join(names, ", "))
+	}
+
+	// the format of detected locale files takes precedence over the main format of the project
+	defaultFormat := cmd.DefaultFileFormat
+	if cmd.layout != nil {
+		print.Success("Found locale files of a %s project", cmd.layout.Name)
+		defaultFormat = cmd.layout.FileFormat
+	}
+
+	// ensure that the default file format is a valid format
 	for _, format := range formats {
-		if format.ApiName == cmd.DefaultFileFormat {
+		if format.ApiName == defaultFormat {
 			cmd.FileFormat = format
 			break
 		}
@@ -284,12 +334,20 @@ func (cmd *InitCommand) selectFormat() error {
 }
 
 func (cmd *InitCommand) configureSources() error {
+	if cmd.Source != "" {
+		if err := paths.Validate(cmd.Source, cmd.FileFormat.ApiName, cmd.FileFormat.Extension); err != nil {
+			return err
+		}
+		cmd.addSource(cmd.Source)
+		return nil
+	}
+
 	fmt.Println("Enter the path to the language file you want to upload to PhraseApp.")
 	fmt.Printf("For documentation, see %s#push\n", shared.DocsConfigUrl)
 
 	pushPath := ""
 	for {
-		err := prompt.WithDefault("Source file path:", &pushPath, cmd.FileFormat.DefaultFile)
+		err := prompt.WithDefault("Source file path:", &pushPath, cmd.layout.sourcePattern(cmd.FileFormat))
 		if err != nil {
 			return err
 		}
@@ -302,6 +360,12 @@ func (cmd *InitCommand) configureSources() error {
 		}
 	}
 
+	cmd.addSource(pushPath)
+
+	return nil
+}
+
+func (cmd *InitCommand) addSource(pushPath string) {
 	sourceYAML := SourcesYAML{
 		File: pushPath,
 		Params: map[string]interface{}{
@@ -310,17 +374,23 @@ func (cmd *InitCommand) configureSources() error {
 	}
 
 	cmd.YAML.Push.Sources = append(cmd.YAML.Push.Sources, sourceYAML)
-
-	return nil
 }
 
 func (cmd *InitCommand) configureTargets() error {
+	if cmd.Target != "" {
+		if err := paths.Validate(cmd.Target, cmd.FileFormat.ApiName, cmd.FileFormat.Extension); err != nil {
+			return err
+		}
+		cmd.addTarget(cmd.Target)
+		return nil
+	}
+
 	fmt.Println("Enter the path to which to download language files from PhraseApp.")
 	fmt.Printf("For documentation, see %s#pull\n", shared.DocsConfigUrl)
 
 	pullPath := ""
 	for {
-		err := prompt.WithDefault("Target file path:", &pullPath, cmd.FileFormat.DefaultFile)
+		err := prompt.WithDefault("Target file path:", &pullPath, cmd.layout.targetPattern(cmd.FileFormat))
 		if err != nil {
 			return err
 		}
@@ -333,6 +403,12 @@ func (cmd *InitCommand) configureTargets() error {
 		}
 	}
 
+	cmd.addTarget(pullPath)
+
+	return nil
+}
+
+func (cmd *InitCommand) addTarget(pullPath string) {
 	targetYAML := TargetsYAML{
 		File: pullPath,
 		Params: map[string]interface{}{
@@ -341,11 +417,13 @@ func (cmd *InitCommand) configureTargets() error {
 	}
 
 	cmd.YAML.Pull.Targets = append(cmd.YAML.Pull.Targets, targetYAML)
-
-	return nil
 }
 
 func (cmd *InitCommand) writeConfig() error {
+	if err := cmd.storeToken(); err != nil {
+		return err
+	}
+
 	wrapper := struct {
 		Config ConfigYAML `yaml:"phraseapp"`
 	}{
@@ -375,12 +453,14 @@ func (cmd *InitCommand) writeConfig() error {
 	fmt.Println("$ phraseapp pull")
 	fmt.Println()
 
-	pushNow := ""
-	err = prompt.WithDefault("Do you want to upload your locales now for the first time? (y/n)", &pushNow, "y")
-	if pushNow == "y" {
-		err = firstPush()
-		if err != nil {
-			return err
+	if !cmd.NoPush {
+		pushNow := ""
+		err = prompt.WithDefault("Do you want to upload your locales now for the first time? (y/n)", &pushNow, "y")
+		if pushNow == "y" {
+			err = firstPush()
+			if err != nil {
+				return err
+			}
 		}
 	}
 
@@ -389,12 +469,43 @@ func (cmd *InitCommand) writeConfig() error {
 	return nil
 }
 
+// storeToken offers to keep the access token in the credentials store instead of the config file, which is likely
+// to be committed.
+func (cmd *InitCommand) storeToken() error {
+	// a token given as flag is kept in the config file, as init is scripted then
+	if cmd.YAML.AccessToken == "" || cmd.Token != "" {
+		return nil
+	}
+
+	storeToken := ""
+	err := prompt.WithDefault("Do you want to keep the access token in the credentials store instead of the config file? (y/n)", &storeToken, "y")
+	if err != nil {
+		return err
+	}
+	if storeToken != "y" {
+		return nil
+	}
+
+	store, err := openCredentialStore()
+	if err != nil {
+		return err
+	}
+	if err := store.Set(cmd.Credentials.Host, cmd.YAML.AccessToken); err != nil {
+		return err
+	}
+	cmd.YAML.AccessToken = ""
+
+	print.Success("Stored the access token in %s", store.Path)
+	fmt.Println()
+	return nil
+}
+
 func firstPush() error {
-	cfg, err := phraseapp.ReadConfig()
+	cfg, err := ReadConfig()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 		os.Exit(2)
 	}
-	cmd := &PushCommand{Config: *cfg}
+	cmd := &PushCommand{Config: *cfg.Config}
 	return cmd.Run()
 }
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+
+	"github.com/phrase/phraseapp-client/internal/paths"
+	"github.com/phrase/phraseapp-go/phraseapp"
+)
+
+// projectLayout is a known layout of locale files, with the format and the patterns to push and pull them.
+type projectLayout struct {
+	Name       string
+	FileFormat string
+	Source     string
+	Target     string
+}
+
+// layoutDetectors find the locale files of common frameworks, in the order they are tried.
+var layoutDetectors = []func(dir string) *projectLayout{
+	detectRailsLayout,
+	detectAndroidLayout,
+	detectIOSLayout,
+	detectJSONLayout,
+}
+
+// detectLayout returns the layout of the locale files in dir, or nil if none of the known layouts is found.
+func detectLayout(dir string) *projectLayout {
+	for _, detect := range layoutDetectors {
+		if layout := detect(dir); layout != nil {
+			return layout
+		}
+	}
+	return nil
+}
+
+func detectRailsLayout(dir string) *projectLayout {
+	if !paths.IsDir(filepath.Join(dir, "config", "locales")) {
+		return nil
+	}
+	pattern := "./config/locales/<locale_code>.yml"
+	return &projectLayout{Name: "Rails", FileFormat: "yml", Source: pattern, Target: pattern}
+}
+
+// androidLocaleDirRegexp matches the names of resource directories of a locale, like values-de or values-pt-rBR, and
+// not the ones of other qualifiers, like values-v21, values-land or values-car.
+var androidLocaleDirRegexp = regexp.MustCompile(`^values-[a-z]{2}(-r[A-Z]{2})?$`)
+
+// detectAndroidLayout finds the strings.xml files of the default resources or of locales in a res directory.
+func detectAndroidLayout(dir string) *projectLayout {
+	for _, resDir := range []string{"res", "src/main/res", "app/src/main/res"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, resDir, "values*", "strings.xml"))
+		for _, match := range matches {
+			name := filepath.Base(filepath.Dir(match))
+			if name != "values" && !androidLocaleDirRegexp.MatchString(name) {
+				continue
+			}
+			pattern := "./" + resDir + "/values-<locale_code>/strings.xml"
+			return &projectLayout{Name: "Android", FileFormat: "xml", Source: pattern, Target: pattern}
+		}
+	}
+	return nil
+}
+
+func detectIOSLayout(dir string) *projectLayout {
+	for _, glob := range []string{"*.lproj", "*/*.lproj"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, glob, "Localizable.strings"))
+		if len(matches) == 0 {
+			continue
+		}
+		rel, err := filepath.Rel(dir, filepath.Dir(filepath.Dir(matches[0])))
+		if err != nil {
+			continue
+		}
+		pattern := "./" + filepath.ToSlash(filepath.Join(rel, "<locale_code>.lproj", "Localizable.strings"))
+		return &projectLayout{Name: "iOS", FileFormat: "strings", Source: pattern, Target: pattern}
+	}
+	return nil
+}
+
+func detectJSONLayout(dir string) *projectLayout {
+	for _, i18nDir := range []string{"i18n", "src/i18n", "src/assets/i18n", "public/i18n"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, i18nDir, "*.json"))
+		if len(matches) == 0 {
+			continue
+		}
+		format := "simple_json"
+		if isNestedJSON(matches[0]) {
+			format = "nested_json"
+		}
+		pattern := "./" + i18nDir + "/<locale_code>.json"
+		return &projectLayout{Name: "i18n JSON", FileFormat: format, Source: pattern, Target: pattern}
+	}
+	return nil
+}
+
+// isNestedJSON reports whether the JSON file at path has objects as values, i.e. keys nested by their namespaces.
+func isNestedJSON(path string) bool {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	values := map[string]interface{}{}
+	if err := json.Unmarshal(content, &values); err != nil {
+		return false
+	}
+	for _, v := range values {
+		if _, ok := v.(map[string]interface{}); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// sourcePattern returns the pattern to push the files of the layout if it has the given format, else the default file
+// of the format.
+func (l *projectLayout) sourcePattern(format *phraseapp.Format) string {
+	if l == nil || l.FileFormat != format.ApiName {
+		return format.DefaultFile
+	}
+	return l.Source
+}
+
+// targetPattern is like sourcePattern, for the pattern to pull the files to.
+func (l *projectLayout) targetPattern(format *phraseapp.Format) string {
+	if l == nil || l.FileFormat != format.ApiName {
+		return format.DefaultFile
+	}
+	return l.Target
+}
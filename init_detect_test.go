@@ -0,0 +1,80 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/phrase/phraseapp-go/phraseapp"
+)
+
+func TestDetectLayout(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		exp   *projectLayout
+	}{
+		{"rails", map[string]string{"config/locales/en.yml": "en:\n  hello: Hello\n"},
+			&projectLayout{"Rails", "yml", "./config/locales/<locale_code>.yml", "./config/locales/<locale_code>.yml"}},
+		{"android", map[string]string{"app/src/main/res/values-de/strings.xml": "<resources/>"},
+			&projectLayout{"Android", "xml", "./app/src/main/res/values-<locale_code>/strings.xml", "./app/src/main/res/values-<locale_code>/strings.xml"}},
+		{"android default resources", map[string]string{"res/values/strings.xml": "<resources/>", "res/values-v21/strings.xml": "<resources/>"},
+			&projectLayout{"Android", "xml", "./res/values-<locale_code>/strings.xml", "./res/values-<locale_code>/strings.xml"}},
+		{"android region", map[string]string{"src/main/res/values-pt-rBR/strings.xml": "<resources/>"},
+			&projectLayout{"Android", "xml", "./src/main/res/values-<locale_code>/strings.xml", "./src/main/res/values-<locale_code>/strings.xml"}},
+		{"android qualifiers only", map[string]string{"res/values-v21/strings.xml": "<resources/>", "res/values-land/strings.xml": "<resources/>", "res/values-car/strings.xml": "<resources/>"}, nil},
+		{"ios", map[string]string{"App/en.lproj/Localizable.strings": `"hello" = "Hello";`},
+			&projectLayout{"iOS", "strings", "./App/<locale_code>.lproj/Localizable.strings", "./App/<locale_code>.lproj/Localizable.strings"}},
+		{"simple json", map[string]string{"i18n/en.json": `{"hello": "Hello"}`},
+			&projectLayout{"i18n JSON", "simple_json", "./i18n/<locale_code>.json", "./i18n/<locale_code>.json"}},
+		{"nested json", map[string]string{"src/assets/i18n/en.json": `{"greeting": {"hello": "Hello"}}`},
+			&projectLayout{"i18n JSON", "nested_json", "./src/assets/i18n/<locale_code>.json", "./src/assets/i18n/<locale_code>.json"}},
+		{"unknown", map[string]string{"locales/en.yml": "en:\n"}, nil},
+	}
+
+	for _, test := range tests {
+		dir, err := ioutil.TempDir("", "phraseapp-init")
+		if err != nil {
+			t.Fatalf("didn't expect an error, got: %s", err)
+		}
+		defer os.RemoveAll(dir)
+
+		for name, content := range test.files {
+			path := filepath.Join(dir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("didn't expect an error, got: %s", err)
+			}
+			if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("didn't expect an error, got: %s", err)
+			}
+		}
+
+		got := detectLayout(dir)
+		switch {
+		case test.exp == nil && got != nil:
+			t.Errorf("%s: expected no layout, got %+v", test.name, got)
+		case test.exp != nil && (got == nil || *got != *test.exp):
+			t.Errorf("%s: expected %+v, got %+v", test.name, test.exp, got)
+		}
+	}
+}
+
+func TestLayoutPatterns(t *testing.T) {
+	layout := &projectLayout{Name: "Rails", FileFormat: "yml", Source: "./config/locales/<locale_code>.yml", Target: "./out/<locale_code>.yml"}
+	yml := &phraseapp.Format{ApiName: "yml", DefaultFile: "./locales/<locale_code>.yml"}
+	json := &phraseapp.Format{ApiName: "simple_json", DefaultFile: "./locales/<locale_code>.json"}
+
+	if got := layout.sourcePattern(yml); got != layout.Source {
+		t.Errorf("expected %q, got %q", layout.Source, got)
+	}
+	if got := layout.targetPattern(yml); got != layout.Target {
+		t.Errorf("expected %q, got %q", layout.Target, got)
+	}
+	if got := layout.sourcePattern(json); got != json.DefaultFile {
+		t.Errorf("expected the default file for another format, got %q", got)
+	}
+	if got := (*projectLayout)(nil).targetPattern(yml); got != yml.DefaultFile {
+		t.Errorf("expected the default file without a layout, got %q", got)
+	}
+}
@@ -0,0 +1,184 @@
+package fakeapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/phrase/phraseapp-go/phraseapp"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// formats are the file formats the fake server can import and export.
+var formats = []*phraseapp.Format{
+	{
+		ApiName:                   "yml",
+		Name:                      "Ruby/Rails YAML",
+		Description:               "YAML file format for use with Ruby/Rails applications",
+		Extension:                 "yml",
+		DefaultEncoding:           "UTF-8",
+		Importable:                true,
+		Exportable:                true,
+		DefaultFile:               "./config/locales/<locale_name>.yml",
+		IncludesLocaleInformation: true,
+	},
+	{
+		ApiName:         "simple_json",
+		Name:            "Simple JSON",
+		Description:     "Simple JSON file format with flat keys",
+		Extension:       "json",
+		DefaultEncoding: "UTF-8",
+		Importable:      true,
+		Exportable:      true,
+		DefaultFile:     "./locales/<locale_name>.json",
+	},
+	{
+		ApiName:         "nested_json",
+		Name:            "Nested JSON",
+		Description:     "JSON file format with nested keys",
+		Extension:       "json",
+		DefaultEncoding: "UTF-8",
+		Importable:      true,
+		Exportable:      true,
+		DefaultFile:     "./locales/<locale_name>.json",
+	},
+}
+
+func supportedFormat(apiName string) bool {
+	for _, f := range formats {
+		if f.ApiName == apiName {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFile returns the translations of a locale file by locale code and key name. Formats without locale information
+// return the translations for the empty locale code. Nested keys are joined with dots.
+func parseFile(format string, content []byte) (map[string]map[string]string, error) {
+	switch format {
+	case "yml":
+		raw := map[interface{}]interface{}{}
+		if err := yaml.Unmarshal(content, &raw); err != nil {
+			return nil, err
+		}
+
+		result := map[string]map[string]string{}
+		for code, v := range raw {
+			translations := map[string]string{}
+			if err := flatten("", v, translations); err != nil {
+				return nil, err
+			}
+			result[fmt.Sprint(code)] = translations
+		}
+		return result, nil
+	case "simple_json", "nested_json":
+		var raw interface{}
+		if err := json.Unmarshal(content, &raw); err != nil {
+			return nil, err
+		}
+
+		translations := map[string]string{}
+		if err := flatten("", raw, translations); err != nil {
+			return nil, err
+		}
+		return map[string]map[string]string{"": translations}, nil
+	}
+	return nil, fmt.Errorf("unsupported file format %q", format)
+}
+
+func flatten(prefix string, v interface{}, translations map[string]string) error {
+	join := func(k interface{}) string {
+		if prefix == "" {
+			return fmt.Sprint(k)
+		}
+		return prefix + "." + fmt.Sprint(k)
+	}
+
+	switch value := v.(type) {
+	case map[interface{}]interface{}:
+		for k, child := range value {
+			if err := flatten(join(k), child, translations); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		for k, child := range value {
+			if err := flatten(join(k), child, translations); err != nil {
+				return err
+			}
+		}
+	case nil:
+		translations[prefix] = ""
+	case []interface{}:
+		return fmt.Errorf("lists are not supported (key %q)", prefix)
+	default:
+		if prefix == "" {
+			return fmt.Errorf("expected a map of translations, got %v", v)
+		}
+		translations[prefix] = fmt.Sprint(value)
+	}
+	return nil
+}
+
+// renderFile renders the translations of a locale in the given format.
+func renderFile(format, code string, translations map[string]string) ([]byte, error) {
+	switch format {
+	case "yml":
+		return yaml.Marshal(yaml.MapSlice{{Key: code, Value: nest(translations)}})
+	case "nested_json":
+		content, err := json.MarshalIndent(nestedMap(nest(translations)), "", "  ")
+		return append(content, '\n'), err
+	case "simple_json":
+		content, err := json.MarshalIndent(translations, "", "  ")
+		return append(content, '\n'), err
+	}
+	return nil, fmt.Errorf("unsupported file format %q", format)
+}
+
+// nest turns keys joined with dots into nested maps, sorted by key. A key that is a prefix of another key is skipped.
+func nest(translations map[string]string) yaml.MapSlice {
+	names := make([]string, 0, len(translations))
+	for name := range translations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	root := yaml.MapSlice{}
+	for _, name := range names {
+		root = insert(root, strings.Split(name, "."), translations[name])
+	}
+	return root
+}
+
+func insert(m yaml.MapSlice, path []string, value string) yaml.MapSlice {
+	for i, item := range m {
+		if item.Key != path[0] {
+			continue
+		}
+		child, ok := item.Value.(yaml.MapSlice)
+		if !ok || len(path) == 1 {
+			return m
+		}
+		m[i].Value = insert(child, path[1:], value)
+		return m
+	}
+
+	if len(path) == 1 {
+		return append(m, yaml.MapItem{Key: path[0], Value: value})
+	}
+	return append(m, yaml.MapItem{Key: path[0], Value: insert(yaml.MapSlice{}, path[1:], value)})
+}
+
+func nestedMap(m yaml.MapSlice) map[string]interface{} {
+	result := map[string]interface{}{}
+	for _, item := range m {
+		if child, ok := item.Value.(yaml.MapSlice); ok {
+			result[fmt.Sprint(item.Key)] = nestedMap(child)
+		} else {
+			result[fmt.Sprint(item.Key)] = item.Value
+		}
+	}
+	return result
+}
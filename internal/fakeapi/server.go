@@ -0,0 +1,566 @@
+// Package fakeapi implements an in-memory fake of the parts of the PhraseApp API used by the client: formats, locales,
+// uploads, downloads, branches and keys. Projects are created on first access. Uploaded YAML and JSON files are turned
+// into keys and translations, which can then be downloaded again.
+package fakeapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/phrase/phraseapp-go/phraseapp"
+)
+
+// user is the user all tokens belong to.
+var user = &phraseapp.User{ID: "user", Username: "fake", Name: "Fake User", Email: "fake@example.com"}
+
+// Server is a fake PhraseApp API, to be used with httptest.NewServer or http.ListenAndServe.
+type Server struct {
+	// Token required for requests. If empty, any token is accepted, but requests still need to be authenticated.
+	Token string
+
+	mutex    sync.Mutex
+	projects map[string]*project
+	lastID   int
+}
+
+func New() *Server {
+	return &Server{projects: map[string]*project{}}
+}
+
+// request is an API request with its path split into segments after the "/v2" prefix.
+type request struct {
+	*http.Request
+	segments []string
+	w        http.ResponseWriter
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authenticated(r) {
+		writeError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	path := strings.Trim(r.URL.Path, "/")
+	if !strings.HasPrefix(path, "v2/") {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	req := &request{Request: r, segments: strings.Split(strings.TrimPrefix(path, "v2/"), "/"), w: w}
+	switch {
+	case req.match("GET", "formats"):
+		writeJSON(w, http.StatusOK, paginate(req, formats))
+	case req.match("GET", "user"):
+		writeJSON(w, http.StatusOK, user)
+	case len(req.segments) >= 3 && req.segments[0] == "projects":
+		s.serveProject(req)
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+func (s *Server) authenticated(r *http.Request) bool {
+	auth := r.Header.Get("Authorization")
+	if s.Token == "" {
+		return auth != ""
+	}
+	return auth == "token "+s.Token
+}
+
+// match reports whether the request has the given method and path. Segments given as "*" match any value.
+func (r *request) match(method string, segments ...string) bool {
+	if r.Method != method || len(r.segments) != len(segments) {
+		return false
+	}
+	for i, s := range segments {
+		if s != "*" && s != r.segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// param returns a parameter given in the query or, for multipart requests, in the form.
+func (r *request) param(name string) string {
+	if v := r.URL.Query().Get(name); v != "" {
+		return v
+	}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		return r.FormValue(name)
+	}
+	return ""
+}
+
+func (s *Server) serveProject(req *request) {
+	projectID := req.segments[1]
+	p, found := s.projects[projectID]
+	if !found {
+		p = &project{main: newSpace(), branches: map[string]*branch{}}
+		s.projects[projectID] = p
+	}
+
+	// Strip "projects/<id>" to match the resource paths.
+	req.segments = req.segments[2:]
+
+	if req.segments[0] == "branches" {
+		s.serveBranches(req, p)
+		return
+	}
+
+	body := map[string]interface{}{}
+	if strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		content, err := ioutil.ReadAll(req.Body)
+		if err == nil && len(content) > 0 {
+			if err := json.Unmarshal(content, &body); err != nil {
+				writeError(req.w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+	}
+	branchName := req.param("branch")
+	if b, ok := body["branch"].(string); ok && branchName == "" {
+		branchName = b
+	}
+
+	sp := p.main
+	if branchName != "" {
+		b, found := p.branches[branchName]
+		if !found {
+			writeError(req.w, http.StatusNotFound, "Not Found")
+			return
+		}
+		sp = b.data
+	}
+
+	switch req.segments[0] {
+	case "locales":
+		s.serveLocales(req, sp, body)
+	case "uploads":
+		s.serveUploads(req, sp)
+	case "keys":
+		s.serveKeys(req, sp, body)
+	case "translations":
+		s.serveTranslations(req, sp, body)
+	default:
+		writeError(req.w, http.StatusNotFound, "Not Found")
+	}
+}
+
+func (s *Server) serveLocales(req *request, sp *space, body map[string]interface{}) {
+	switch {
+	case req.match("GET", "locales"):
+		writeJSON(req.w, http.StatusOK, paginate(req, sortedLocales(sp.locales)))
+	case req.match("POST", "locales"):
+		name, _ := body["name"].(string)
+		code, _ := body["code"].(string)
+		if name == "" || code == "" {
+			writeError(req.w, http.StatusUnprocessableEntity, "name and code are required")
+			return
+		}
+		if sp.locale(name) != nil || sp.locale(code) != nil {
+			writeError(req.w, http.StatusUnprocessableEntity, "locale already exists")
+			return
+		}
+		l := s.createLocale(sp, name, code)
+		if def, _ := body["default"].(bool); def {
+			for _, other := range sp.locales {
+				other.Default = false
+			}
+			l.Default = true
+		}
+		writeJSON(req.w, http.StatusCreated, &phraseapp.LocaleDetails{Locale: l.Locale})
+	case req.match("GET", "locales", "*"):
+		l := sp.locale(req.segments[1])
+		if l == nil {
+			writeError(req.w, http.StatusNotFound, "Not Found")
+			return
+		}
+		writeJSON(req.w, http.StatusOK, &phraseapp.LocaleDetails{Locale: l.Locale})
+	case req.match("GET", "locales", "*", "download"):
+		l := sp.locale(req.segments[1])
+		if l == nil {
+			writeError(req.w, http.StatusNotFound, "Not Found")
+			return
+		}
+
+		format := req.param("file_format")
+		if format == "" {
+			format = "yml"
+		}
+		content, err := renderFile(format, l.Code, sp.translations(l, req.param("tag")))
+		if err != nil {
+			writeError(req.w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		req.w.WriteHeader(http.StatusOK)
+		req.w.Write(content)
+	default:
+		writeError(req.w, http.StatusNotFound, "Not Found")
+	}
+}
+
+func (s *Server) createLocale(sp *space, name, code string) *locale {
+	l := &locale{Locale: phraseapp.Locale{ID: s.newID(), Name: name, Code: code, CreatedAt: now(), UpdatedAt: now()}}
+	if len(sp.locales) == 0 {
+		l.Default = true
+	}
+	sp.locales = append(sp.locales, l)
+	return l
+}
+
+func (s *Server) serveUploads(req *request, sp *space) {
+	switch {
+	case req.match("POST", "uploads"):
+		s.createUpload(req, sp)
+	case req.match("GET", "uploads"):
+		uploads := []*phraseapp.Upload{}
+		for _, u := range sp.uploads {
+			uploads = append(uploads, &u.Upload)
+		}
+		sort.Slice(uploads, func(i, j int) bool { return uploads[i].ID < uploads[j].ID })
+		writeJSON(req.w, http.StatusOK, paginate(req, uploads))
+	case req.match("GET", "uploads", "*"):
+		u, found := sp.uploads[req.segments[1]]
+		if !found {
+			writeError(req.w, http.StatusNotFound, "Not Found")
+			return
+		}
+		writeJSON(req.w, http.StatusOK, &u.Upload)
+	default:
+		writeError(req.w, http.StatusNotFound, "Not Found")
+	}
+}
+
+func (s *Server) createUpload(req *request, sp *space) {
+	file, header, err := req.FormFile("file")
+	if err != nil {
+		writeError(req.w, http.StatusUnprocessableEntity, "file is required")
+		return
+	}
+	defer file.Close()
+
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		writeError(req.w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	format := req.FormValue("file_format")
+	if !supportedFormat(format) {
+		writeError(req.w, http.StatusUnprocessableEntity, fmt.Sprintf("file format %q is not supported", format))
+		return
+	}
+
+	parsed, err := parseFile(format, content)
+	if err != nil {
+		writeError(req.w, http.StatusUnprocessableEntity, fmt.Sprintf("could not parse file: %s", err))
+		return
+	}
+
+	u := &upload{Upload: phraseapp.Upload{
+		ID:        s.newID(),
+		Filename:  header.Filename,
+		Format:    format,
+		State:     "success",
+		CreatedAt: now(),
+		UpdatedAt: now(),
+	}}
+	u.Tag = strings.Replace(header.Filename, ".", "-", -1) + "-" + u.ID[len(u.ID)-8:]
+
+	tags := []string{u.Tag}
+	for _, tag := range strings.Split(req.FormValue("tags"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	updateTranslations := req.FormValue("update_translations") == "true"
+
+	for code, translations := range parsed {
+		var l *locale
+		if code == "" {
+			l = sp.locale(req.FormValue("locale_id"))
+			if l == nil {
+				writeError(req.w, http.StatusUnprocessableEntity, "locale_id is required for this file format")
+				return
+			}
+		} else {
+			l = sp.locale(code)
+			if l == nil {
+				l = s.createLocale(sp, code, code)
+				u.Summary.LocalesCreated++
+			}
+		}
+
+		for name, translation := range translations {
+			k := sp.key(name)
+			if k == nil {
+				k = &key{
+					TranslationKey: phraseapp.TranslationKey{ID: s.newID(), Name: name, DataType: "string", CreatedAt: now(), UpdatedAt: now()},
+					translations:   map[string]string{},
+					uploads:        map[string]bool{},
+				}
+				sp.keys = append(sp.keys, k)
+				u.Summary.TranslationKeysCreated++
+			}
+			k.uploads[u.ID] = true
+			for _, tag := range tags {
+				if !containsString(k.Tags, tag) {
+					k.Tags = append(k.Tags, tag)
+				}
+			}
+
+			existing, found := k.translations[l.ID]
+			switch {
+			case !found:
+				k.translations[l.ID] = translation
+				u.Summary.TranslationsCreated++
+			case existing != translation && updateTranslations:
+				k.translations[l.ID] = translation
+				u.Summary.TranslationsUpdated++
+			}
+		}
+	}
+
+	sp.uploads[u.ID] = u
+	writeJSON(req.w, http.StatusCreated, &u.Upload)
+}
+
+func (s *Server) serveKeys(req *request, sp *space, body map[string]interface{}) {
+	q := req.param("q")
+	if v, ok := body["q"].(string); ok && q == "" {
+		q = v
+	}
+
+	switch {
+	case req.match("GET", "keys"), req.match("POST", "keys", "search"):
+		keys, err := sp.query(q)
+		if err != nil {
+			writeError(req.w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		list := make([]*phraseapp.TranslationKey, 0, len(keys))
+		for _, k := range keys {
+			list = append(list, &k.TranslationKey)
+		}
+		writeJSON(req.w, http.StatusOK, paginate(req, list))
+	case req.match("POST", "keys"):
+		s.createKey(req, sp)
+	case req.match("DELETE", "keys"):
+		keys, err := sp.query(q)
+		if err != nil {
+			writeError(req.w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		sp.deleteKeys(keys)
+		writeJSON(req.w, http.StatusOK, &phraseapp.AffectedResources{RecordsAffected: int64(len(keys))})
+	case req.match("GET", "keys", "*"):
+		k := sp.key(req.segments[1])
+		if k == nil {
+			writeError(req.w, http.StatusNotFound, "Not Found")
+			return
+		}
+		writeJSON(req.w, http.StatusOK, &phraseapp.TranslationKeyDetails{TranslationKey: k.TranslationKey})
+	case req.match("DELETE", "keys", "*"):
+		k := sp.key(req.segments[1])
+		if k == nil {
+			writeError(req.w, http.StatusNotFound, "Not Found")
+			return
+		}
+		sp.deleteKeys([]*key{k})
+		req.w.WriteHeader(http.StatusNoContent)
+	case req.match("GET", "keys", "*", "translations"):
+		k := sp.key(req.segments[1])
+		if k == nil {
+			writeError(req.w, http.StatusNotFound, "Not Found")
+			return
+		}
+		list := []*phraseapp.Translation{}
+		for _, l := range sp.locales {
+			if t, found := k.translations[l.ID]; found {
+				list = append(list, translation(k, l, t))
+			}
+		}
+		writeJSON(req.w, http.StatusOK, paginate(req, list))
+	default:
+		writeError(req.w, http.StatusNotFound, "Not Found")
+	}
+}
+
+func (s *Server) createKey(req *request, sp *space) {
+	name := req.param("name")
+	if name == "" {
+		writeError(req.w, http.StatusUnprocessableEntity, "name is required")
+		return
+	}
+	if sp.key(name) != nil {
+		writeError(req.w, http.StatusUnprocessableEntity, "name has already been taken")
+		return
+	}
+
+	k := &key{
+		TranslationKey: phraseapp.TranslationKey{
+			ID:          s.newID(),
+			Name:        name,
+			Description: req.param("description"),
+			DataType:    "string",
+			Plural:      req.param("plural") == "true",
+			CreatedAt:   now(),
+			UpdatedAt:   now(),
+		},
+		translations: map[string]string{},
+		uploads:      map[string]bool{},
+	}
+	if dataType := req.param("data_type"); dataType != "" {
+		k.DataType = dataType
+	}
+	if tags := req.param("tags"); tags != "" {
+		k.Tags = strings.Split(tags, ",")
+	}
+	sp.keys = append(sp.keys, k)
+	writeJSON(req.w, http.StatusCreated, &phraseapp.TranslationKeyDetails{TranslationKey: k.TranslationKey})
+}
+
+func (s *Server) serveTranslations(req *request, sp *space, body map[string]interface{}) {
+	if req.match("GET", "translations") {
+		list := []*phraseapp.Translation{}
+		for _, k := range sp.keys {
+			for _, l := range sp.locales {
+				if t, found := k.translations[l.ID]; found {
+					list = append(list, translation(k, l, t))
+				}
+			}
+		}
+		writeJSON(req.w, http.StatusOK, paginate(req, list))
+		return
+	}
+	if !req.match("POST", "translations") {
+		writeError(req.w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	keyID, _ := body["key_id"].(string)
+	localeID, _ := body["locale_id"].(string)
+	content, _ := body["content"].(string)
+	k, l := sp.key(keyID), sp.locale(localeID)
+	if k == nil || l == nil {
+		writeError(req.w, http.StatusUnprocessableEntity, "key_id and locale_id must refer to existing records")
+		return
+	}
+
+	k.translations[l.ID] = content
+	writeJSON(req.w, http.StatusCreated, &phraseapp.TranslationDetails{Translation: *translation(k, l, content)})
+}
+
+func translation(k *key, l *locale, content string) *phraseapp.Translation {
+	return &phraseapp.Translation{
+		ID:      k.ID + l.ID,
+		Content: content,
+		Key:     &phraseapp.KeyPreview{ID: k.ID, Name: k.Name, Plural: k.Plural},
+		Locale:  &phraseapp.LocalePreview{ID: l.ID, Name: l.Name, Code: l.Code},
+	}
+}
+
+func (s *Server) serveBranches(req *request, p *project) {
+	var b *branch
+	if len(req.segments) > 1 {
+		b = p.branches[req.segments[1]]
+		if b == nil {
+			writeError(req.w, http.StatusNotFound, "Not Found")
+			return
+		}
+	}
+
+	switch {
+	case req.match("GET", "branches"):
+		branches := []*phraseapp.Branch{}
+		for _, b := range p.branches {
+			branches = append(branches, &b.Branch)
+		}
+		sort.Slice(branches, func(i, j int) bool { return branches[i].Name < branches[j].Name })
+		writeJSON(req.w, http.StatusOK, paginate(req, branches))
+	case req.match("POST", "branches"):
+		params := &phraseapp.BranchParams{}
+		if err := json.NewDecoder(req.Body).Decode(params); err != nil || params.Name == nil || *params.Name == "" {
+			writeError(req.w, http.StatusUnprocessableEntity, "name is required")
+			return
+		}
+		if _, found := p.branches[*params.Name]; found {
+			writeError(req.w, http.StatusUnprocessableEntity, "branch already exists")
+			return
+		}
+
+		b := &branch{Branch: phraseapp.Branch{Name: *params.Name, State: "success", CreatedAt: now(), UpdatedAt: now()}, data: p.main.copy()}
+		p.branches[b.Name] = b
+		writeJSON(req.w, http.StatusCreated, &b.Branch)
+	case req.match("GET", "branches", "*"):
+		writeJSON(req.w, http.StatusOK, &b.Branch)
+	case req.match("PATCH", "branches", "*", "merge"):
+		params := &phraseapp.BranchMergeParams{}
+		json.NewDecoder(req.Body).Decode(params)
+		strategy := "use_branch"
+		if params.Strategy != nil {
+			strategy = *params.Strategy
+		}
+
+		p.main.merge(b.data, strategy)
+		b.State = "merged"
+		b.MergedAt = now()
+		b.UpdatedAt = b.MergedAt
+		writeJSON(req.w, http.StatusOK, &b.Branch)
+	case req.match("DELETE", "branches", "*"):
+		delete(p.branches, b.Name)
+		req.w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(req.w, http.StatusNotFound, "Not Found")
+	}
+}
+
+func (s *Server) newID() string {
+	s.lastID++
+	return fmt.Sprintf("%032x", s.lastID)
+}
+
+// paginate returns the page of a list given by the page and per_page query parameters, like the API does.
+func paginate(req *request, list interface{}) interface{} {
+	page, err := strconv.Atoi(req.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	perPage, err := strconv.Atoi(req.URL.Query().Get("per_page"))
+	if err != nil || perPage < 1 {
+		perPage = 25
+	}
+
+	content, _ := json.Marshal(list)
+	items := []json.RawMessage{}
+	json.Unmarshal(content, &items)
+
+	start := (page - 1) * perPage
+	if start > len(items) {
+		start = len(items)
+	}
+	end := start + perPage
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[start:end]
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
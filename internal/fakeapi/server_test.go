@@ -0,0 +1,217 @@
+package fakeapi
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/phrase/phraseapp-go/phraseapp"
+)
+
+func newTestClient(t *testing.T) (*phraseapp.Client, func()) {
+	srv := httptest.NewServer(New())
+	c, err := phraseapp.NewClient(phraseapp.Credentials{Token: "token", Host: srv.URL}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, srv.Close
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func uploadFile(t *testing.T, c *phraseapp.Client, projectID string, params *phraseapp.UploadParams) *phraseapp.Upload {
+	u, err := c.UploadCreate(projectID, params)
+	if err != nil {
+		t.Fatalf("didn't expect an error on upload, got: %s", err)
+	}
+	if u.State != "success" {
+		t.Fatalf("expected upload to succeed, got state %q", u.State)
+	}
+	return u
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestUploadDownloadRoundTrip(t *testing.T) {
+	c, done := newTestClient(t)
+	defer done()
+
+	dir, err := ioutil.TempDir("", "fakeapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	yml := "en:\n  greeting:\n    hello: Hello\n    bye: Goodbye\n  title: Welcome\n"
+	uploadFile(t, c, "project", &phraseapp.UploadParams{File: strPtr(writeFile(t, dir, "en.yml", yml)), FileFormat: strPtr("yml")})
+
+	locales, err := c.LocalesList("project", 1, 25, &phraseapp.LocalesListParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(locales) != 1 || locales[0].Code != "en" {
+		t.Fatalf("expected locale en to be created by the upload, got %v", locales)
+	}
+
+	content, err := c.LocaleDownload("project", locales[0].ID, &phraseapp.LocaleDownloadParams{FileFormat: strPtr("yml")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "en:\n  greeting:\n    bye: Goodbye\n    hello: Hello\n  title: Welcome\n"; string(content) != expected {
+		t.Errorf("expected download %q, got %q", expected, content)
+	}
+
+	json := `{"greeting": {"hello": "Hallo"}}`
+	de, err := c.LocaleCreate("project", &phraseapp.LocaleParams{Name: strPtr("de"), Code: strPtr("de")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	uploadFile(t, c, "project", &phraseapp.UploadParams{File: strPtr(writeFile(t, dir, "de.json", json)), FileFormat: strPtr("nested_json"), LocaleID: &de.ID})
+
+	content, err = c.LocaleDownload("project", "de", &phraseapp.LocaleDownloadParams{FileFormat: strPtr("simple_json")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "{\n  \"greeting.hello\": \"Hallo\"\n}\n"; string(content) != expected {
+		t.Errorf("expected download %q, got %q", expected, content)
+	}
+}
+
+func TestKeysDeleteUnmentioned(t *testing.T) {
+	c, done := newTestClient(t)
+	defer done()
+
+	dir, err := ioutil.TempDir("", "fakeapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	uploadFile(t, c, "project", &phraseapp.UploadParams{File: strPtr(writeFile(t, dir, "en.yml", "en:\n  a: A\n  b: B\n")), FileFormat: strPtr("yml")})
+	u := uploadFile(t, c, "project", &phraseapp.UploadParams{File: strPtr(writeFile(t, dir, "en.yml", "en:\n  a: A\n")), FileFormat: strPtr("yml")})
+
+	affected, err := c.KeysDelete("project", &phraseapp.KeysDeleteParams{Q: strPtr("unmentioned_in_upload:" + u.ID)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if affected.RecordsAffected != 1 {
+		t.Errorf("expected one key to be deleted, got %d", affected.RecordsAffected)
+	}
+
+	keys, err := c.KeysSearch("project", 1, 25, &phraseapp.KeysSearchParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0].Name != "a" {
+		t.Errorf("expected only key a to remain, got %v", keys)
+	}
+}
+
+func TestBranches(t *testing.T) {
+	c, done := newTestClient(t)
+	defer done()
+
+	dir, err := ioutil.TempDir("", "fakeapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	uploadFile(t, c, "project", &phraseapp.UploadParams{File: strPtr(writeFile(t, dir, "en.yml", "en:\n  a: A\n")), FileFormat: strPtr("yml")})
+
+	if _, err := c.BranchCreate("project", &phraseapp.BranchParams{Name: strPtr("feature")}); err != nil {
+		t.Fatal(err)
+	}
+	uploadFile(t, c, "project", &phraseapp.UploadParams{File: strPtr(writeFile(t, dir, "en.yml", "en:\n  b: B\n")), FileFormat: strPtr("yml"), Branch: strPtr("feature")})
+
+	keys, err := c.KeysList("project", 1, 25, &phraseapp.KeysListParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 {
+		t.Errorf("expected the upload to the branch not to change the main branch, got %d keys", len(keys))
+	}
+
+	if err := c.BranchMerge("project", "feature", &phraseapp.BranchMergeParams{}); err != nil {
+		t.Fatal(err)
+	}
+	keys, err = c.KeysList("project", 1, 25, &phraseapp.KeysListParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 {
+		t.Errorf("expected the merge to add the branch's key, got %d keys", len(keys))
+	}
+
+	branch, err := c.BranchShow("project", "feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if branch.State != "merged" {
+		t.Errorf("expected branch to be merged, got state %q", branch.State)
+	}
+
+	if err := c.BranchDelete("project", "feature"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.LocalesList("project", 1, 25, &phraseapp.LocalesListParams{Branch: strPtr("feature")}); err == nil {
+		t.Errorf("expected the deleted branch not to be found")
+	}
+}
+
+func TestShowUser(t *testing.T) {
+	c, done := newTestClient(t)
+	defer done()
+
+	u, err := c.ShowUser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Username != "fake" {
+		t.Errorf("expected user fake, got %q", u.Username)
+	}
+}
+
+func TestKeyAndTranslationCreate(t *testing.T) {
+	c, done := newTestClient(t)
+	defer done()
+
+	l, err := c.LocaleCreate("project", &phraseapp.LocaleParams{Name: strPtr("en"), Code: strPtr("en")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err := c.KeyCreate("project", &phraseapp.TranslationKeyParams{Name: strPtr("greeting"), Tags: strPtr("web,app")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(k.Tags) != 2 {
+		t.Errorf("expected two tags, got %v", k.Tags)
+	}
+	if _, err := c.TranslationCreate("project", &phraseapp.TranslationParams{KeyID: &k.ID, LocaleID: &l.ID, Content: strPtr("Hello")}); err != nil {
+		t.Fatal(err)
+	}
+
+	translations, err := c.TranslationsByKey("project", k.ID, 1, 25, &phraseapp.TranslationsByKeyParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(translations) != 1 || translations[0].Content != "Hello" || translations[0].Locale.Code != "en" {
+		t.Errorf("expected the created translation, got %v", translations)
+	}
+
+	translations, err = c.TranslationsList("project", 1, 25, &phraseapp.TranslationsListParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(translations) != 1 || translations[0].Key.Name != "greeting" {
+		t.Errorf("expected the created translation in the project list, got %v", translations)
+	}
+}
@@ -0,0 +1,233 @@
+package fakeapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/phrase/phraseapp-go/phraseapp"
+)
+
+// project holds the data of the main branch and of all branches of a project.
+type project struct {
+	main     *space
+	branches map[string]*branch
+}
+
+type branch struct {
+	phraseapp.Branch
+	data *space
+}
+
+// space holds the locales, keys and uploads of a project or one of its branches.
+type space struct {
+	locales []*locale
+	keys    []*key
+	uploads map[string]*upload
+}
+
+type locale struct {
+	phraseapp.Locale
+}
+
+type key struct {
+	phraseapp.TranslationKey
+	translations map[string]string // translations by locale ID
+	uploads      map[string]bool   // IDs of the uploads mentioning the key
+}
+
+type upload struct {
+	phraseapp.Upload
+}
+
+func newSpace() *space {
+	return &space{uploads: map[string]*upload{}}
+}
+
+// copy returns a deep copy, as branches start with the data of the main branch.
+func (sp *space) copy() *space {
+	c := newSpace()
+	for _, l := range sp.locales {
+		copied := *l
+		c.locales = append(c.locales, &copied)
+	}
+	for _, k := range sp.keys {
+		c.keys = append(c.keys, k.copy())
+	}
+	return c
+}
+
+func (k *key) copy() *key {
+	c := &key{TranslationKey: k.TranslationKey, translations: map[string]string{}, uploads: map[string]bool{}}
+	c.Tags = append([]string{}, k.Tags...)
+	for id, t := range k.translations {
+		c.translations[id] = t
+	}
+	return c
+}
+
+// locale returns the locale with the given ID, name or code.
+func (sp *space) locale(idOrName string) *locale {
+	for _, l := range sp.locales {
+		if l.ID == idOrName || l.Name == idOrName || l.Code == idOrName {
+			return l
+		}
+	}
+	return nil
+}
+
+func (sp *space) key(idOrName string) *key {
+	for _, k := range sp.keys {
+		if k.ID == idOrName || k.Name == idOrName {
+			return k
+		}
+	}
+	return nil
+}
+
+// query returns the keys matching a query in the style of the API, e.g. "name:welcome", "tags:web",
+// "unmentioned_in_upload:<id>" or a part of the name. All terms separated by whitespace have to match.
+func (sp *space) query(q string) ([]*key, error) {
+	matching := []*key{}
+	terms := strings.Fields(q)
+
+	for _, k := range sp.keys {
+		matches := true
+		for _, term := range terms {
+			ok, err := k.matches(term)
+			if err != nil {
+				return nil, err
+			}
+			matches = matches && ok
+		}
+		if matches {
+			matching = append(matching, k)
+		}
+	}
+	return matching, nil
+}
+
+func (k *key) matches(term string) (bool, error) {
+	parts := strings.SplitN(term, ":", 2)
+	if len(parts) == 1 {
+		return strings.Contains(k.Name, term), nil
+	}
+
+	switch parts[0] {
+	case "name":
+		return k.Name == parts[1], nil
+	case "ids":
+		for _, id := range strings.Split(parts[1], ",") {
+			if id == k.ID {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "tags":
+		for _, tag := range strings.Split(parts[1], ",") {
+			if containsString(k.Tags, tag) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "unmentioned_in_upload":
+		return !k.uploads[parts[1]], nil
+	case "mentioned_in_upload":
+		return k.uploads[parts[1]], nil
+	}
+	return false, fmt.Errorf("unsupported query %q", term)
+}
+
+func (sp *space) deleteKeys(keys []*key) {
+	deleted := map[*key]bool{}
+	for _, k := range keys {
+		deleted[k] = true
+	}
+
+	remaining := []*key{}
+	for _, k := range sp.keys {
+		if !deleted[k] {
+			remaining = append(remaining, k)
+		}
+	}
+	sp.keys = remaining
+}
+
+// merge applies the changes of a branch to the main branch. Translations differing in both are taken from the
+// branch, unless the strategy is "use_main".
+func (sp *space) merge(from *space, strategy string) {
+	for _, l := range from.locales {
+		if sp.locale(l.Code) == nil {
+			copied := *l
+			sp.locales = append(sp.locales, &copied)
+		}
+	}
+
+	for _, k := range from.keys {
+		target := sp.key(k.Name)
+		if target == nil {
+			target = k.copy()
+			target.translations = map[string]string{}
+			sp.keys = append(sp.keys, target)
+		}
+
+		for localeID, t := range k.translations {
+			fromLocale := localeByID(from.locales, localeID)
+			if fromLocale == nil {
+				continue
+			}
+			targetLocale := sp.locale(fromLocale.Code)
+			if _, found := target.translations[targetLocale.ID]; found && strategy == "use_main" {
+				continue
+			}
+			target.translations[targetLocale.ID] = t
+		}
+	}
+}
+
+func localeByID(locales []*locale, id string) *locale {
+	for _, l := range locales {
+		if l.ID == id {
+			return l
+		}
+	}
+	return nil
+}
+
+// translations returns the translations of a locale by key name, optionally restricted to keys with the given tag.
+func (sp *space) translations(l *locale, tag string) map[string]string {
+	translations := map[string]string{}
+	for _, k := range sp.keys {
+		if tag != "" && !containsString(k.Tags, tag) {
+			continue
+		}
+		if t, found := k.translations[l.ID]; found {
+			translations[k.Name] = t
+		}
+	}
+	return translations
+}
+
+func sortedLocales(locales []*locale) []*phraseapp.Locale {
+	sorted := make([]*phraseapp.Locale, 0, len(locales))
+	for _, l := range locales {
+		sorted = append(sorted, &l.Locale)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func now() *time.Time {
+	t := time.Now().UTC().Truncate(time.Second)
+	return &t
+}
@@ -0,0 +1,160 @@
+// Package interpolation finds the placeholders in translations that are replaced with values at runtime: Rails style
+// %{name}, mustache style {{name}}, printf verbs like %s or %1$d and ICU message arguments like {name} or
+// {count, plural, one {...} other {...}}.
+package interpolation
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Placeholder is a placeholder in a translation.
+type Placeholder struct {
+	// Text is the placeholder as it is written.
+	Text string
+	// Name identifies the placeholder when comparing translations, e.g. %{count}, %1$s or {count, plural}.
+	Name string
+	// Start and End are the byte offsets of Text in the translation.
+	Start, End int
+	// Options are the choices of ICU plural and select arguments.
+	Options []Option
+}
+
+// Option is a choice of an ICU plural or select argument, with the offsets of its message in the translation.
+type Option struct {
+	Selector   string
+	Start, End int
+}
+
+var (
+	printfRegexp    = regexp.MustCompile(`^%(\d+\$)?[-+0#]*(\d+|\*)?(\.\d+)?(hh|h|ll|l|L|q|z|t|j)?[sdiufFeEgGxXoc@]`)
+	icuNameRegexp   = regexp.MustCompile(`^[\w.]+$`)
+	icuOptionRegexp = regexp.MustCompile(`^\s*(offset:\d+\s+)?(=?[\w]+)\s*\{`)
+)
+
+// Find returns the placeholders in s in the order they occur. The messages of ICU plural and select arguments are
+// not searched, use Options to find the placeholders in them.
+func Find(s string) []Placeholder {
+	found := []Placeholder{}
+	for i := 0; i < len(s); i++ {
+		var p *Placeholder
+		switch s[i] {
+		case '%':
+			if strings.HasPrefix(s[i:], "%%") {
+				// an escaped percent sign
+				i++
+				continue
+			}
+			p = findPercent(s, i)
+		case '{':
+			p = findBraces(s, i)
+		}
+		if p != nil {
+			found = append(found, *p)
+			i = p.End - 1
+		}
+	}
+	return found
+}
+
+// Names returns the names of all placeholders in s, including the ones in the messages of ICU arguments.
+func Names(s string) []string {
+	names := []string{}
+	for _, p := range Find(s) {
+		names = append(names, p.Name)
+		for _, o := range p.Options {
+			names = append(names, Names(s[o.Start:o.End])...)
+		}
+	}
+	return names
+}
+
+func findPercent(s string, i int) *Placeholder {
+	rest := s[i:]
+	if strings.HasPrefix(rest, "%{") {
+		end := strings.IndexByte(rest, '}')
+		if end < 0 {
+			return nil
+		}
+		text := rest[:end+1]
+		return &Placeholder{Text: text, Name: "%{" + strings.TrimSpace(text[2:end]) + "}", Start: i, End: i + end + 1}
+	}
+	if m := printfRegexp.FindString(rest); m != "" {
+		return &Placeholder{Text: m, Name: m, Start: i, End: i + len(m)}
+	}
+	return nil
+}
+
+func findBraces(s string, i int) *Placeholder {
+	rest := s[i:]
+	if strings.HasPrefix(rest, "{{") {
+		end := strings.Index(rest, "}}")
+		if end < 0 {
+			return nil
+		}
+		text := rest[:end+2]
+		return &Placeholder{Text: text, Name: "{{" + strings.TrimSpace(text[2:end]) + "}}", Start: i, End: i + end + 2}
+	}
+
+	end := matchingBrace(rest)
+	if end < 0 {
+		return nil
+	}
+	inner := rest[1:end]
+	parts := strings.SplitN(inner, ",", 3)
+	name := strings.TrimSpace(parts[0])
+	if !icuNameRegexp.MatchString(name) {
+		return nil
+	}
+
+	p := &Placeholder{Text: rest[:end+1], Name: "{" + name + "}", Start: i, End: i + end + 1}
+	if len(parts) == 1 {
+		return p
+	}
+	kind := strings.TrimSpace(parts[1])
+	p.Name = "{" + name + ", " + kind + "}"
+	if len(parts) == 3 && (kind == "plural" || kind == "select" || kind == "selectordinal") {
+		p.Options = parseOptions(parts[2], i+1+len(parts[0])+1+len(parts[1])+1)
+	}
+	return p
+}
+
+// parseOptions returns the options of an ICU plural or select argument, s starting at offset in the translation.
+func parseOptions(s string, offset int) []Option {
+	options := []Option{}
+	for pos := 0; pos < len(s); {
+		m := icuOptionRegexp.FindStringSubmatchIndex(s[pos:])
+		if m == nil {
+			break
+		}
+		open := pos + m[1] - 1
+		end := matchingBrace(s[open:])
+		if end < 0 {
+			break
+		}
+		options = append(options, Option{
+			Selector: s[pos+m[4] : pos+m[5]],
+			Start:    offset + open + 1,
+			End:      offset + open + end,
+		})
+		pos = open + end + 1
+	}
+	return options
+}
+
+// matchingBrace returns the index of the brace closing the one s starts with, or -1.
+func matchingBrace(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,44 @@
+package interpolation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNames(t *testing.T) {
+	for s, expected := range map[string][]string{
+		"Hello %{name}!":                 {"%{name}"},
+		"Hello {{ name }} and {{other}}": {"{{name}}", "{{other}}"},
+		"%1$s has %2$d items, %s %.2f":   {"%1$s", "%2$d", "%s", "%.2f"},
+		"100%% sure, 50% off":            {},
+		"Hello {name}":                   {"{name}"},
+		"{count, plural, =0 {no apples} one {# apple from {name}} other {# apples}}": {"{count, plural}", "{name}"},
+		"{gender, select, male {he} other {they}} said {a b}":                        {"{gender, select}"},
+		"unbalanced { brace %{": {},
+	} {
+		if got := Names(s); !reflect.DeepEqual(got, expected) {
+			t.Errorf("%q: expected %v, got %v", s, expected, got)
+		}
+	}
+}
+
+func TestFindOptions(t *testing.T) {
+	s := "You have {count, plural, offset:1 one {# message} other {# messages}}."
+	found := Find(s)
+	if len(found) != 1 {
+		t.Fatalf("expected 1 placeholder, got %d", len(found))
+	}
+
+	p := found[0]
+	if s[p.Start:p.End] != p.Text || p.Text != "{count, plural, offset:1 one {# message} other {# messages}}" {
+		t.Errorf("expected the whole argument as text, got %q", p.Text)
+	}
+	messages := map[string]string{}
+	for _, o := range p.Options {
+		messages[o.Selector] = s[o.Start:o.End]
+	}
+	expected := map[string]string{"one": "# message", "other": "# messages"}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Errorf("expected options %v, got %v", expected, messages)
+	}
+}
@@ -0,0 +1,403 @@
+// Package lineedit implements a small line editor for interactive terminals with a history and tab completion. If
+// the input is no terminal, lines are read as they are, without any editing features.
+package lineedit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// ErrInterrupted is returned by ReadLine if the user pressed Ctrl-C.
+var ErrInterrupted = errors.New("interrupted")
+
+const maxHistory = 1000
+
+// Editor reads lines from the user.
+type Editor struct {
+	// Complete returns the candidates for the word in front of the cursor. It is called with the content of the
+	// line up to the cursor and must return complete words, not only the missing suffixes.
+	Complete func(line string) []string
+
+	in      *os.File
+	out     io.Writer
+	reader  *bufio.Reader
+	history []string
+}
+
+// New returns an editor reading from in and echoing to out.
+func New(in *os.File, out io.Writer) *Editor {
+	return &Editor{
+		in:     in,
+		out:    out,
+		reader: bufio.NewReader(in),
+	}
+}
+
+// ReadLine prints the prompt and returns the line entered. io.EOF is returned if the user pressed Ctrl-D on an empty
+// line or the input was closed.
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	fd := e.in.Fd()
+	if !isTerminal(fd) {
+		return e.readPlain(prompt)
+	}
+
+	state, err := makeRaw(fd)
+	if err != nil {
+		return e.readPlain(prompt)
+	}
+	defer restore(fd, state)
+
+	return e.edit(prompt)
+}
+
+// ReadSecret prints the prompt and returns the line entered without echoing it, e.g. for tokens and passwords. If the
+// input is no terminal, the line is read as it is.
+func (e *Editor) ReadSecret(prompt string) (string, error) {
+	fd := e.in.Fd()
+	if !isTerminal(fd) {
+		return e.readPlain(prompt)
+	}
+
+	state, err := makeRaw(fd)
+	if err != nil {
+		return e.readPlain(prompt)
+	}
+	defer restore(fd, state)
+
+	return e.readHidden(prompt)
+}
+
+func (e *Editor) readHidden(prompt string) (string, error) {
+	fmt.Fprint(e.out, prompt)
+	buf := []rune{}
+	for {
+		r, _, err := e.reader.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		switch r {
+		case keyCR, keyLF:
+			fmt.Fprint(e.out, "\r\n")
+			return string(buf), nil
+		case keyCtrlC:
+			fmt.Fprint(e.out, "\r\n")
+			return "", ErrInterrupted
+		case keyCtrlD:
+			if len(buf) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+		case keyBackspace, keyDelete:
+			if len(buf) > 0 {
+				buf = buf[:len(buf)-1]
+			}
+		case keyCtrlU:
+			buf = buf[:0]
+		default:
+			if unicode.IsPrint(r) {
+				buf = append(buf, r)
+			}
+		}
+	}
+}
+
+// IsTerminal reports whether f is an interactive terminal.
+func IsTerminal(f *os.File) bool {
+	return isTerminal(f.Fd())
+}
+
+// AddHistory appends line to the history, unless it is empty or equals the previous entry.
+func (e *Editor) AddHistory(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if len(e.history) > 0 && e.history[len(e.history)-1] == line {
+		return
+	}
+	e.history = append(e.history, line)
+	if len(e.history) > maxHistory {
+		e.history = e.history[len(e.history)-maxHistory:]
+	}
+}
+
+// History returns the lines entered so far, the oldest first.
+func (e *Editor) History() []string {
+	return e.history
+}
+
+// LoadHistory reads the history from the file at path. A missing file is not an error.
+func (e *Editor) LoadHistory(path string) error {
+	content, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return nil
+	case err != nil:
+		return err
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		e.AddHistory(line)
+	}
+	return nil
+}
+
+// SaveHistory writes the history to the file at path.
+func (e *Editor) SaveHistory(path string) error {
+	content := strings.Join(e.history, "\n") + "\n"
+	return ioutil.WriteFile(path, []byte(content), 0600)
+}
+
+func (e *Editor) readPlain(prompt string) (string, error) {
+	fmt.Fprint(e.out, prompt)
+
+	line, err := e.reader.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyBackspace = 8
+	keyTab       = 9
+	keyLF        = 10
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyCR        = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+// lineState is the state of the line currently edited.
+type lineState struct {
+	prompt  string
+	buf     []rune
+	pos     int
+	histIdx int
+	saved   []rune // line entered before the history was browsed
+}
+
+func (e *Editor) edit(prompt string) (string, error) {
+	ls := &lineState{prompt: prompt, histIdx: len(e.history)}
+	e.refresh(ls)
+
+	for {
+		r, _, err := e.reader.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		switch r {
+		case keyCR, keyLF:
+			fmt.Fprint(e.out, "\r\n")
+			return string(ls.buf), nil
+		case keyCtrlC:
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", ErrInterrupted
+		case keyCtrlD:
+			if len(ls.buf) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			ls.deleteAt(ls.pos)
+		case keyBackspace, keyDelete:
+			if ls.pos > 0 {
+				ls.pos--
+				ls.deleteAt(ls.pos)
+			}
+		case keyCtrlA:
+			ls.pos = 0
+		case keyCtrlE:
+			ls.pos = len(ls.buf)
+		case keyCtrlB:
+			ls.moveBy(-1)
+		case keyCtrlF:
+			ls.moveBy(1)
+		case keyCtrlK:
+			ls.buf = ls.buf[:ls.pos]
+		case keyCtrlU:
+			ls.buf = append([]rune{}, ls.buf[ls.pos:]...)
+			ls.pos = 0
+		case keyCtrlW:
+			end := ls.pos
+			for end > 0 && unicode.IsSpace(ls.buf[end-1]) {
+				end--
+			}
+			start := wordStart(ls.buf[:end])
+			ls.buf = append(ls.buf[:start], ls.buf[ls.pos:]...)
+			ls.pos = start
+		case keyCtrlL:
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+		case keyCtrlP:
+			e.browseHistory(ls, -1)
+		case keyCtrlN:
+			e.browseHistory(ls, 1)
+		case keyTab:
+			e.complete(ls)
+		case keyEscape:
+			if err := e.handleEscapeSequence(ls); err != nil {
+				return "", err
+			}
+		default:
+			if unicode.IsPrint(r) {
+				ls.insert(r)
+			}
+		}
+
+		e.refresh(ls)
+	}
+}
+
+// Handle the escape sequences sent for cursor and editing keys, e.g. "\x1b[A" for the up arrow or "\x1b[3~" for
+// the delete key. Unknown sequences are ignored.
+func (e *Editor) handleEscapeSequence(ls *lineState) error {
+	r, _, err := e.reader.ReadRune()
+	if err != nil {
+		return err
+	}
+	if r != '[' && r != 'O' {
+		return nil
+	}
+
+	param := ""
+	for {
+		r, _, err = e.reader.ReadRune()
+		if err != nil {
+			return err
+		}
+		if r < '0' || r > '9' {
+			break
+		}
+		param += string(r)
+	}
+
+	switch {
+	case r == 'A':
+		e.browseHistory(ls, -1)
+	case r == 'B':
+		e.browseHistory(ls, 1)
+	case r == 'C':
+		ls.moveBy(1)
+	case r == 'D':
+		ls.moveBy(-1)
+	case r == 'H', r == '~' && (param == "1" || param == "7"):
+		ls.pos = 0
+	case r == 'F', r == '~' && (param == "4" || param == "8"):
+		ls.pos = len(ls.buf)
+	case r == '~' && param == "3":
+		ls.deleteAt(ls.pos)
+	}
+	return nil
+}
+
+func (e *Editor) refresh(ls *lineState) {
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K", ls.prompt, string(ls.buf))
+	if back := len(ls.buf) - ls.pos; back > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", back)
+	}
+}
+
+func (e *Editor) browseHistory(ls *lineState, direction int) {
+	idx := ls.histIdx + direction
+	if idx < 0 || idx > len(e.history) {
+		return
+	}
+
+	if ls.histIdx == len(e.history) {
+		ls.saved = ls.buf
+	}
+	ls.histIdx = idx
+
+	if idx == len(e.history) {
+		ls.buf = ls.saved
+	} else {
+		ls.buf = []rune(e.history[idx])
+	}
+	ls.pos = len(ls.buf)
+}
+
+func (e *Editor) complete(ls *lineState) {
+	if e.Complete == nil {
+		return
+	}
+
+	candidates := e.Complete(string(ls.buf[:ls.pos]))
+	if len(candidates) == 0 {
+		fmt.Fprint(e.out, "\a")
+		return
+	}
+
+	start := wordStart(ls.buf[:ls.pos])
+	word := string(ls.buf[start:ls.pos])
+
+	replacement := commonPrefix(candidates)
+	if len(candidates) == 1 {
+		replacement += " "
+	}
+
+	if len(replacement) > len(word) {
+		tail := append([]rune(replacement), ls.buf[ls.pos:]...)
+		ls.buf = append(ls.buf[:start], tail...)
+		ls.pos = start + len([]rune(replacement))
+		return
+	}
+
+	fmt.Fprintf(e.out, "\r\n%s\r\n", strings.Join(candidates, "  "))
+}
+
+func (ls *lineState) insert(r rune) {
+	ls.buf = append(ls.buf, 0)
+	copy(ls.buf[ls.pos+1:], ls.buf[ls.pos:])
+	ls.buf[ls.pos] = r
+	ls.pos++
+}
+
+func (ls *lineState) deleteAt(pos int) {
+	if pos < len(ls.buf) {
+		ls.buf = append(ls.buf[:pos], ls.buf[pos+1:]...)
+	}
+}
+
+func (ls *lineState) moveBy(offset int) {
+	pos := ls.pos + offset
+	if pos >= 0 && pos <= len(ls.buf) {
+		ls.pos = pos
+	}
+}
+
+// wordStart returns the index of the first rune of the last word in buf. If buf ends with whitespace, that is
+// the length of buf.
+func wordStart(buf []rune) int {
+	i := len(buf)
+	for i > 0 && !unicode.IsSpace(buf[i-1]) {
+		i--
+	}
+	return i
+}
+
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, w := range words[1:] {
+		for !strings.HasPrefix(w, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
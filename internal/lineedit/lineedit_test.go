@@ -0,0 +1,127 @@
+package lineedit
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func newTestEditor(input string) *Editor {
+	return &Editor{
+		out:    ioutil.Discard,
+		reader: bufio.NewReader(strings.NewReader(input)),
+	}
+}
+
+func TestEdit(t *testing.T) {
+	tests := map[string]string{
+		"abc\r":                  "abc",
+		"abd\x7fc\r":             "abc",
+		"bc\x01a\r":              "abc",
+		"ac\x1b[Db\r":            "abc",
+		"abcd\x1b[D\x1b[3~\r":    "abc",
+		"abc def\x17\x17xyz\r":   "xyz",
+		"xyz\x15abc\n":           "abc",
+		"abcdef\x01\x1b[C\x0b\r": "a",
+	}
+
+	for input, expected := range tests {
+		line, err := newTestEditor(input).edit("> ")
+		if err != nil {
+			t.Errorf("%q: didn't expect an error, got: %s", input, err)
+			continue
+		}
+		if line != expected {
+			t.Errorf("%q: expected line %q, got %q", input, expected, line)
+		}
+	}
+}
+
+func TestEditControlKeys(t *testing.T) {
+	if _, err := newTestEditor("\x04").edit("> "); err != io.EOF {
+		t.Errorf("expected EOF on Ctrl-D, got: %v", err)
+	}
+	if _, err := newTestEditor("abc\x03").edit("> "); err != ErrInterrupted {
+		t.Errorf("expected interruption on Ctrl-C, got: %v", err)
+	}
+}
+
+func TestEditHistory(t *testing.T) {
+	e := newTestEditor("\x1b[A\x1b[A\r" + "new\x1b[A\x1b[B\r")
+	e.AddHistory("first")
+	e.AddHistory("second")
+	e.AddHistory("second")
+
+	if len(e.History()) != 2 {
+		t.Errorf("expected duplicate entries to be skipped, got history %q", e.History())
+	}
+
+	line, err := e.edit("> ")
+	if err != nil || line != "first" {
+		t.Errorf("expected line %q, got %q (%v)", "first", line, err)
+	}
+
+	line, err = e.edit("> ")
+	if err != nil || line != "new" {
+		t.Errorf("expected line %q, got %q (%v)", "new", line, err)
+	}
+}
+
+func TestEditCompletion(t *testing.T) {
+	complete := func(line string) []string {
+		candidates := []string{}
+		word := line[strings.LastIndex(line, " ")+1:]
+		for _, c := range []string{"keys", "key", "locales"} {
+			if strings.HasPrefix(c, word) {
+				candidates = append(candidates, c)
+			}
+		}
+		return candidates
+	}
+
+	tests := map[string]string{
+		"lo\t\r":      "locales ",
+		"k\ts\r":      "keys",
+		"keys lo\t\r": "keys locales ",
+		"x\t\r":       "x",
+	}
+
+	for input, expected := range tests {
+		e := newTestEditor(input)
+		e.Complete = complete
+		line, err := e.edit("> ")
+		if err != nil {
+			t.Errorf("%q: didn't expect an error, got: %s", input, err)
+			continue
+		}
+		if line != expected {
+			t.Errorf("%q: expected line %q, got %q", input, expected, line)
+		}
+	}
+}
+
+func TestReadHidden(t *testing.T) {
+	tests := map[string]string{
+		"secret\r":          "secret",
+		"secrex\x7ft\r":     "secret",
+		"wrong\x15secret\n": "secret",
+	}
+	for input, exp := range tests {
+		got, err := newTestEditor(input).readHidden("")
+		if err != nil {
+			t.Errorf("%q: didn't expect an error, got: %s", input, err)
+		}
+		if got != exp {
+			t.Errorf("%q: expected %q, got %q", input, exp, got)
+		}
+	}
+
+	if _, err := newTestEditor("\x03").readHidden(""); err != ErrInterrupted {
+		t.Errorf("expected Ctrl-C to interrupt, got %v", err)
+	}
+	if _, err := newTestEditor("\x04").readHidden(""); err != io.EOF {
+		t.Errorf("expected Ctrl-D on an empty line to return io.EOF, got %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !linux && !darwin && !windows
+// +build !linux,!darwin,!windows
+
+package lineedit
+
+import "errors"
+
+type termState struct{}
+
+func isTerminal(fd uintptr) bool {
+	return false
+}
+
+func makeRaw(fd uintptr) (*termState, error) {
+	return nil, errors.New("raw terminal mode not supported on this platform")
+}
+
+func restore(fd uintptr, state *termState) error {
+	return nil
+}
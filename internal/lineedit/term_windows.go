@@ -0,0 +1,63 @@
+//go:build windows
+// +build windows
+
+package lineedit
+
+import "syscall"
+
+// Console modes, see https://docs.microsoft.com/en-us/windows/console/setconsolemode
+const (
+	enableProcessedInput            = 0x0001
+	enableLineInput                 = 0x0002
+	enableEchoInput                 = 0x0004
+	enableVirtualTerminalInput      = 0x0200
+	enableVirtualTerminalProcessing = 0x0004
+)
+
+var procSetConsoleMode = syscall.NewLazyDLL("kernel32.dll").NewProc("SetConsoleMode")
+
+type termState struct {
+	inMode, outMode uint32
+	outSet          bool
+}
+
+func setConsoleMode(handle syscall.Handle, mode uint32) error {
+	if r, _, err := procSetConsoleMode.Call(uintptr(handle), uintptr(mode)); r == 0 {
+		return err
+	}
+	return nil
+}
+
+func isTerminal(fd uintptr) bool {
+	var mode uint32
+	return syscall.GetConsoleMode(syscall.Handle(fd), &mode) == nil
+}
+
+// makeRaw disables line buffering, echoing and the handling of Ctrl-C by the console. Keys like the arrows are sent
+// as the escape sequences of unix terminals, and the escape sequences the editor writes to the console output are
+// interpreted, where the console supports it.
+func makeRaw(fd uintptr) (*termState, error) {
+	state := &termState{}
+	if err := syscall.GetConsoleMode(syscall.Handle(fd), &state.inMode); err != nil {
+		return nil, err
+	}
+
+	mode := state.inMode &^ (enableProcessedInput | enableLineInput | enableEchoInput)
+	if err := setConsoleMode(syscall.Handle(fd), mode|enableVirtualTerminalInput); err != nil {
+		if err := setConsoleMode(syscall.Handle(fd), mode); err != nil {
+			return nil, err
+		}
+	}
+
+	if syscall.GetConsoleMode(syscall.Stdout, &state.outMode) == nil {
+		state.outSet = setConsoleMode(syscall.Stdout, state.outMode|enableVirtualTerminalProcessing) == nil
+	}
+	return state, nil
+}
+
+func restore(fd uintptr, state *termState) error {
+	if state.outSet {
+		setConsoleMode(syscall.Stdout, state.outMode)
+	}
+	return setConsoleMode(syscall.Handle(fd), state.inMode)
+}
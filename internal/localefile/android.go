@@ -0,0 +1,232 @@
+package localefile
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// androidXML is the format of Android string resources, with string, plurals and string-array elements. The items of
+// string arrays become keys like name[0].
+type androidXML struct{}
+
+type androidItem struct {
+	Quantity string `xml:"quantity,attr"`
+	Inner    string `xml:",innerxml"`
+}
+
+func (androidXML) Parse(content []byte) (*File, error) {
+	f := &File{}
+	comments := []string{}
+	dec := xml.NewDecoder(bytes.NewReader(content))
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return f, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.Comment:
+			comments = append(comments, strings.TrimSpace(string(t)))
+			continue
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) == 0 {
+				continue
+			}
+		case xml.StartElement:
+			if t.Name.Local == "resources" {
+				continue
+			}
+
+			entries, err := parseAndroidElement(dec, t)
+			if err != nil {
+				return nil, err
+			}
+			if len(entries) > 0 {
+				entries[0].Comment = strings.Join(comments, "\n")
+			}
+			f.Entries = append(f.Entries, entries...)
+		}
+		comments = comments[:0]
+	}
+}
+
+func parseAndroidElement(dec *xml.Decoder, start xml.StartElement) ([]*Entry, error) {
+	e := &Entry{}
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "name" {
+			e.Key = attr.Value
+		} else {
+			e.setAttribute(attr.Name.Local, attr.Value)
+		}
+	}
+
+	switch start.Name.Local {
+	case "string":
+		s := struct {
+			Inner string `xml:",innerxml"`
+		}{}
+		if err := dec.DecodeElement(&s, &start); err != nil {
+			return nil, err
+		}
+		e.Value = androidText(s.Inner)
+		return []*Entry{e}, nil
+	case "plurals", "string-array":
+		s := struct {
+			Items []androidItem `xml:"item"`
+		}{}
+		if err := dec.DecodeElement(&s, &start); err != nil {
+			return nil, err
+		}
+		if start.Name.Local == "string-array" {
+			entries := make([]*Entry, 0, len(s.Items))
+			for i, item := range s.Items {
+				entries = append(entries, &Entry{Key: fmt.Sprintf("%s[%d]", e.Key, i), Value: androidText(item.Inner)})
+			}
+			return entries, nil
+		}
+
+		e.Plural = map[string]string{}
+		for _, item := range s.Items {
+			e.Plural[item.Quantity] = androidText(item.Inner)
+		}
+		return []*Entry{e}, nil
+	}
+	return nil, dec.Skip()
+}
+
+// androidText returns the text of a resource. Text with markup like <b> is kept as it is.
+func androidText(inner string) string {
+	if hasMarkup(inner) {
+		return inner
+	}
+	s := html.UnescapeString(inner)
+	if len(s) >= 2 && strings.HasPrefix(s, `"`) && strings.HasSuffix(s, `"`) {
+		s = s[1 : len(s)-1]
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'u':
+			if i+5 <= len(s) {
+				if u, err := strconv.ParseUint(s[i+1:i+5], 16, 16); err == nil {
+					b.WriteRune(rune(u))
+					i += 4
+					continue
+				}
+			}
+			b.WriteString(`\u`)
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+var markupRegexp = regexp.MustCompile(`<[A-Za-z/!?]`)
+
+// hasMarkup reports whether s contains XML elements, and is well-formed.
+func hasMarkup(s string) bool {
+	if !markupRegexp.MatchString(s) {
+		return false
+	}
+	dec := xml.NewDecoder(strings.NewReader("<x>" + s + "</x>"))
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			return true
+		} else if err != nil {
+			return false
+		}
+	}
+}
+
+var androidEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func escapeAndroid(s string) string {
+	if hasMarkup(s) {
+		return s
+	}
+	s = androidEscaper.Replace(s)
+	if strings.HasPrefix(s, "@") || strings.HasPrefix(s, "?") {
+		s = `\` + s
+	}
+	return s
+}
+
+func (androidXML) Write(f *File) ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	buf.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<resources>\n")
+
+	for i := 0; i < len(f.Entries); i++ {
+		e := f.Entries[i]
+		if e.Comment != "" {
+			fmt.Fprintf(buf, "  <!-- %s -->\n", strings.Replace(e.Comment, "--", "- -", -1))
+		}
+
+		if m := listElementRegexp.FindStringSubmatch(e.Key); m != nil && e.Plural == nil {
+			fmt.Fprintf(buf, "  <string-array name=%s>\n", xmlAttr(m[1]))
+			for ; i < len(f.Entries); i++ {
+				item := listElementRegexp.FindStringSubmatch(f.Entries[i].Key)
+				if item == nil || item[1] != m[1] || f.Entries[i].Plural != nil {
+					break
+				}
+				fmt.Fprintf(buf, "    <item>%s</item>\n", escapeAndroid(f.Entries[i].Value))
+			}
+			i--
+			buf.WriteString("  </string-array>\n")
+			continue
+		}
+
+		attrs := xmlAttrs(e.Attributes)
+		if e.Plural == nil {
+			fmt.Fprintf(buf, "  <string name=%s%s>%s</string>\n", xmlAttr(e.Key), attrs, escapeAndroid(e.Value))
+			continue
+		}
+		fmt.Fprintf(buf, "  <plurals name=%s%s>\n", xmlAttr(e.Key), attrs)
+		for _, form := range e.PluralForms() {
+			fmt.Fprintf(buf, "    <item quantity=%s>%s</item>\n", xmlAttr(form), escapeAndroid(e.Plural[form]))
+		}
+		buf.WriteString("  </plurals>\n")
+	}
+
+	buf.WriteString("</resources>\n")
+	return buf.Bytes(), nil
+}
+
+// xmlText returns s escaped as XML character data.
+func xmlText(s string) string {
+	buf := bytes.NewBuffer(nil)
+	xml.EscapeText(buf, []byte(s))
+	return buf.String()
+}
+
+// xmlAttr returns s quoted as an XML attribute value.
+func xmlAttr(s string) string {
+	return `"` + xmlText(s) + `"`
+}
+
+func xmlAttrs(attributes map[string]string) string {
+	s := ""
+	for _, name := range sortedAttributes(attributes) {
+		s += " " + name + "=" + xmlAttr(attributes[name])
+	}
+	return s
+}
@@ -0,0 +1,270 @@
+package localefile
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// gettext is the format of PO files. The msgid is the key, prefixed with the msgctxt and an EOT byte like gettext
+// does internally if there is a context, and the source text. The indices of the msgstr lines of plurals are used as plural forms, as
+// their meaning depends on the Plural-Forms header.
+type gettext struct{}
+
+// contextSeparator separates the msgctxt from the msgid in keys.
+const contextSeparator = "\x04"
+
+// poComments are the kinds of comments besides translator comments, by their marker.
+var poComments = map[string]string{"#.": "extracted", "#:": "references", "#,": "flags", "#|": "previous"}
+
+var poFieldRegexp = regexp.MustCompile(`^(msgctxt|msgid|msgid_plural|msgstr|msgstr\[(\d+)\])\s+(".*")$`)
+
+type poEntry struct {
+	entry    *Entry
+	fields   map[string]string
+	last     string // field continued by lines with a string only
+	comments []string
+	started  bool
+}
+
+func (gettext) Parse(content []byte) (*File, error) {
+	f := &File{}
+	current := newPOEntry()
+
+	finish := func() error {
+		if !current.started {
+			current = newPOEntry()
+			return nil
+		}
+		if err := current.finish(f); err != nil {
+			return err
+		}
+		current = newPOEntry()
+		return nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			if err := finish(); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(line, "#~"):
+			// obsolete entries are dropped
+		case strings.HasPrefix(line, "#"):
+			if current.started {
+				if err := finish(); err != nil {
+					return nil, err
+				}
+			}
+			current.addComment(line)
+		case strings.HasPrefix(line, `"`):
+			if current.last == "" {
+				return nil, fmt.Errorf("line %d: string without a field", lineNumber)
+			}
+			s, err := strconv.Unquote(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", lineNumber, err)
+			}
+			current.fields[current.last] += s
+		default:
+			m := poFieldRegexp.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("line %d: unexpected %q", lineNumber, line)
+			}
+			if (m[1] == "msgid" || m[1] == "msgctxt") && current.hasField("msgstr") {
+				if err := finish(); err != nil {
+					return nil, err
+				}
+			}
+			s, err := strconv.Unquote(m[3])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", lineNumber, err)
+			}
+			current.started = true
+			current.last = m[1]
+			current.fields[m[1]] = s
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := finish(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func newPOEntry() *poEntry {
+	return &poEntry{entry: &Entry{}, fields: map[string]string{}}
+}
+
+func (p *poEntry) hasField(prefix string) bool {
+	for name := range p.fields {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *poEntry) addComment(line string) {
+	if len(line) >= 2 {
+		if kind, found := poComments[line[:2]]; found {
+			value := strings.TrimSpace(line[2:])
+			if previous := p.entry.attribute(kind); previous != "" {
+				value = previous + "\n" + value
+			}
+			p.entry.setAttribute(kind, value)
+			return
+		}
+	}
+	p.comments = append(p.comments, strings.TrimSpace(strings.TrimPrefix(line, "#")))
+}
+
+func (p *poEntry) finish(f *File) error {
+	msgid, found := p.fields["msgid"]
+	if !found {
+		return fmt.Errorf("entry without msgid")
+	}
+	e := p.entry
+	e.Comment = strings.Join(p.comments, "\n")
+
+	if msgid == "" {
+		// the header
+		header := p.fields["msgstr"]
+		f.setAttribute("header", header)
+		if e.Comment != "" {
+			f.setAttribute("header_comment", e.Comment)
+		}
+		f.Locale = poHeaderValue(header, "Language")
+		return nil
+	}
+
+	e.Key = msgid
+	e.setAttribute("source", msgid)
+	if ctxt, found := p.fields["msgctxt"]; found {
+		e.Key = ctxt + contextSeparator + msgid
+	}
+
+	if plural, found := p.fields["msgid_plural"]; found {
+		e.setAttribute("msgid_plural", plural)
+		e.Plural = map[string]string{}
+		for name, value := range p.fields {
+			if strings.HasPrefix(name, "msgstr[") {
+				e.Plural[strings.TrimSuffix(strings.TrimPrefix(name, "msgstr["), "]")] = value
+			}
+		}
+	} else {
+		e.Value = p.fields["msgstr"]
+	}
+	f.Entries = append(f.Entries, e)
+	return nil
+}
+
+func poHeaderValue(header, name string) string {
+	for _, line := range strings.Split(header, "\n") {
+		if i := strings.Index(line, ":"); i >= 0 && strings.TrimSpace(line[:i]) == name {
+			return strings.TrimSpace(line[i+1:])
+		}
+	}
+	return ""
+}
+
+func setPOHeaderValue(header, name, value string) string {
+	lines := strings.SplitAfter(header, "\n")
+	for i, line := range lines {
+		if j := strings.Index(line, ":"); j >= 0 && strings.TrimSpace(line[:j]) == name {
+			lines[i] = name + ": " + value + "\n"
+			return strings.Join(lines, "")
+		}
+	}
+	return name + ": " + value + "\n" + header
+}
+
+func (gettext) Write(f *File) ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+
+	header := f.attribute("header")
+	if header == "" {
+		header = "MIME-Version: 1.0\nContent-Type: text/plain; charset=UTF-8\nContent-Transfer-Encoding: 8bit\n"
+	}
+	if f.Locale != "" && poHeaderValue(header, "Language") != f.Locale {
+		header = setPOHeaderValue(header, "Language", f.Locale)
+	}
+	writePOComments(buf, "#", f.attribute("header_comment"))
+	buf.WriteString("msgid \"\"\n")
+	writePOString(buf, "msgstr", header)
+
+	for _, e := range f.Entries {
+		buf.WriteString("\n")
+		writePOComments(buf, "#", e.Comment)
+		for _, marker := range []string{"#.", "#:", "#,", "#|"} {
+			writePOComments(buf, marker, e.attribute(poComments[marker]))
+		}
+
+		msgid := e.Key
+		if i := strings.Index(e.Key, contextSeparator); i >= 0 {
+			writePOString(buf, "msgctxt", e.Key[:i])
+			msgid = e.Key[i+len(contextSeparator):]
+		}
+		writePOString(buf, "msgid", msgid)
+
+		if e.Plural == nil {
+			writePOString(buf, "msgstr", e.Value)
+			continue
+		}
+		plural := e.attribute("msgid_plural")
+		if plural == "" {
+			plural = msgid
+		}
+		writePOString(buf, "msgid_plural", plural)
+		forms := e.PluralForms()
+		for i, form := range forms {
+			if _, err := strconv.Atoi(form); err != nil {
+				// CLDR forms of other formats are numbered in their order
+				form = strconv.Itoa(i)
+			}
+			writePOString(buf, "msgstr["+form+"]", e.Plural[forms[i]])
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func writePOComments(buf *bytes.Buffer, marker, comment string) {
+	if comment == "" {
+		return
+	}
+	for _, line := range strings.Split(comment, "\n") {
+		fmt.Fprintf(buf, "%s %s\n", marker, line)
+	}
+}
+
+// writePOString writes a field, with strings spanning multiple lines split after their line breaks.
+func writePOString(buf *bytes.Buffer, field, s string) {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) <= 1 {
+		fmt.Fprintf(buf, "%s %s\n", field, quotePO(s))
+		return
+	}
+	fmt.Fprintf(buf, "%s \"\"\n", field)
+	for _, line := range lines {
+		fmt.Fprintf(buf, "%s\n", quotePO(line))
+	}
+}
+
+var poEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+
+func quotePO(s string) string {
+	return `"` + poEscaper.Replace(s) + `"`
+}
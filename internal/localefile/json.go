@@ -0,0 +1,136 @@
+package localefile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// jsonFormat is the format of simple_json files with flat keys and of nested_json files with keys nested by their dots.
+// Both read nested objects, objects with only plural forms as keys are pluralized keys.
+type jsonFormat struct {
+	nested bool
+}
+
+func (jsonFormat) Parse(content []byte) (*File, error) {
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.UseNumber()
+	v, err := decodeJSON(dec)
+	if err != nil {
+		return nil, err
+	}
+	members, ok := v.([]member)
+	if !ok {
+		return nil, errors.New("expected a JSON object")
+	}
+
+	f := &File{}
+	flattenNested(f, nil, "", members, nil)
+	return f, nil
+}
+
+// decodeJSON decodes the next value, keeping the order of the keys of objects.
+func decodeJSON(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		members := []member{}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := keyTok.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected an object key, got %v", keyTok)
+			}
+			value, err := decodeJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, member{key: key, value: value})
+		}
+		_, err := dec.Token()
+		return members, err
+	case json.Delim('['):
+		items := []interface{}{}
+		for dec.More() {
+			item, err := decodeJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		_, err := dec.Token()
+		return items, err
+	}
+	return tok, nil
+}
+
+func (format jsonFormat) Write(f *File) ([]byte, error) {
+	var content interface{}
+	if format.nested {
+		root, err := buildTree(f.Entries)
+		if err != nil {
+			return nil, err
+		}
+		content = root.value()
+	} else {
+		members := make([]member, 0, len(f.Entries))
+		for _, e := range f.Entries {
+			var value interface{} = e.Value
+			if e.Plural != nil {
+				value = pluralMembers(e)
+			}
+			members = append(members, member{key: e.Key, value: value})
+		}
+		content = members
+	}
+
+	buf := bytes.NewBuffer(nil)
+	writeJSON(buf, content, "")
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+func writeJSON(buf *bytes.Buffer, v interface{}, indent string) {
+	switch v := v.(type) {
+	case []member:
+		if len(v) == 0 {
+			buf.WriteString("{}")
+			return
+		}
+		buf.WriteString("{\n")
+		for i, m := range v {
+			buf.WriteString(indent + "  " + jsonString(m.key) + ": ")
+			writeJSON(buf, m.value, indent+"  ")
+			if i < len(v)-1 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString(indent + "}")
+	case []interface{}:
+		if len(v) == 0 {
+			buf.WriteString("[]")
+			return
+		}
+		buf.WriteString("[\n")
+		for i, item := range v {
+			buf.WriteString(indent + "  ")
+			writeJSON(buf, item, indent+"  ")
+			if i < len(v)-1 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString(indent + "]")
+	case string:
+		buf.WriteString(jsonString(v))
+	}
+}
@@ -0,0 +1,159 @@
+// Package localefile parses and writes locale files of the common PhraseApp formats. All formats share one model: a
+// file is a list of keys with their translations, plural forms and comments. Formats are looked up by the API names
+// PhraseApp uses for them, e.g. yml or nested_json.
+package localefile
+
+import (
+	"fmt"
+	"sort"
+)
+
+// File is the content of a locale file.
+type File struct {
+	// Locale is the code of the locale, for formats including it, e.g. the root key of Rails YAML files.
+	Locale  string
+	Entries []*Entry
+	// Attributes are details of the format kept for writing the file again, e.g. the header of gettext files.
+	Attributes map[string]string
+}
+
+// Entry is a key with its translation.
+type Entry struct {
+	Key   string
+	Value string
+	// Plural holds the translations of pluralized keys by plural form, nil for other keys. The forms are the CLDR
+	// plural categories, except for gettext, which keeps the indices of its msgstr lines. ConvertPlurals maps them.
+	Plural  map[string]string
+	Comment string
+	// Attributes are details of the format kept for writing the file again, e.g. the source text of XLIFF files.
+	Attributes map[string]string
+}
+
+// Format parses and writes the locale files of one file format.
+type Format interface {
+	Parse(content []byte) (*File, error)
+	Write(f *File) ([]byte, error)
+}
+
+// formats are the supported formats by their API name.
+var formats = map[string]Format{
+	"yml":         railsYAML{},
+	"simple_json": jsonFormat{nested: false},
+	"nested_json": jsonFormat{nested: true},
+	"properties":  properties{},
+	"gettext":     gettext{},
+	"strings":     appleStrings{},
+	"xml":         androidXML{},
+	"xlf":         xliff{},
+}
+
+// Lookup returns the format with the given API name.
+func Lookup(apiName string) (Format, error) {
+	format, found := formats[apiName]
+	if !found {
+		return nil, fmt.Errorf("format %q is not supported, use one of %v", apiName, APINames())
+	}
+	return format, nil
+}
+
+// Supported reports whether files of the format with the given API name can be parsed and written.
+func Supported(apiName string) bool {
+	_, found := formats[apiName]
+	return found
+}
+
+// APINames returns the API names of all supported formats.
+func APINames() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Parse parses a locale file of the format with the given API name.
+func Parse(apiName string, content []byte) (*File, error) {
+	format, err := Lookup(apiName)
+	if err != nil {
+		return nil, err
+	}
+	return format.Parse(content)
+}
+
+// Write returns the content of a locale file of the format with the given API name.
+func Write(apiName string, f *File) ([]byte, error) {
+	format, err := Lookup(apiName)
+	if err != nil {
+		return nil, err
+	}
+	return format.Write(f)
+}
+
+// Entry returns the entry with the given key, or nil.
+func (f *File) Entry(key string) *Entry {
+	for _, e := range f.Entries {
+		if e.Key == key {
+			return e
+		}
+	}
+	return nil
+}
+
+// Translations returns the translations by key. The forms of pluralized keys are returned as <key>.<form>.
+func (f *File) Translations() map[string]string {
+	translations := map[string]string{}
+	for _, e := range f.Entries {
+		if e.Plural == nil {
+			translations[e.Key] = e.Value
+			continue
+		}
+		for form, value := range e.Plural {
+			translations[e.Key+"."+form] = value
+		}
+	}
+	return translations
+}
+
+// PluralForms returns the plural forms of the entry in their usual order.
+func (e *Entry) PluralForms() []string {
+	forms := make([]string, 0, len(e.Plural))
+	for form := range e.Plural {
+		forms = append(forms, form)
+	}
+	sort.Slice(forms, func(i, j int) bool { return lessPluralForm(forms[i], forms[j]) })
+	return forms
+}
+
+func (e *Entry) attribute(name string) string {
+	return e.Attributes[name]
+}
+
+func (e *Entry) setAttribute(name, value string) {
+	if value == "" {
+		return
+	}
+	if e.Attributes == nil {
+		e.Attributes = map[string]string{}
+	}
+	e.Attributes[name] = value
+}
+
+func (f *File) attribute(name string) string {
+	return f.Attributes[name]
+}
+
+func (f *File) setAttribute(name, value string) {
+	if value == "" {
+		return
+	}
+	if f.Attributes == nil {
+		f.Attributes = map[string]string{}
+	}
+	f.Attributes[name] = value
+}
+
+// errPlurals is returned by the formats without plurals when writing pluralized keys.
+func errPlurals(format string, e *Entry) error {
+	return fmt.Errorf("%s files can't contain plurals, key %q is pluralized", format, e.Key)
+}
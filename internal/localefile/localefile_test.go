@@ -0,0 +1,438 @@
+package localefile
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var parseTests = []struct {
+	format   string
+	content  string
+	locale   string
+	expected map[string]string
+	comments map[string]string
+}{
+	{
+		format: "yml",
+		content: `en:
+  # the greeting
+  hello: "Hello"
+  nested:
+    key: 'It''s'
+  apples:
+    one: one apple
+    other: "%{count} apples"
+  list:
+    - first
+    - second
+`,
+		locale: "en",
+		expected: map[string]string{
+			"hello": "Hello", "nested.key": "It's", "apples.one": "one apple", "apples.other": "%{count} apples",
+			"list[0]": "first", "list[1]": "second",
+		},
+		comments: map[string]string{"hello": "the greeting"},
+	},
+	{
+		format:   "simple_json",
+		content:  `{"hello": "Hello", "nested.key": "Nested \"quotes\"", "apples": {"one": "an apple", "other": "apples"}}`,
+		expected: map[string]string{"hello": "Hello", "nested.key": `Nested "quotes"`, "apples.one": "an apple", "apples.other": "apples"},
+	},
+	{
+		format:   "nested_json",
+		content:  `{"hello": "Hello", "nested": {"key": "<b>html</b> & more"}, "list": ["a", "b"], "count": 3}`,
+		expected: map[string]string{"hello": "Hello", "nested.key": "<b>html</b> & more", "list[0]": "a", "list[1]": "b", "count": "3"},
+	},
+	{
+		format: "properties",
+		content: `# the greeting
+hello = Hello
+multi.line=first \
+    second
+escaped\ key:café\n
+
+! ignored, followed by a blank line
+
+colon: a:b
+`,
+		expected: map[string]string{"hello": "Hello", "multi.line": "first second", "escaped key": "café\n", "colon": "a:b"},
+		comments: map[string]string{"hello": "the greeting"},
+	},
+	{
+		format: "gettext",
+		content: `# header comment
+msgid ""
+msgstr ""
+"Language: de\n"
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+# the greeting
+#: main.c:12
+msgid "Hello"
+msgstr "Hallo"
+
+msgctxt "menu"
+msgid "Open"
+msgstr ""
+"Öffnen\n"
+"mehr"
+
+msgid "apple"
+msgid_plural "apples"
+msgstr[0] "Apfel"
+msgstr[1] "Äpfel"
+
+#~ msgid "old"
+#~ msgstr "alt"
+`,
+		locale:   "de",
+		expected: map[string]string{"Hello": "Hallo", "menu\x04Open": "Öffnen\nmehr", "apple.0": "Apfel", "apple.1": "Äpfel"},
+		comments: map[string]string{"Hello": "the greeting"},
+	},
+	{
+		format: "strings",
+		content: `/* the greeting */
+"hello" = "Hello";
+
+// escaped
+"quote" = "Say \"hi\"\n\U00e9";
+unquoted = "value";
+`,
+		expected: map[string]string{"hello": "Hello", "quote": "Say \"hi\"\né", "unquoted": "value"},
+		comments: map[string]string{"hello": "the greeting", "quote": "escaped"},
+	},
+	{
+		format: "xml",
+		content: `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+  <!-- the greeting -->
+  <string name="hello">Hello</string>
+  <string name="escaped">It\'s \"quoted\" &amp; \@home\nnew line</string>
+  <string name="styled">Hello <b>World</b></string>
+  <string name="app" translatable="false">App</string>
+  <plurals name="apples">
+    <item quantity="one">one apple</item>
+    <item quantity="other">%d apples</item>
+  </plurals>
+  <string-array name="planets">
+    <item>Mercury</item>
+    <item>Venus</item>
+  </string-array>
+</resources>
+`,
+		expected: map[string]string{
+			"hello": "Hello", "escaped": "It's \"quoted\" & @home\nnew line", "styled": "Hello <b>World</b>", "app": "App",
+			"apples.one": "one apple", "apples.other": "%d apples", "planets[0]": "Mercury", "planets[1]": "Venus",
+		},
+		comments: map[string]string{"hello": "the greeting"},
+	},
+	{
+		format: "xlf",
+		content: `<?xml version="1.0" encoding="UTF-8"?>
+<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2">
+  <file original="app" source-language="en" target-language="fr" datatype="plaintext">
+    <body>
+      <trans-unit id="1" resname="hello">
+        <source>Hello</source>
+        <target>Bonjour</target>
+        <note>the greeting</note>
+      </trans-unit>
+      <trans-unit id="bye">
+        <source>Bye &amp; thanks</source>
+        <target>Au revoir &amp; merci</target>
+      </trans-unit>
+      <group id="apple" restype="x-gettext-plurals">
+        <trans-unit id="apple[0]">
+          <source>apple</source>
+          <target>pomme</target>
+        </trans-unit>
+        <trans-unit id="apple[1]">
+          <source>apples</source>
+          <target>pommes</target>
+        </trans-unit>
+      </group>
+    </body>
+  </file>
+</xliff>
+`,
+		locale:   "fr",
+		expected: map[string]string{"hello": "Bonjour", "bye": "Au revoir & merci", "apple.0": "pomme", "apple.1": "pommes"},
+		comments: map[string]string{"hello": "the greeting"},
+	},
+}
+
+func TestParse(t *testing.T) {
+	for _, tt := range parseTests {
+		f, err := Parse(tt.format, []byte(tt.content))
+		if err != nil {
+			t.Errorf("%s: didn't expect an error, got: %s", tt.format, err)
+			continue
+		}
+		if f.Locale != tt.locale {
+			t.Errorf("%s: expected locale %q, got %q", tt.format, tt.locale, f.Locale)
+		}
+		if got := f.Translations(); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: expected translations\n%#v\ngot\n%#v", tt.format, tt.expected, got)
+		}
+		for key, comment := range tt.comments {
+			if e := f.Entry(key); e == nil || e.Comment != comment {
+				t.Errorf("%s: expected comment %q for key %q, got entry %#v", tt.format, comment, key, e)
+			}
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, tt := range parseTests {
+		f, err := Parse(tt.format, []byte(tt.content))
+		if err != nil {
+			t.Fatalf("%s: didn't expect an error, got: %s", tt.format, err)
+		}
+		written, err := Write(tt.format, f)
+		if err != nil {
+			t.Errorf("%s: didn't expect an error writing, got: %s", tt.format, err)
+			continue
+		}
+		reparsed, err := Parse(tt.format, written)
+		if err != nil {
+			t.Errorf("%s: didn't expect an error parsing written file, got: %s\n%s", tt.format, err, written)
+			continue
+		}
+		if !reflect.DeepEqual(f, reparsed) {
+			t.Errorf("%s: expected written file to parse to the same content, got:\n%s", tt.format, written)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	f := &File{Locale: "de", Entries: []*Entry{
+		{Key: "hello", Value: "Hallo \"Welt\"", Comment: "the greeting"},
+		{Key: "nested.key", Value: "Zeile 1\nZeile 2 ü"},
+		{Key: "apples", Plural: map[string]string{"one": "ein Apfel", "other": "%{count} Äpfel"}},
+	}}
+	for _, format := range APINames() {
+		written, err := Write(format, f)
+		if format == "properties" || format == "strings" {
+			if err == nil || !strings.Contains(err.Error(), "plurals") {
+				t.Errorf("%s: expected an error about plurals, got: %v", format, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: didn't expect an error, got: %s", format, err)
+			continue
+		}
+		parsed, err := Parse(format, written)
+		if err != nil {
+			t.Errorf("%s: didn't expect an error, got: %s\n%s", format, err, written)
+			continue
+		}
+
+		expected := f.Translations()
+		if format == "gettext" {
+			expected = map[string]string{"hello": "Hallo \"Welt\"", "nested.key": "Zeile 1\nZeile 2 ü", "apples.0": "ein Apfel", "apples.1": "%{count} Äpfel"}
+		}
+		if got := parsed.Translations(); !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s: expected translations\n%#v\ngot\n%#v\n%s", format, expected, got, written)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	if _, err := Lookup("yml"); err != nil {
+		t.Errorf("didn't expect an error, got: %s", err)
+	}
+	_, err := Lookup("csv")
+	if err == nil || !strings.Contains(err.Error(), `format "csv" is not supported`) {
+		t.Errorf("expected an error about the unsupported format, got: %v", err)
+	}
+	if Supported("csv") || !Supported("nested_json") {
+		t.Errorf("expected only nested_json to be supported")
+	}
+}
+
+func TestWriteNestedConflict(t *testing.T) {
+	f := &File{Entries: []*Entry{{Key: "a", Value: "1"}, {Key: "a.b", Value: "2"}}}
+	if _, err := Write("nested_json", f); err == nil {
+		t.Errorf("expected an error for conflicting keys")
+	}
+}
+
+func TestPluralCategoriesFor(t *testing.T) {
+	for locale, expected := range map[string][]string{
+		"en":    {"one", "other"},
+		"de-CH": {"one", "other"},
+		"ja":    {"other"},
+		"ru_RU": {"one", "few", "many", "other"},
+		"ar":    {"zero", "one", "two", "few", "many", "other"},
+	} {
+		if got := PluralCategoriesFor(locale); !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s: expected %v, got %v", locale, expected, got)
+		}
+	}
+}
+
+func TestLookupWithOptions(t *testing.T) {
+	content := []byte("en:\n  apples:\n    one: an apple\n    other: apples\n")
+	format, err := LookupWithOptions("yml", map[string]string{"enable_pluralization": "false"})
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	f, err := format.Parse(content)
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if e := f.Entry("apples.one"); e == nil || e.Plural != nil || e.Value != "an apple" {
+		t.Errorf("expected the plural forms as keys of their own, got %#v", f.Entries)
+	}
+
+	plural, _ := Parse("yml", content)
+	written, err := withoutPlurals{jsonFormat{}}.Write(plural)
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if exp := "{\n  \"apples.one\": \"an apple\",\n  \"apples.other\": \"apples\"\n}\n"; string(written) != exp {
+		t.Errorf("expected %q, got %q", exp, written)
+	}
+
+	format, err = LookupWithOptions("xlf", map[string]string{"enclose_in_cdata": "true"})
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	f = &File{Locale: "de", Entries: []*Entry{{Key: "a", Value: "<b>fett</b> ]]> &"}}}
+	written, err = format.Write(f)
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if !strings.Contains(string(written), "<target><![CDATA[<b>fett</b> ]]]]><![CDATA[> &]]></target>") {
+		t.Errorf("expected the target as CDATA, got:\n%s", written)
+	}
+	if parsed, err := format.Parse(written); err != nil || parsed.Entries[0].Value != f.Entries[0].Value {
+		t.Errorf("expected the CDATA to be read again, got %v (%v)", parsed, err)
+	}
+
+	for message, options := range map[string]map[string]string{
+		`format option "enclose_in_cdata" is not supported for yml files`: {"enclose_in_cdata": "true"},
+		`format option "enable_pluralization" must be true or false`:      {"enable_pluralization": "maybe"},
+	} {
+		if _, err := LookupWithOptions("yml", options); err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("expected an error containing %q, got %v", message, err)
+		}
+	}
+}
+
+func TestXliffInlineElements(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2">
+  <file datatype="plaintext" original="app" source-language="en" target-language="de">
+    <body>
+      <trans-unit id="a" resname="a">
+        <source>A <g id="1">x</g></source>
+        <target>B <g id="1">x</g></target>
+      </trans-unit>
+      <trans-unit id="b" resname="b">
+        <source>Line<x id="1"/>break &amp; more</source>
+        <target>Zeile<x id="1"/>umbruch &amp; mehr</target>
+      </trans-unit>
+      <trans-unit id="c" resname="c">
+        <source>A &amp; B</source>
+        <target>A &amp; B</target>
+      </trans-unit>
+    </body>
+  </file>
+</xliff>
+`
+	f, err := Parse("xlf", []byte(content))
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	for key, exp := range map[string]string{"a": `B <g id="1">x</g>`, "b": `Zeile<x id="1"/>umbruch &amp; mehr`, "c": "A & B"} {
+		if e := f.Entry(key); e == nil || e.Value != exp {
+			t.Errorf("expected %s to be %q, got %#v", key, exp, e)
+		}
+	}
+
+	written, err := Write("xlf", f)
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if string(written) != content {
+		t.Errorf("expected the inline elements to be written as they are, got:\n%s", written)
+	}
+}
+
+func TestConvertPlurals(t *testing.T) {
+	f := &File{Locale: "fr", Entries: []*Entry{
+		{Key: "hello", Value: "Bonjour"},
+		{Key: "apple", Plural: map[string]string{"0": "pomme", "1": "pommes"}},
+	}}
+	if err := ConvertPlurals(f, "yml"); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if exp := map[string]string{"one": "pomme", "other": "pommes"}; !reflect.DeepEqual(f.Entries[1].Plural, exp) {
+		t.Errorf("expected %v, got %v", exp, f.Entries[1].Plural)
+	}
+
+	if err := ConvertPlurals(f, "gettext"); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if exp := map[string]string{"0": "pomme", "1": "pommes"}; !reflect.DeepEqual(f.Entries[1].Plural, exp) {
+		t.Errorf("expected %v, got %v", exp, f.Entries[1].Plural)
+	}
+
+	for locale, message := range map[string]string{
+		"ru": `the plural forms of key "apple" can't be mapped between gettext and CLDR for locale "ru"`,
+		"ja": `key "apple" has the plural forms [0 1], but locale "ja" has 1`,
+		"":   `can't be mapped between gettext and CLDR for locale ""`,
+	} {
+		f.Locale = locale
+		if err := ConvertPlurals(f, "nested_json"); err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("%s: expected an error containing %q, got %v", locale, message, err)
+		}
+	}
+
+	f = &File{Locale: "en", Entries: []*Entry{{Key: "apple", Plural: map[string]string{"other": "apples"}}}}
+	if err := ConvertPlurals(f, "gettext"); err == nil || !strings.Contains(err.Error(), `key "apple" lacks the plural form one of locale "en"`) {
+		t.Errorf("expected an error about the missing plural form, got %v", err)
+	}
+}
+
+func TestParseYAMLScalarsAsWritten(t *testing.T) {
+	f, err := Parse("yml", []byte("no:\n  n: 5\n  y: yes\n  on: 1.0\n  list:\n    - y\n    - off\n  empty: ~\n  date: 2001-01-01\n"))
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if f.Locale != "no" {
+		t.Errorf("expected locale %q, got %q", "no", f.Locale)
+	}
+	expected := map[string]string{
+		"n": "5", "y": "yes", "on": "1.0", "list[0]": "y", "list[1]": "off", "empty": "", "date": "2001-01-01",
+	}
+	if got := f.Translations(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected translations\n%#v\ngot\n%#v", expected, got)
+	}
+}
+
+func TestParseYAMLDuplicateKeys(t *testing.T) {
+	for content, message := range map[string]string{
+		"en:\n  a: A\n  b: B\n  a: again\n":         `line 4: duplicate key "a", first defined on line 2`,
+		"en:\n  a:\n    b: 1\n  a:\n    c: 2\n":     `line 4: duplicate key "a", first defined on line 2`,
+		"en:\n  a:\n    b: 1\n    c: 2\n    b: 3\n": `line 5: duplicate key "b", first defined on line 3`,
+		"en: {a: A, a: B}\n":                        `duplicate key "a"`,
+	} {
+		if _, err := Parse("yml", []byte(content)); err == nil || err.Error() != message {
+			t.Errorf("expected the error %q for %q, got %v", message, content, err)
+		}
+	}
+
+	// keys merged in may be overridden
+	f, err := Parse("yml", []byte("en:\n  base: &base\n    a: A\n    b: B\n  other:\n    <<: *base\n    b: override\n"))
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	expected := map[string]string{"base.a": "A", "base.b": "B", "other.a": "A", "other.b": "override"}
+	if got := f.Translations(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected translations\n%#v\ngot\n%#v", expected, got)
+	}
+}
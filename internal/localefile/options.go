@@ -0,0 +1,122 @@
+package localefile
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// formatOptions are the format options supported by format, named like the format_options of uploads and downloads.
+var formatOptions = map[string][]string{
+	"yml":         {"enable_pluralization"},
+	"simple_json": {"enable_pluralization"},
+	"nested_json": {"enable_pluralization"},
+	"xlf":         {"enclose_in_cdata"},
+}
+
+// extensions are the usual file extensions of the formats.
+var extensions = map[string]string{
+	"yml":         "yml",
+	"simple_json": "json",
+	"nested_json": "json",
+	"properties":  "properties",
+	"gettext":     "po",
+	"strings":     "strings",
+	"xml":         "xml",
+	"xlf":         "xlf",
+}
+
+// Extension returns the usual file extension of the format with the given API name, without the dot.
+func Extension(apiName string) string {
+	return extensions[apiName]
+}
+
+// LookupWithOptions returns the format with the given API name, configured by format options like the format_options
+// of uploads and downloads:
+//
+//	enable_pluralization  yml, simple_json and nested_json files have pluralized keys, true by default. If false,
+//	                      plural forms are keys of their own, e.g. apples.one.
+//	enclose_in_cdata      the texts of xlf files are written as CDATA sections.
+//
+// Options the format doesn't support are an error.
+func LookupWithOptions(apiName string, options map[string]string) (Format, error) {
+	format, err := Lookup(apiName)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !supportsOption(apiName, name) {
+			return nil, fmt.Errorf("format option %q is not supported for %s files, supported are %v", name, apiName, formatOptions[apiName])
+		}
+		enabled, err := strconv.ParseBool(options[name])
+		if err != nil {
+			return nil, fmt.Errorf("format option %q must be true or false, got %q", name, options[name])
+		}
+
+		switch name {
+		case "enable_pluralization":
+			if !enabled {
+				format = withoutPlurals{format}
+			}
+		case "enclose_in_cdata":
+			format = xliff{cdata: enabled}
+		}
+	}
+	return format, nil
+}
+
+func supportsOption(apiName, name string) bool {
+	for _, option := range formatOptions[apiName] {
+		if option == name {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutPlurals is a format with pluralization disabled: the plural forms of keys are read and written as keys of
+// their own.
+type withoutPlurals struct {
+	Format
+}
+
+func (w withoutPlurals) Parse(content []byte) (*File, error) {
+	f, err := w.Format.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	f.Entries = expandPlurals(f.Entries)
+	return f, nil
+}
+
+func (w withoutPlurals) Write(f *File) ([]byte, error) {
+	expanded := *f
+	expanded.Entries = expandPlurals(f.Entries)
+	return w.Format.Write(&expanded)
+}
+
+// expandPlurals returns the entries with each plural form of pluralized keys as an entry of its own, keyed <key>.<form>.
+func expandPlurals(entries []*Entry) []*Entry {
+	expanded := make([]*Entry, 0, len(entries))
+	for _, e := range entries {
+		if e.Plural == nil {
+			expanded = append(expanded, e)
+			continue
+		}
+		for i, form := range e.PluralForms() {
+			formEntry := &Entry{Key: e.Key + "." + form, Value: e.Plural[form]}
+			if i == 0 {
+				formEntry.Comment = e.Comment
+			}
+			expanded = append(expanded, formEntry)
+		}
+	}
+	return expanded
+}
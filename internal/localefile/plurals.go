@@ -0,0 +1,182 @@
+package localefile
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PluralCategories are the CLDR plural categories in their usual order.
+var PluralCategories = []string{"zero", "one", "two", "few", "many", "other"}
+
+// pluralCategoriesByLanguage are the CLDR plural categories used for cardinal numbers by language.
+var pluralCategoriesByLanguage = map[string][]string{
+	"ar": {"zero", "one", "two", "few", "many", "other"},
+	"be": {"one", "few", "many", "other"},
+	"bg": {"one", "other"},
+	"ca": {"one", "other"},
+	"cs": {"one", "few", "many", "other"},
+	"cy": {"zero", "one", "two", "few", "many", "other"},
+	"da": {"one", "other"},
+	"de": {"one", "other"},
+	"el": {"one", "other"},
+	"en": {"one", "other"},
+	"es": {"one", "many", "other"},
+	"et": {"one", "other"},
+	"fa": {"one", "other"},
+	"fi": {"one", "other"},
+	"fr": {"one", "many", "other"},
+	"ga": {"one", "two", "few", "many", "other"},
+	"he": {"one", "two", "other"},
+	"hi": {"one", "other"},
+	"hr": {"one", "few", "other"},
+	"hu": {"one", "other"},
+	"id": {"other"},
+	"it": {"one", "many", "other"},
+	"ja": {"other"},
+	"ko": {"other"},
+	"lt": {"one", "few", "many", "other"},
+	"lv": {"zero", "one", "other"},
+	"ms": {"other"},
+	"nb": {"one", "other"},
+	"nl": {"one", "other"},
+	"no": {"one", "other"},
+	"pl": {"one", "few", "many", "other"},
+	"pt": {"one", "many", "other"},
+	"ro": {"one", "few", "other"},
+	"ru": {"one", "few", "many", "other"},
+	"sk": {"one", "few", "many", "other"},
+	"sl": {"one", "two", "few", "other"},
+	"sr": {"one", "few", "other"},
+	"sv": {"one", "other"},
+	"th": {"other"},
+	"tr": {"one", "other"},
+	"uk": {"one", "few", "many", "other"},
+	"vi": {"other"},
+	"zh": {"other"},
+}
+
+// PluralCategoriesFor returns the CLDR plural categories of the language of a locale code like de or pt-BR, or nil
+// if the language is unknown.
+func PluralCategoriesFor(locale string) []string {
+	return pluralCategoriesByLanguage[language(locale)]
+}
+
+func language(locale string) string {
+	language := strings.ToLower(locale)
+	if i := strings.IndexAny(language, "-_"); i >= 0 {
+		language = language[:i]
+	}
+	return language
+}
+
+// gettextPluralCategories are the CLDR plural categories of the msgstr indices of the usual Plural-Forms of languages,
+// for which they differ from the categories of pluralCategoriesByLanguage: CLDR distinguishes large numbers or
+// fractions gettext doesn't. Nil marks languages whose forms can't be mapped, as gettext lacks the other category.
+var gettextPluralCategories = map[string][]string{
+	"be": nil,
+	"cs": {"one", "few", "other"},
+	"es": {"one", "other"},
+	"fr": {"one", "other"},
+	"it": {"one", "other"},
+	"lt": {"one", "few", "other"},
+	"pl": nil,
+	"pt": {"one", "other"},
+	"ru": nil,
+	"sk": {"one", "few", "other"},
+	"uk": nil,
+}
+
+func gettextPluralCategoriesFor(locale string) []string {
+	if categories, found := gettextPluralCategories[language(locale)]; found {
+		return categories
+	}
+	return PluralCategoriesFor(locale)
+}
+
+// ConvertPlurals converts the plural forms of f for writing it in the format with the given API name: the msgstr
+// indices of gettext files are mapped to the CLDR plural categories of the locale, and back for writing gettext and
+// xlf files, the plural groups of which are numbered like gettext. Plural forms that can't be mapped are an error.
+func ConvertPlurals(f *File, apiName string) error {
+	toGettext := apiName == "gettext" || apiName == "xlf"
+	for _, e := range f.Entries {
+		if e.Plural == nil || hasGettextPluralForms(e) == toGettext {
+			continue
+		}
+		categories := gettextPluralCategoriesFor(f.Locale)
+		if len(categories) == 0 {
+			return fmt.Errorf("the plural forms of key %q can't be mapped between gettext and CLDR for locale %q", e.Key, f.Locale)
+		}
+
+		plural := map[string]string{}
+		for i, category := range categories {
+			from, to := strconv.Itoa(i), category
+			if toGettext {
+				from, to = category, strconv.Itoa(i)
+			}
+			value, found := e.Plural[from]
+			if !found {
+				return fmt.Errorf("key %q lacks the plural form %s of locale %q", e.Key, from, f.Locale)
+			}
+			plural[to] = value
+		}
+		if len(plural) != len(e.Plural) {
+			return fmt.Errorf("key %q has the plural forms %v, but locale %q has %d", e.Key, e.PluralForms(), f.Locale, len(categories))
+		}
+		e.Plural = plural
+	}
+	return nil
+}
+
+// hasGettextPluralForms reports whether the plural forms of an entry are msgstr indices.
+func hasGettextPluralForms(e *Entry) bool {
+	for form := range e.Plural {
+		if _, err := strconv.Atoi(form); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// IsPluralCategory reports whether s is one of the CLDR plural categories.
+func IsPluralCategory(s string) bool {
+	return pluralCategoryIndex(s) >= 0
+}
+
+func pluralCategoryIndex(s string) int {
+	for i, c := range PluralCategories {
+		if c == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// lessPluralForm orders CLDR categories first, then the gettext indices by number.
+func lessPluralForm(a, b string) bool {
+	ia, ib := pluralCategoryIndex(a), pluralCategoryIndex(b)
+	switch {
+	case ia >= 0 && ib >= 0:
+		return ia < ib
+	case ia >= 0 || ib >= 0:
+		return ia >= 0
+	case len(a) != len(b):
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+// isPluralMap reports whether the keys of a map in a nested format are plural forms, which requires the other form.
+func isPluralMap(keys []string) bool {
+	if len(keys) == 0 {
+		return false
+	}
+	other := false
+	for _, key := range keys {
+		if !IsPluralCategory(key) {
+			return false
+		}
+		other = other || key == "other"
+	}
+	return other
+}
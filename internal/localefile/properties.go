@@ -0,0 +1,191 @@
+package localefile
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// properties is the format of Java properties files, with comments, continuation lines and escapes.
+type properties struct{}
+
+func (properties) Parse(content []byte) (*File, error) {
+	f := &File{}
+	pending := []string{}
+	logical := ""
+
+	add := func(line string) error {
+		key, value, err := splitProperty(line)
+		if err != nil {
+			return err
+		}
+		f.Entries = append(f.Entries, &Entry{Key: key, Value: value, Comment: strings.Join(pending, "\n")})
+		pending = pending[:0]
+		return nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimLeft(scanner.Text(), " \t\f")
+		if logical == "" {
+			switch {
+			case line == "":
+				pending = pending[:0]
+				continue
+			case line[0] == '#' || line[0] == '!':
+				pending = append(pending, strings.TrimSpace(line[1:]))
+				continue
+			}
+		}
+
+		// an odd number of trailing backslashes continues the line
+		trailing := len(line) - len(strings.TrimRight(line, "\\"))
+		if trailing%2 == 1 {
+			logical += line[:len(line)-1]
+			continue
+		}
+
+		if err := add(logical + line); err != nil {
+			return nil, err
+		}
+		logical = ""
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if logical != "" {
+		if err := add(logical); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func splitProperty(line string) (string, string, error) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte("=: \t\f", line[i]) >= 0 {
+			end = i
+			break
+		}
+	}
+
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+
+	key, err := unescapeProperty(line[:end])
+	if err != nil {
+		return "", "", err
+	}
+	value, err := unescapeProperty(rest)
+	if err != nil {
+		return "", "", err
+	}
+	return key, value, nil
+}
+
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	var surrogate rune // high surrogate waiting for the low one
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("invalid unicode escape in %q", s)
+			}
+			u, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("invalid unicode escape in %q", s)
+			}
+			i += 4
+
+			r := rune(u)
+			switch {
+			case utf16.IsSurrogate(r) && surrogate == 0:
+				surrogate = r
+				continue
+			case surrogate != 0:
+				r = utf16.DecodeRune(surrogate, r)
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte(s[i])
+		}
+		surrogate = 0
+	}
+	return b.String(), nil
+}
+
+func (properties) Write(f *File) ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	for _, e := range f.Entries {
+		if e.Plural != nil {
+			return nil, errPlurals("properties", e)
+		}
+		if e.Comment != "" {
+			for _, line := range strings.Split(e.Comment, "\n") {
+				fmt.Fprintf(buf, "# %s\n", line)
+			}
+		}
+		fmt.Fprintf(buf, "%s=%s\n", escapeProperty(e.Key, true), escapeProperty(e.Value, false))
+	}
+	return buf.Bytes(), nil
+}
+
+// escapeProperty escapes the special characters of keys or values, and all characters outside of ASCII, as properties
+// files are read as ISO 8859-1 by Java.
+func escapeProperty(s string, key bool) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\f':
+			b.WriteString(`\f`)
+		case r == ' ' && (key || i == 0):
+			b.WriteString(`\ `)
+		case key && strings.ContainsRune("=:#!", r), !key && i == 0 && (r == '#' || r == '!'):
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case r > 0x7e || r < 0x20:
+			for _, u := range utf16.Encode([]rune{r}) {
+				fmt.Fprintf(&b, `\u%04x`, u)
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
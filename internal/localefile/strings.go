@@ -0,0 +1,209 @@
+package localefile
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// appleStrings is the format of the .strings files of iOS and macOS, with "key" = "value"; pairs and C style comments.
+type appleStrings struct{}
+
+func (appleStrings) Parse(content []byte) (*File, error) {
+	s := &stringsScanner{src: string(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")))}
+	f := &File{}
+	comments := []string{}
+
+	for {
+		s.skipSpace()
+		if s.done() {
+			return f, nil
+		}
+
+		switch {
+		case strings.HasPrefix(s.rest(), "/*"):
+			end := strings.Index(s.rest(), "*/")
+			if end < 0 {
+				return nil, s.errorf("unterminated comment")
+			}
+			comments = append(comments, strings.TrimSpace(s.rest()[2:end]))
+			s.pos += end + 2
+			continue
+		case strings.HasPrefix(s.rest(), "//"):
+			end := strings.IndexByte(s.rest(), '\n')
+			if end < 0 {
+				end = len(s.rest())
+			}
+			comments = append(comments, strings.TrimSpace(s.rest()[2:end]))
+			s.pos += end
+			continue
+		}
+
+		key, err := s.token()
+		if err != nil {
+			return nil, err
+		}
+		if err := s.expect('='); err != nil {
+			return nil, err
+		}
+		value, err := s.token()
+		if err != nil {
+			return nil, err
+		}
+		if err := s.expect(';'); err != nil {
+			return nil, err
+		}
+
+		f.Entries = append(f.Entries, &Entry{Key: key, Value: value, Comment: strings.Join(comments, "\n")})
+		comments = comments[:0]
+	}
+}
+
+type stringsScanner struct {
+	src string
+	pos int
+}
+
+func (s *stringsScanner) rest() string {
+	return s.src[s.pos:]
+}
+
+func (s *stringsScanner) done() bool {
+	return s.pos >= len(s.src)
+}
+
+func (s *stringsScanner) errorf(format string, args ...interface{}) error {
+	line := strings.Count(s.src[:s.pos], "\n") + 1
+	return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func (s *stringsScanner) skipSpace() {
+	for !s.done() && unicode.IsSpace(rune(s.src[s.pos])) {
+		s.pos++
+	}
+}
+
+func (s *stringsScanner) expect(c byte) error {
+	s.skipSpace()
+	if s.done() || s.src[s.pos] != c {
+		return s.errorf("expected %q", c)
+	}
+	s.pos++
+	return nil
+}
+
+// token reads a quoted string or an unquoted word.
+func (s *stringsScanner) token() (string, error) {
+	s.skipSpace()
+	if s.done() {
+		return "", s.errorf("unexpected end of file")
+	}
+
+	if s.src[s.pos] != '"' {
+		start := s.pos
+		for !s.done() && (isStringsWordChar(rune(s.src[s.pos]))) {
+			s.pos++
+		}
+		if start == s.pos {
+			return "", s.errorf("unexpected %q", s.src[s.pos])
+		}
+		return s.src[start:s.pos], nil
+	}
+
+	var b strings.Builder
+	for s.pos++; !s.done(); s.pos++ {
+		c := s.src[s.pos]
+		switch {
+		case c == '"':
+			s.pos++
+			return b.String(), nil
+		case c != '\\':
+			b.WriteByte(c)
+			continue
+		}
+
+		s.pos++
+		if s.done() {
+			break
+		}
+		switch s.src[s.pos] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '0':
+			b.WriteByte(0)
+		case 'u', 'U':
+			r, err := s.unicodeEscape()
+			if err != nil {
+				return "", err
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte(s.src[s.pos])
+		}
+	}
+	return "", s.errorf("unterminated string")
+}
+
+// unicodeEscape reads the four hex digits after \u, and a following escaped low surrogate.
+func (s *stringsScanner) unicodeEscape() (rune, error) {
+	read := func() (rune, error) {
+		if s.pos+5 > len(s.src) {
+			return 0, s.errorf("invalid unicode escape")
+		}
+		u, err := strconv.ParseUint(s.src[s.pos+1:s.pos+5], 16, 16)
+		if err != nil {
+			return 0, s.errorf("invalid unicode escape")
+		}
+		s.pos += 4
+		return rune(u), nil
+	}
+
+	r, err := read()
+	if err != nil || !utf16.IsSurrogate(r) {
+		return r, err
+	}
+	if rest := s.src[s.pos+1:]; len(rest) >= 2 && rest[0] == '\\' && (rest[1] == 'u' || rest[1] == 'U') {
+		s.pos += 2
+		low, err := read()
+		if err != nil {
+			return 0, err
+		}
+		return utf16.DecodeRune(r, low), nil
+	}
+	return unicode.ReplacementChar, nil
+}
+
+func isStringsWordChar(r rune) bool {
+	return r == '_' || r == '.' || r == '-' || r == '$' || r == ':' || r == '/' ||
+		('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9')
+}
+
+func (appleStrings) Write(f *File) ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	for i, e := range f.Entries {
+		if e.Plural != nil {
+			return nil, errPlurals("strings", e)
+		}
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		if e.Comment != "" {
+			fmt.Fprintf(buf, "/* %s */\n", strings.Replace(e.Comment, "*/", "* /", -1))
+		}
+		fmt.Fprintf(buf, "%s = %s;\n", quoteStrings(e.Key), quoteStrings(e.Value))
+	}
+	return buf.Bytes(), nil
+}
+
+var stringsEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+
+func quoteStrings(s string) string {
+	return `"` + stringsEscaper.Replace(s) + `"`
+}
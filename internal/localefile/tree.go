@@ -0,0 +1,169 @@
+package localefile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// member is a key of an object in a nested format, in file order. Values are []member for objects, []interface{}
+// for lists or scalars.
+type member struct {
+	key   string
+	value interface{}
+}
+
+// flattenNested adds the keys nested in v to f. Nested keys are joined with dots, list elements get their index
+// appended like key[0], and objects with only plural forms as keys become pluralized entries. Comments are looked up
+// by the path of a key, its segments joined by NUL bytes.
+func flattenNested(f *File, path []string, key string, v interface{}, comments map[string]string) {
+	comment := comments[strings.Join(path, "\x00")]
+
+	switch v := v.(type) {
+	case []member:
+		keys := make([]string, 0, len(v))
+		for _, m := range v {
+			keys = append(keys, m.key)
+		}
+		if key != "" && isPluralMap(keys) {
+			e := &Entry{Key: key, Plural: map[string]string{}, Comment: comment}
+			for _, m := range v {
+				e.Plural[m.key] = scalarString(m.value)
+			}
+			f.Entries = append(f.Entries, e)
+			return
+		}
+
+		for _, m := range v {
+			nestedKey := m.key
+			if key != "" {
+				nestedKey = key + "." + m.key
+			}
+			flattenNested(f, append(path[:len(path):len(path)], m.key), nestedKey, m.value, comments)
+		}
+	case []interface{}:
+		for i, item := range v {
+			index := fmt.Sprintf("[%d]", i)
+			flattenNested(f, append(path[:len(path):len(path)], index), key+index, item, comments)
+		}
+	default:
+		if key != "" {
+			f.Entries = append(f.Entries, &Entry{Key: key, Value: scalarString(v), Comment: comment})
+		}
+	}
+}
+
+func scalarString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// node is a key of a nested format, holding either an entry or the keys nested in it.
+type node struct {
+	name     string
+	entry    *Entry
+	children []*node
+	list     bool // the children are list elements, named by their index
+}
+
+var listElementRegexp = regexp.MustCompile(`^(.+)\[(\d+)\]$`)
+
+// buildTree nests the entries by the dots in their keys. Keys ending in an index like key[0] become list elements.
+func buildTree(entries []*Entry) (*node, error) {
+	root := &node{}
+	for _, e := range entries {
+		n := root
+		for _, segment := range strings.Split(e.Key, ".") {
+			var err error
+			if m := listElementRegexp.FindStringSubmatch(segment); m != nil {
+				if n, err = n.child(m[1], false); err == nil {
+					n, err = n.child(m[2], true)
+				}
+			} else {
+				n, err = n.child(segment, false)
+			}
+			if err != nil || n.entry != nil {
+				return nil, fmt.Errorf("key %q conflicts with another key", e.Key)
+			}
+		}
+		if len(n.children) > 0 {
+			return nil, fmt.Errorf("key %q conflicts with the keys nested in it", e.Key)
+		}
+		n.entry = e
+	}
+	return root, nil
+}
+
+// child returns the child with the given name, which is added if missing. Lists can't have other children.
+func (n *node) child(name string, element bool) (*node, error) {
+	if len(n.children) > 0 && n.list != element {
+		return nil, fmt.Errorf("%s is a list and an object", n.name)
+	}
+	n.list = element
+	for _, c := range n.children {
+		if c.name == name {
+			return c, nil
+		}
+	}
+	c := &node{name: name}
+	n.children = append(n.children, c)
+	return c, nil
+}
+
+// value returns the content of the node as []member, []interface{} or string.
+func (n *node) value() interface{} {
+	switch {
+	case n.entry != nil && n.entry.Plural == nil:
+		return n.entry.Value
+	case n.entry != nil:
+		return pluralMembers(n.entry)
+	case n.list:
+		items := make([]interface{}, 0, len(n.children))
+		for _, c := range n.children {
+			items = append(items, c.value())
+		}
+		return items
+	}
+	members := make([]member, 0, len(n.children))
+	for _, c := range n.children {
+		members = append(members, member{key: c.name, value: c.value()})
+	}
+	return members
+}
+
+func pluralMembers(e *Entry) []member {
+	members := []member{}
+	for _, form := range e.PluralForms() {
+		members = append(members, member{key: form, value: e.Plural[form]})
+	}
+	return members
+}
+
+// jsonString returns s as a JSON string, without escaping HTML characters.
+func jsonString(s string) string {
+	buf := bytes.NewBuffer(nil)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func sortedAttributes(attributes map[string]string) []string {
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
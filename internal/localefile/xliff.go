@@ -0,0 +1,219 @@
+package localefile
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// xliff is the format of XLIFF 1.2 files. The resname of a trans-unit is the key, or its id if there is none, and the
+// target is the translation. The source texts are kept as attributes of the entries. Pluralized keys are groups of
+// trans-units with the restype x-gettext-plurals, one per plural form, like gettext tools write them. Texts with inline
+// elements like <g> or <x/> are kept as markup.
+type xliff struct {
+	// cdata writes texts as CDATA sections instead of escaping them.
+	cdata bool
+}
+
+// xliffPluralRestype is the restype of the groups of pluralized keys.
+const xliffPluralRestype = "x-gettext-plurals"
+
+type xliffDocument struct {
+	Files []xliffFile `xml:"file"`
+}
+
+type xliffFile struct {
+	Original       string    `xml:"original,attr"`
+	SourceLanguage string    `xml:"source-language,attr"`
+	TargetLanguage string    `xml:"target-language,attr"`
+	Datatype       string    `xml:"datatype,attr"`
+	Body           xliffUnit `xml:"body"`
+}
+
+// xliffUnit is a trans-unit, or a group or body of them.
+type xliffUnit struct {
+	XMLName xml.Name
+	ID      string      `xml:"id,attr"`
+	Resname string      `xml:"resname,attr"`
+	Restype string      `xml:"restype,attr"`
+	Source  xliffText   `xml:"source"`
+	Target  xliffText   `xml:"target"`
+	Notes   []string    `xml:"note"`
+	Units   []xliffUnit `xml:",any"`
+}
+
+// xliffText is the content of a source or target, which can contain inline elements besides text.
+type xliffText struct {
+	Inner string `xml:",innerxml"`
+}
+
+// String returns the text, or the content as it is if it contains inline elements.
+func (t xliffText) String() string {
+	text, elements := "", 0
+	dec := xml.NewDecoder(strings.NewReader("<x>" + t.Inner + "</x>"))
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			// Unmarshal read the content already, so this is the end of it
+			return text
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if elements++; elements > 1 {
+				return t.Inner
+			}
+		case xml.CharData:
+			text += string(token)
+		}
+	}
+}
+
+func (xliff) Parse(content []byte) (*File, error) {
+	doc := &xliffDocument{}
+	if err := xml.Unmarshal(content, doc); err != nil {
+		return nil, err
+	}
+
+	f := &File{}
+	for _, file := range doc.Files {
+		if f.Locale == "" {
+			f.Locale = file.TargetLanguage
+			f.setAttribute("source-language", file.SourceLanguage)
+			f.setAttribute("original", file.Original)
+			f.setAttribute("datatype", file.Datatype)
+		}
+		f.Entries = append(f.Entries, xliffEntries(file.Body.Units)...)
+	}
+	return f, nil
+}
+
+func xliffEntries(units []xliffUnit) []*Entry {
+	entries := []*Entry{}
+	for _, unit := range units {
+		switch {
+		case unit.XMLName.Local == "group" && unit.Restype == xliffPluralRestype:
+			entries = append(entries, xliffPluralEntry(unit))
+		case unit.XMLName.Local == "group":
+			entries = append(entries, xliffEntries(unit.Units)...)
+		case unit.XMLName.Local == "trans-unit":
+			e := xliffEntry(unit)
+			e.Value = unit.Target.String()
+			e.setAttribute("source", unit.Source.String())
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// xliffEntry returns the entry of a trans-unit or plural group, without its translations.
+func xliffEntry(unit xliffUnit) *Entry {
+	e := &Entry{Key: unit.Resname, Comment: strings.Join(unit.Notes, "\n")}
+	if e.Key == "" {
+		e.Key = unit.ID
+	} else if unit.ID != unit.Resname {
+		e.setAttribute("id", unit.ID)
+	}
+	return e
+}
+
+// xliffPluralEntry returns the entry of a plural group. The plural forms are taken from the ids of the trans-units,
+// e.g. apples[one], or their position. The source of the first form is the source of the entry, a different one of
+// the other forms is kept like the msgid_plural of gettext files.
+func xliffPluralEntry(group xliffUnit) *Entry {
+	e := xliffEntry(group)
+	e.Plural = map[string]string{}
+	i := 0
+	for _, unit := range group.Units {
+		if unit.XMLName.Local != "trans-unit" {
+			continue
+		}
+		form := strconv.Itoa(i)
+		if start := strings.LastIndex(unit.ID, "["); start >= 0 && strings.HasSuffix(unit.ID, "]") {
+			form = unit.ID[start+1 : len(unit.ID)-1]
+		}
+		e.Plural[form] = unit.Target.String()
+		if source := unit.Source.String(); i == 0 {
+			e.setAttribute("source", source)
+		} else if source != e.attribute("source") {
+			e.setAttribute("msgid_plural", source)
+		}
+		i++
+	}
+	return e
+}
+
+func (format xliff) Write(f *File) ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	buf.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	buf.WriteString("<xliff version=\"1.2\" xmlns=\"urn:oasis:names:tc:xliff:document:1.2\">\n")
+
+	attrs := map[string]string{
+		"original":        f.attribute("original"),
+		"source-language": f.attribute("source-language"),
+		"target-language": f.Locale,
+		"datatype":        f.attribute("datatype"),
+	}
+	if attrs["original"] == "" {
+		attrs["original"] = "phraseapp"
+	}
+	if attrs["datatype"] == "" {
+		attrs["datatype"] = "plaintext"
+	}
+	if attrs["source-language"] == "" {
+		attrs["source-language"] = f.Locale
+	}
+	if attrs["source-language"] == "" {
+		return nil, fmt.Errorf("xlf files require a locale")
+	}
+	fmt.Fprintf(buf, "  <file%s>\n    <body>\n", xmlAttrs(attrs))
+
+	for _, e := range f.Entries {
+		id := e.attribute("id")
+		if id == "" {
+			id = e.Key
+		}
+		if e.Plural == nil {
+			format.writeUnit(buf, "      ", fmt.Sprintf("id=%s resname=%s", xmlAttr(id), xmlAttr(e.Key)), e.attribute("source"), e.Value, e.Comment)
+			continue
+		}
+
+		fmt.Fprintf(buf, "      <group id=%s resname=%s restype=%q>\n", xmlAttr(id), xmlAttr(e.Key), xliffPluralRestype)
+		if e.Comment != "" {
+			fmt.Fprintf(buf, "        <note>%s</note>\n", xmlText(e.Comment))
+		}
+		for i, form := range e.PluralForms() {
+			source := e.attribute("source")
+			if plural := e.attribute("msgid_plural"); i > 0 && plural != "" {
+				source = plural
+			}
+			format.writeUnit(buf, "        ", "id="+xmlAttr(id+"["+form+"]"), source, e.Plural[form], "")
+		}
+		buf.WriteString("      </group>\n")
+	}
+
+	buf.WriteString("    </body>\n  </file>\n</xliff>\n")
+	return buf.Bytes(), nil
+}
+
+func (format xliff) writeUnit(buf *bytes.Buffer, indent, attrs, source, target, note string) {
+	fmt.Fprintf(buf, "%s<trans-unit %s>\n", indent, attrs)
+	fmt.Fprintf(buf, "%s  <source>%s</source>\n", indent, format.text(source))
+	fmt.Fprintf(buf, "%s  <target>%s</target>\n", indent, format.text(target))
+	if note != "" {
+		fmt.Fprintf(buf, "%s  <note>%s</note>\n", indent, xmlText(note))
+	}
+	fmt.Fprintf(buf, "%s</trans-unit>\n", indent)
+}
+
+func (format xliff) text(s string) string {
+	if !format.cdata && hasMarkup(s) {
+		return s
+	}
+	if !format.cdata || s == "" {
+		return xmlText(s)
+	}
+	// a CDATA section can't contain its end, which is split into two sections
+	return "<![CDATA[" + strings.Replace(s, "]]>", "]]]]><![CDATA[>", -1) + "]]>"
+}
@@ -0,0 +1,316 @@
+package localefile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// railsYAML is the format of Rails, with the locale code as the root key and nested keys.
+type railsYAML struct{}
+
+func (railsYAML) Parse(content []byte) (*File, error) {
+	var root yamlNode
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		if dup, ok := err.(*yamlDuplicateKeyError); ok {
+			return nil, dup.withLines(content)
+		}
+		return nil, err
+	}
+
+	f := &File{}
+	if root.value == nil {
+		return f, nil
+	}
+	members, ok := root.value.([]member)
+	if !ok || len(members) != 1 {
+		return nil, errors.New("expected the locale code as the only root key")
+	}
+	f.Locale = members[0].key
+	flattenNested(f, []string{f.Locale}, "", members[0].value, yamlComments(content))
+	return f, nil
+}
+
+// yamlNode is a decoded YAML value with its scalars as written, as YAML 1.1 reads plain scalars like n, on or 1.0 as
+// booleans and numbers. Mappings are decoded to members in the order of the file, sequences to lists and null to nil.
+type yamlNode struct {
+	value interface{}
+}
+
+func (n *yamlNode) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var raw interface{}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+
+	switch raw.(type) {
+	case nil:
+		n.value = nil
+	case map[interface{}]interface{}, yaml.MapSlice:
+		members, err := yamlMembers(unmarshal)
+		if err != nil {
+			return err
+		}
+		n.value = members
+	case []interface{}:
+		var nodes []yamlNode
+		if err := unmarshal(&nodes); err != nil {
+			return err
+		}
+		items := make([]interface{}, 0, len(nodes))
+		for _, item := range nodes {
+			items = append(items, item.value)
+		}
+		n.value = items
+	default:
+		// the YAML library sets strings to the text of any scalar
+		var s string
+		if err := unmarshal(&s); err != nil {
+			return err
+		}
+		n.value = s
+	}
+	return nil
+}
+
+// yamlKeySeq numbers the decoded keys of mappings, which restores their order after decoding them to a Go map.
+var yamlKeySeq uint64
+
+// yamlNodeKey is a key of a mapping as written, with its position among all decoded keys.
+type yamlNodeKey struct {
+	text string
+	seq  uint64
+}
+
+func (k *yamlNodeKey) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal(&k.text); err != nil {
+		return err
+	}
+	k.seq = atomic.AddUint64(&yamlKeySeq, 1)
+	return nil
+}
+
+// yamlMembers decodes a mapping to members in the order of the file. Keys defined twice are an error, except for keys
+// merged in with <<, which the keys of the mapping itself override.
+func yamlMembers(unmarshal func(interface{}) error) ([]member, error) {
+	var nodes map[yamlNodeKey]yamlNode
+	if err := unmarshal(&nodes); err != nil {
+		return nil, err
+	}
+	// the YAML library leaves out merged keys when decoding to a MapSlice
+	var own yaml.MapSlice
+	if err := unmarshal(&own); err != nil {
+		return nil, err
+	}
+	merged := len(nodes) > len(own)
+
+	keys := make([]yamlNodeKey, 0, len(nodes))
+	for k := range nodes {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].seq < keys[j].seq })
+
+	members := make([]member, 0, len(keys))
+	indices := map[string]int{}
+	for _, k := range keys {
+		i, found := indices[k.text]
+		switch {
+		case found && !merged:
+			return nil, &yamlDuplicateKeyError{key: k.text}
+		case found:
+			members[i].value = nodes[k].value
+		default:
+			indices[k.text] = len(members)
+			members = append(members, member{key: k.text, value: nodes[k].value})
+		}
+	}
+	return members, nil
+}
+
+// yamlDuplicateKeyError is returned for keys defined twice in a mapping.
+type yamlDuplicateKeyError struct {
+	key string
+}
+
+func (e *yamlDuplicateKeyError) Error() string {
+	return fmt.Sprintf("duplicate key %q", e.key)
+}
+
+// withLines returns the error with the lines the key is defined on, if they can be found.
+func (e *yamlDuplicateKeyError) withLines(content []byte) error {
+	first := map[string]int{}
+	for _, k := range yamlKeyLines(content) {
+		if k.path[len(k.path)-1] != e.key {
+			continue
+		}
+		path := strings.Join(k.path, "\x00")
+		if line, found := first[path]; found {
+			return fmt.Errorf("line %d: duplicate key %q, first defined on line %d", k.line, e.key, line)
+		}
+		first[path] = k.line
+	}
+	return e
+}
+
+var yamlKeyLineRegexp = regexp.MustCompile(`^(\s*)("(?:[^"\\]|\\.)*"|'(?:[^']|'')*'|[^\s#'"\-][^:#]*?|-[^\s:#][^:#]*?)\s*:(?:\s+(.*))?$`)
+
+// yamlComments returns the comments on the lines right above keys by the path of the keys, as the YAML library drops
+// comments.
+func yamlComments(content []byte) map[string]string {
+	comments := map[string]string{}
+	for _, k := range yamlKeyLines(content) {
+		if k.comment != "" {
+			comments[strings.Join(k.path, "\x00")] = k.comment
+		}
+	}
+	return comments
+}
+
+// yamlKeyLine is a key of a block mapping with the line it is defined on and the comment right above it.
+type yamlKeyLine struct {
+	path    []string
+	line    int
+	comment string
+}
+
+// yamlKeyLines returns the keys of block mappings in the order of the file. The paths are found by the indentation
+// of the keys.
+func yamlKeyLines(content []byte) []yamlKeyLine {
+	type level struct {
+		indent int
+		key    string
+	}
+
+	keys := []yamlKeyLine{}
+	stack := []level{}
+	pending := []string{}
+	blockIndent := -1 // lines indented further belong to a block scalar
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if blockIndent >= 0 {
+			if trimmed == "" || indent > blockIndent {
+				continue
+			}
+			blockIndent = -1
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "#"):
+			pending = append(pending, strings.TrimSpace(strings.TrimPrefix(trimmed, "#")))
+			continue
+		case trimmed == "", trimmed == "---", strings.HasPrefix(trimmed, "-"):
+			pending = pending[:0]
+			continue
+		}
+
+		m := yamlKeyLineRegexp.FindStringSubmatch(line)
+		if m == nil {
+			pending = pending[:0]
+			continue
+		}
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		stack = append(stack, level{indent: indent, key: unquoteYAMLKey(m[2])})
+		if value := strings.TrimSpace(m[3]); strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">") {
+			blockIndent = indent
+		}
+
+		path := make([]string, 0, len(stack))
+		for _, l := range stack {
+			path = append(path, l.key)
+		}
+		keys = append(keys, yamlKeyLine{path: path, line: lineNumber, comment: strings.Join(pending, "\n")})
+		pending = pending[:0]
+	}
+	return keys
+}
+
+func unquoteYAMLKey(key string) string {
+	switch {
+	case strings.HasPrefix(key, `"`):
+		var s string
+		if err := json.Unmarshal([]byte(key), &s); err == nil {
+			return s
+		}
+	case strings.HasPrefix(key, "'"):
+		return strings.Replace(key[1:len(key)-1], "''", "'", -1)
+	}
+	return key
+}
+
+func (railsYAML) Write(f *File) ([]byte, error) {
+	if f.Locale == "" {
+		return nil, errors.New("yml files need a locale code as their root key")
+	}
+	root, err := buildTree(f.Entries)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := bytes.NewBuffer(nil)
+	fmt.Fprintf(buf, "%s:\n", yamlKeyString(f.Locale))
+	for _, n := range root.children {
+		writeYAMLNode(buf, n, "  ", "  "+yamlKeyString(n.name)+":")
+	}
+	return buf.Bytes(), nil
+}
+
+// writeYAMLNode writes the node, with head as the start of its first line, i.e. the key or the dash of list elements.
+func writeYAMLNode(buf *bytes.Buffer, n *node, indent, head string) {
+	if n.entry != nil && n.entry.Comment != "" {
+		for _, line := range strings.Split(n.entry.Comment, "\n") {
+			fmt.Fprintf(buf, "%s# %s\n", indent, line)
+		}
+	}
+
+	switch {
+	case n.entry != nil && n.entry.Plural == nil:
+		fmt.Fprintf(buf, "%s %s\n", head, jsonString(n.entry.Value))
+	case n.entry != nil:
+		fmt.Fprintf(buf, "%s\n", head)
+		for _, form := range n.entry.PluralForms() {
+			fmt.Fprintf(buf, "%s  %s: %s\n", indent, yamlKeyString(form), jsonString(n.entry.Plural[form]))
+		}
+	case n.list:
+		fmt.Fprintf(buf, "%s\n", head)
+		for _, c := range n.children {
+			writeYAMLNode(buf, c, indent+"  ", indent+"  -")
+		}
+	case len(n.children) == 0:
+		fmt.Fprintf(buf, "%s {}\n", head)
+	default:
+		fmt.Fprintf(buf, "%s\n", head)
+		for _, c := range n.children {
+			writeYAMLNode(buf, c, indent+"  ", indent+"  "+yamlKeyString(c.name)+":")
+		}
+	}
+}
+
+var plainYAMLKeyRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// yamlSpecialWords are read as booleans or null by YAML 1.1 parsers if not quoted.
+var yamlSpecialWords = map[string]bool{
+	"y": true, "n": true, "yes": true, "no": true, "on": true, "off": true, "true": true, "false": true, "null": true,
+}
+
+func yamlKeyString(key string) string {
+	if plainYAMLKeyRegexp.MatchString(key) && !yamlSpecialWords[strings.ToLower(key)] {
+		return key
+	}
+	// JSON strings are valid double quoted YAML strings
+	return jsonString(key)
+}
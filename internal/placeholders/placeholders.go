@@ -27,6 +27,11 @@ func ContainsTagPlaceholder(s string) bool {
 	return tagPlaceholder.MatchString(s)
 }
 
+// FindAll returns the placeholders in s, e.g. "<locale_code>", in the order they occur.
+func FindAll(s string) []string {
+	return anyPlaceholderRegexp.FindAllString(s, -1)
+}
+
 func ToGlobbingPattern(s string) string {
 	path := anyPlaceholderRegexp.ReplaceAllString(s, "*")
 	baseName := filepath.Base(s)
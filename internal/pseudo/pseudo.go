@@ -0,0 +1,101 @@
+// Package pseudo pseudo-localizes translations for testing, to find hard-coded strings and layouts that break with
+// longer texts or other scripts. Letters get accents, texts are made longer and wrapped in brackets, e.g. "Hello" becomes
+// "[Ĥéļļö ~~]". Placeholders, HTML tags and entities are left untouched.
+package pseudo
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/phrase/phraseapp-client/internal/interpolation"
+)
+
+// Expansion is the share by which texts are made longer, as translations often are longer than the English source.
+const Expansion = 0.4
+
+var accents = map[rune]rune{
+	'A': 'Å', 'B': 'Ɓ', 'C': 'Ç', 'D': 'Đ', 'E': 'É', 'F': 'Ƒ', 'G': 'Ĝ', 'H': 'Ĥ', 'I': 'Î', 'J': 'Ĵ', 'K': 'Ķ', 'L': 'Ļ',
+	'M': 'Ṁ', 'N': 'Ñ', 'O': 'Ö', 'P': 'Þ', 'Q': 'Ǫ', 'R': 'Ŕ', 'S': 'Š', 'T': 'Ţ', 'U': 'Û', 'V': 'Ṽ', 'W': 'Ŵ', 'X': 'Ẋ',
+	'Y': 'Ý', 'Z': 'Ž',
+	'a': 'á', 'b': 'ƀ', 'c': 'ç', 'd': 'ð', 'e': 'é', 'f': 'ƒ', 'g': 'ĝ', 'h': 'ĥ', 'i': 'î', 'j': 'ĵ', 'k': 'ķ', 'l': 'ļ',
+	'm': 'ɱ', 'n': 'ñ', 'o': 'ö', 'p': 'þ', 'q': 'ǫ', 'r': 'ŕ', 's': 'š', 't': 'ţ', 'u': 'û', 'v': 'ṽ', 'w': 'ŵ', 'x': 'ẋ',
+	'y': 'ý', 'z': 'ž',
+}
+
+var markupRegexp = regexp.MustCompile(`<[^<>]+>|&#?\w+;`)
+
+// span is a part of a translation that is kept as it is.
+type span struct {
+	start, end int
+	// options are the messages of ICU arguments in the span, which are pseudo-localized themselves.
+	options []interpolation.Option
+}
+
+// Localize returns the pseudo-localized version of s. Empty strings are kept empty.
+func Localize(s string) string {
+	if s == "" {
+		return s
+	}
+	text, letters := transform(s)
+	if letters == 0 {
+		return "[" + text + "]"
+	}
+	return "[" + text + " " + strings.Repeat("~", int(float64(letters)*Expansion+0.5)) + "]"
+}
+
+// transform accents the letters of s outside of the kept spans and returns the number of characters transformed.
+func transform(s string) (string, int) {
+	var b strings.Builder
+	count := 0
+	pos := 0
+	for _, sp := range keptSpans(s) {
+		count += accent(&b, s[pos:sp.start])
+		pos = sp.start
+		for _, o := range sp.options {
+			b.WriteString(s[pos:o.Start])
+			text, n := transform(s[o.Start:o.End])
+			b.WriteString(text)
+			count += n
+			pos = o.End
+		}
+		b.WriteString(s[pos:sp.end])
+		pos = sp.end
+	}
+	count += accent(&b, s[pos:])
+	return b.String(), count
+}
+
+func accent(b *strings.Builder, s string) int {
+	for _, r := range s {
+		if accented, found := accents[r]; found {
+			r = accented
+		}
+		b.WriteRune(r)
+	}
+	return utf8.RuneCountInString(s)
+}
+
+// keptSpans returns the placeholders and markup in s in order, without overlaps.
+func keptSpans(s string) []span {
+	spans := []span{}
+	for _, p := range interpolation.Find(s) {
+		spans = append(spans, span{start: p.Start, end: p.End, options: p.Options})
+	}
+	for _, m := range markupRegexp.FindAllStringIndex(s, -1) {
+		spans = append(spans, span{start: m[0], end: m[1]})
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	kept := []span{}
+	end := 0
+	for _, sp := range spans {
+		if sp.start < end {
+			continue
+		}
+		kept = append(kept, sp)
+		end = sp.end
+	}
+	return kept
+}
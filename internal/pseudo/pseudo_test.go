@@ -0,0 +1,19 @@
+package pseudo
+
+import "testing"
+
+func TestLocalize(t *testing.T) {
+	for s, expected := range map[string]string{
+		"":                            "",
+		"Hello":                       "[Ĥéļļö ~~]",
+		"Hello %{name}!":              "[Ĥéļļö %{name}! ~~~]",
+		"<b>Save</b> {{count}} files": "[<b>Šáṽé</b> {{count}} ƒîļéš ~~~~]",
+		"%1$s &amp; %2$d":             "[%1$s &amp; %2$d ~]",
+		"{count}":                     "[{count}]",
+		"{n, plural, one {# file} other {# files}}": "[{n, plural, one {# ƒîļé} other {# ƒîļéš}} ~~~~~]",
+	} {
+		if got := Localize(s); got != expected {
+			t.Errorf("%q: expected %q, got %q", s, expected, got)
+		}
+	}
+}
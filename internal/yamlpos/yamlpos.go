@@ -0,0 +1,154 @@
+// Package yamlpos finds the lines and columns of keys in YAML documents, as the YAML parser doesn't expose them. Only
+// block style mappings and sequences are indexed, the content of flow style collections and of block scalars is not.
+package yamlpos
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Position is a 1-based line and column in a YAML document.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// Positions maps the paths of keys and sequence items to their positions. Paths are built from keys joined with dots
+// and sequence indexes in brackets, e.g. "phrase.push.sources[0].file".
+type Positions map[string]Position
+
+type entry struct {
+	indent int
+	seq    bool
+	path   string
+}
+
+// Index returns the positions of all keys and sequence items in content.
+func Index(content []byte) Positions {
+	positions := Positions{}
+	counters := map[string]int{} // next index of the sequence at a path
+	stack := []entry{}
+	blockIndent := -1 // lines indented deeper than this belong to a block scalar
+
+	top := func() string {
+		if len(stack) == 0 {
+			return ""
+		}
+		return stack[len(stack)-1].path
+	}
+
+	for i, line := range strings.Split(string(content), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if blockIndent >= 0 {
+			if indent > blockIndent {
+				continue
+			}
+			blockIndent = -1
+		}
+		if strings.HasPrefix(trimmed, "---") || strings.HasPrefix(trimmed, "...") {
+			stack = stack[:0]
+			continue
+		}
+
+		col, rest := indent, trimmed
+		for rest == "-" || strings.HasPrefix(rest, "- ") {
+			stack = pop(stack, col, true)
+			parent := top()
+			path := fmt.Sprintf("%s[%d]", parent, counters[parent])
+			counters[parent]++
+			positions[path] = Position{Line: i + 1, Column: col + 1}
+			stack = append(stack, entry{indent: col, seq: true, path: path})
+
+			after := strings.TrimLeft(rest[1:], " ")
+			col += len(rest) - len(after)
+			rest = after
+		}
+
+		key, value, ok := splitKey(rest)
+		if !ok {
+			continue
+		}
+
+		stack = pop(stack, col, false)
+		path := key
+		if parent := top(); parent != "" {
+			path = parent + "." + key
+		}
+		positions[path] = Position{Line: i + 1, Column: col + 1}
+		delete(counters, path)
+		stack = append(stack, entry{indent: col, path: path})
+
+		if strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">") {
+			blockIndent = col
+		}
+	}
+	return positions
+}
+
+// pop removes the entries that can't be the parent of a key or sequence item at the given column. A sequence may be
+// indented as deep as the key it belongs to, so keys at the same column are kept for sequence items.
+func pop(stack []entry, col int, seq bool) []entry {
+	for len(stack) > 0 {
+		e := stack[len(stack)-1]
+		if e.indent < col || (e.indent == col && seq && !e.seq) {
+			break
+		}
+		stack = stack[:len(stack)-1]
+	}
+	return stack
+}
+
+// splitKey splits a line of a block mapping into key and value. Lines not starting with a key return false.
+func splitKey(s string) (key, value string, ok bool) {
+	if s == "" || strings.ContainsAny(s[:1], "{[&*!|>%@`") {
+		return "", "", false
+	}
+
+	end := -1
+	if q := s[0]; q == '"' || q == '\'' {
+		closing := strings.IndexByte(s[1:], q)
+		if closing < 0 {
+			return "", "", false
+		}
+		key, end = s[1:closing+1], closing+2
+		if end >= len(s) || s[end] != ':' {
+			return "", "", false
+		}
+	} else {
+		for i := 0; i < len(s); i++ {
+			if s[i] == ':' && (i+1 == len(s) || s[i+1] == ' ') {
+				end = i
+				break
+			}
+			if s[i] == ' ' && i+1 < len(s) && s[i+1] == '#' {
+				break
+			}
+		}
+		if end < 0 {
+			return "", "", false
+		}
+		key = strings.TrimSpace(s[:end])
+	}
+	return key, strings.TrimSpace(s[end+1:]), true
+}
+
+// Locate returns the position of path, or of its closest ancestor if path itself isn't indexed, e.g. for keys in
+// flow style mappings.
+func (p Positions) Locate(path string) (Position, bool) {
+	for path != "" {
+		if pos, found := p[path]; found {
+			return pos, true
+		}
+
+		cut := strings.LastIndexAny(path, ".[")
+		if cut < 0 {
+			break
+		}
+		path = path[:cut]
+	}
+	return Position{}, false
+}
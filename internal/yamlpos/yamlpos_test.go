@@ -0,0 +1,70 @@
+package yamlpos
+
+import "testing"
+
+func TestIndex(t *testing.T) {
+	content := []byte(`# comment
+phrase:
+  access_token: "abc"
+  push:
+    sources:
+    - file: ./config/<locale_code>.yml
+      params:
+        file_format: yml
+    -   file: ./other.yml
+  pull:
+    targets:
+      -
+        file: ./out/<locale_code>.yml
+      - file: |
+          not: a key
+        project_id: x
+  "quoted key": 1
+  defaults: {pull: {a: b}}
+`)
+
+	tests := map[string]Position{
+		"phrase":                                    {2, 1},
+		"phrase.access_token":                       {3, 3},
+		"phrase.push.sources":                       {5, 5},
+		"phrase.push.sources[0]":                    {6, 5},
+		"phrase.push.sources[0].file":               {6, 7},
+		"phrase.push.sources[0].params.file_format": {8, 9},
+		"phrase.push.sources[1].file":               {9, 9},
+		"phrase.pull.targets[0]":                    {12, 7},
+		"phrase.pull.targets[0].file":               {13, 9},
+		"phrase.pull.targets[1].file":               {14, 9},
+		"phrase.pull.targets[1].project_id":         {16, 9},
+		"phrase.quoted key":                         {17, 3},
+		"phrase.defaults":                           {18, 3},
+	}
+
+	positions := Index(content)
+	for path, exp := range tests {
+		if got, found := positions[path]; !found || got != exp {
+			t.Errorf("%s: expected %v, got %v (found: %t)", path, exp, got, found)
+		}
+	}
+	if _, found := positions["phrase.pull.targets[1].file.not"]; found {
+		t.Errorf("expected block scalars not to be indexed")
+	}
+}
+
+func TestLocate(t *testing.T) {
+	positions := Index([]byte("phrase:\n  defaults: {pull: {a: b}}\n  push:\n    sources:\n      - file: a\n"))
+
+	tests := map[string]Position{
+		"phrase.defaults.pull.a":             {2, 3},
+		"phrase.push.sources[0].params.tags": {5, 7},
+		"phrase.push.sources[3]":             {4, 5},
+	}
+	for path, exp := range tests {
+		if got, found := positions.Locate(path); !found || got != exp {
+			t.Errorf("%s: expected %v, got %v (found: %t)", path, exp, got, found)
+		}
+	}
+
+	if _, found := positions.Locate("other.key"); found {
+		t.Errorf("expected unknown paths not to be found")
+	}
+}
@@ -0,0 +1,480 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	ct "github.com/daviddengcn/go-colortext"
+	"github.com/phrase/phraseapp-client/internal/interpolation"
+	"github.com/phrase/phraseapp-client/internal/localefile"
+	"github.com/phrase/phraseapp-client/internal/print"
+	"github.com/phrase/phraseapp-client/internal/yamlpos"
+	"github.com/phrase/phraseapp-go/phraseapp"
+)
+
+type LintCommand struct {
+	phraseapp.Config
+	DefaultLocale string `cli:"opt --default-locale desc='Code of the locale to compare placeholders with, defaults to the default locale of the project'"`
+}
+
+func (cmd *LintCommand) Run() error {
+	sources, err := SourcesFromConfig(cmd.Config)
+	if err != nil {
+		return err
+	}
+
+	if err := sources.Validate(); err != nil {
+		return err
+	}
+
+	defaultLocales := cmd.defaultLocales(sources)
+
+	results := []*lintResult{}
+	for _, source := range sources {
+		r, err := source.lint(defaultLocales[source.ProjectID])
+		if err != nil {
+			return err
+		}
+		results = append(results, r...)
+	}
+
+	errors, warnings := printLintResults(results)
+	switch {
+	case errors > 0:
+		return fmt.Errorf("found %s and %s", pluralize(errors, "error"), pluralize(warnings, "warning"))
+	case warnings > 0:
+		print.WithColor(ct.Yellow, "found %s", pluralize(warnings, "warning"))
+	default:
+		print.Success("checked %s, no problems found", pluralize(len(results), "file"))
+	}
+	return nil
+}
+
+// defaultLocales returns the code of the locale to compare placeholders with by project ID. Without --default-locale
+// the default locales of the projects are looked up, which is skipped with a notice if that fails, e.g. offline.
+func (cmd *LintCommand) defaultLocales(sources Sources) map[string]string {
+	codes := map[string]string{}
+	if cmd.DefaultLocale != "" {
+		for _, projectID := range sources.ProjectIds() {
+			codes[projectID] = cmd.DefaultLocale
+		}
+		return codes
+	}
+
+	err := func() error {
+		client, err := newClient(cmd.Config.Credentials, cmd.Config.Debug)
+		if err != nil {
+			return err
+		}
+		for _, projectID := range sources.ProjectIds() {
+			locales, err := RemoteLocales(client, LocaleCacheKey{ProjectID: projectID})
+			if err != nil {
+				return err
+			}
+			for _, locale := range locales {
+				if locale.Default {
+					codes[projectID] = locale.Code
+				}
+			}
+		}
+		return nil
+	}()
+	if err != nil {
+		print.WithColor(ct.Yellow, "Placeholders are not compared, the default locale is unknown (%s). Use --default-locale to set it.", err)
+	}
+	return codes
+}
+
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+const (
+	lintError   = "error"
+	lintWarning = "warning"
+)
+
+// lintProblem is a problem found in a locale file, at the line of the key it concerns if that is known.
+type lintProblem struct {
+	line     int
+	severity string
+	message  string
+}
+
+// lintResult holds the problems of a locale file.
+type lintResult struct {
+	LocaleFile *LocaleFile
+	Format     string
+	// Code is the locale of the file, from the path or the file itself.
+	Code string
+	// Unsupported is set if the file format can't be checked.
+	Unsupported bool
+
+	content  []byte
+	file     *localefile.File
+	problems []*lintProblem
+}
+
+func (r *lintResult) addf(line int, severity, format string, args ...interface{}) {
+	r.problems = append(r.problems, &lintProblem{line: line, severity: severity, message: fmt.Sprintf(format, args...)})
+}
+
+func (source *Source) lint(defaultLocale string) ([]*lintResult, error) {
+	localeFiles, err := source.LocaleFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	format := source.GetFileFormat()
+	results := []*lintResult{}
+	for _, localeFile := range localeFiles {
+		r := &lintResult{LocaleFile: localeFile, Format: format, Code: localeFile.Code}
+		results = append(results, r)
+		if !localefile.Supported(format) {
+			r.Unsupported = true
+			continue
+		}
+
+		if r.content, err = ioutil.ReadFile(localeFile.Path); err != nil {
+			return nil, err
+		}
+		r.check()
+	}
+
+	if defaultLocale == "" {
+		return results, nil
+	}
+	for _, r := range results {
+		if d := defaultLintResult(results, r, defaultLocale); d != nil && d != r {
+			r.comparePlaceholders(d)
+		}
+	}
+	return results, nil
+}
+
+// defaultLintResult returns the file of the default locale to compare r with, preferring one with the same tag.
+func defaultLintResult(results []*lintResult, r *lintResult, defaultLocale string) *lintResult {
+	var found *lintResult
+	for _, candidate := range results {
+		if candidate.file == nil || candidate.Code != defaultLocale {
+			continue
+		}
+		if candidate.LocaleFile.Tag == r.LocaleFile.Tag {
+			return candidate
+		}
+		if found == nil {
+			found = candidate
+		}
+	}
+	return found
+}
+
+// check parses the file and checks it for problems not depending on other files.
+func (r *lintResult) check() {
+	f, err := localefile.Parse(r.Format, r.content)
+	if err != nil {
+		r.addf(syntaxErrorLine(err, r.content), lintError, "syntax error: %s", err)
+		return
+	}
+	r.file = f
+	if r.Code == "" {
+		r.Code = f.Locale
+	}
+	if r.Code == "" {
+		r.Code = r.LocaleFile.Name
+	}
+
+	categories := localefile.PluralCategoriesFor(r.Code)
+	seen := map[string]int{}
+	for _, e := range f.Entries {
+		seen[e.Key]++
+		line := r.keyLine(e.Key, seen[e.Key])
+		if seen[e.Key] == 2 {
+			r.addf(line, lintError, "duplicate key %q, first defined on line %d", e.Key, r.keyLine(e.Key, 1))
+		}
+
+		if e.Plural == nil {
+			if e.Value == "" {
+				r.addf(line, lintWarning, "empty value for %q", e.Key)
+			}
+			r.checkICUPlurals(line, e.Key, e.Value, categories)
+			continue
+		}
+
+		for _, form := range e.PluralForms() {
+			if e.Plural[form] == "" {
+				r.addf(line, lintWarning, "empty plural form %q of %q", form, e.Key)
+			}
+			r.checkICUPlurals(line, e.Key, e.Plural[form], categories)
+		}
+		if missing := r.missingPluralForms(e, categories); len(missing) > 0 {
+			r.addf(line, lintError, "%q is missing the plural forms %s of %s", e.Key, strings.Join(missing, ", "), r.Code)
+		}
+	}
+}
+
+var npluralsRegexp = regexp.MustCompile(`nplurals\s*=\s*(\d+)`)
+
+// missingPluralForms returns the CLDR plural categories of the locale e doesn't have. Gettext files number their
+// forms, for them the number of forms given in the Plural-Forms header is checked.
+func (r *lintResult) missingPluralForms(e *localefile.Entry, categories []string) []string {
+	missing := []string{}
+	if r.Format == "gettext" {
+		m := npluralsRegexp.FindStringSubmatch(r.file.Attributes["header"])
+		if m == nil {
+			return missing
+		}
+		n, _ := strconv.Atoi(m[1])
+		for i := 0; i < n; i++ {
+			if _, found := e.Plural[strconv.Itoa(i)]; !found {
+				missing = append(missing, fmt.Sprintf("[%d]", i))
+			}
+		}
+		return missing
+	}
+
+	for _, category := range categories {
+		if _, found := e.Plural[category]; !found {
+			missing = append(missing, category)
+		}
+	}
+	return missing
+}
+
+// checkICUPlurals checks the ICU plural arguments in value for missing categories. As exact matches like =1 may cover
+// a category, these are only warnings.
+func (r *lintResult) checkICUPlurals(line int, key, value string, categories []string) {
+	for _, p := range interpolation.Find(value) {
+		if !strings.HasSuffix(p.Name, ", plural}") {
+			continue
+		}
+		selectors := map[string]bool{}
+		for _, o := range p.Options {
+			selectors[o.Selector] = true
+		}
+		if !selectors["other"] {
+			r.addf(line, lintError, "%s in %q has no other option", p.Name, key)
+		}
+		missing := []string{}
+		for _, category := range categories {
+			if category != "other" && !selectors[category] {
+				missing = append(missing, category)
+			}
+		}
+		if len(missing) > 0 {
+			r.addf(line, lintWarning, "%s in %q is missing the plural forms %s of %s", p.Name, key, strings.Join(missing, ", "), r.Code)
+		}
+	}
+}
+
+// comparePlaceholders reports the placeholders of keys that differ from the ones in the file of the default locale.
+func (r *lintResult) comparePlaceholders(d *lintResult) {
+	if r.file == nil {
+		return
+	}
+
+	expected := map[string][]string{}
+	for _, e := range d.file.Entries {
+		if _, found := expected[e.Key]; !found {
+			expected[e.Key] = entryPlaceholders(e)
+		}
+	}
+
+	compared := map[string]bool{}
+	for _, e := range r.file.Entries {
+		want, found := expected[e.Key]
+		if !found || compared[e.Key] || entryEmpty(e) {
+			continue
+		}
+		compared[e.Key] = true
+
+		got := entryPlaceholders(e)
+		line := r.keyLine(e.Key, 1)
+		if missing := subtractStrings(want, got); len(missing) > 0 {
+			r.addf(line, lintError, "%q is missing the placeholders %s of %s", e.Key, strings.Join(missing, ", "), d.Code)
+		}
+		if extra := subtractStrings(got, want); len(extra) > 0 {
+			r.addf(line, lintError, "%q has the placeholders %s not used in %s", e.Key, strings.Join(extra, ", "), d.Code)
+		}
+	}
+}
+
+// entryPlaceholders returns the names of the placeholders of all translations of e, sorted and without duplicates.
+func entryPlaceholders(e *localefile.Entry) []string {
+	values := []string{e.Value}
+	if e.Plural != nil {
+		values = values[:0]
+		for _, value := range e.Plural {
+			values = append(values, value)
+		}
+	}
+
+	seen := map[string]bool{}
+	names := []string{}
+	for _, value := range values {
+		for _, name := range interpolation.Names(value) {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func entryEmpty(e *localefile.Entry) bool {
+	for _, value := range e.Plural {
+		if value != "" {
+			return false
+		}
+	}
+	return e.Value == ""
+}
+
+// subtractStrings returns the elements of a not in b.
+func subtractStrings(a, b []string) []string {
+	in := map[string]bool{}
+	for _, s := range b {
+		in[s] = true
+	}
+	result := []string{}
+	for _, s := range a {
+		if !in[s] {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+var errorLineRegexp = regexp.MustCompile(`line (\d+)`)
+
+// syntaxErrorLine returns the line of a parse error, or 0 if it is unknown.
+func syntaxErrorLine(err error, content []byte) int {
+	switch err := err.(type) {
+	case *json.SyntaxError:
+		return strings.Count(string(content[:err.Offset]), "\n") + 1
+	case *xml.SyntaxError:
+		return err.Line
+	}
+	if m := errorLineRegexp.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return line
+	}
+	return 0
+}
+
+var listIndexRegexp = regexp.MustCompile(`\[\d+\]`)
+
+// keyLine returns the line the n-th occurrence of key is defined on, or 0 if it can't be found. The key, or each
+// segment of nested keys, is searched for as it is written in the file. For Rails files the YAML positions are used if
+// that fails, e.g. for quoted keys.
+func (r *lintResult) keyLine(key string, n int) int {
+	needles := []string{strconv.Quote(key)}
+	switch r.Format {
+	case "yml":
+		needles = append([]string{r.file.Locale}, strings.Split(key, ".")...)
+		for i := range needles {
+			needles[i] = listIndexRegexp.ReplaceAllString(needles[i], "") + ":"
+		}
+	case "nested_json":
+		needles = strings.Split(listIndexRegexp.ReplaceAllString(key, ""), ".")
+		for i := range needles {
+			needles[i] = strconv.Quote(needles[i])
+		}
+	case "properties":
+		needles = []string{key}
+	case "xml":
+		needles = []string{`name="` + listIndexRegexp.ReplaceAllString(key, "") + `"`}
+	case "gettext":
+		if i := strings.Index(key, "\x04"); i >= 0 {
+			needles = []string{strconv.Quote(key[:i]), strconv.Quote(key[i+1:])}
+		}
+	}
+
+	line := findLine(strings.Split(string(r.content), "\n"), needles, n)
+	if line == 0 && r.Format == "yml" && n == 1 {
+		if pos, found := yamlpos.Index(r.content).Locate(r.file.Locale + "." + key); found {
+			return pos.Line
+		}
+	}
+	return line
+}
+
+// findLine returns the 1-based line of the n-th occurrence of the last needle, each needle searched for starting at
+// the line of the one before it.
+func findLine(lines, needles []string, n int) int {
+	line := 0
+	for i, needle := range needles {
+		occurrences := 1
+		if i == len(needles)-1 {
+			occurrences = n
+		}
+		for ; line < len(lines); line++ {
+			if strings.Contains(lines[line], needle) {
+				occurrences--
+				if occurrences == 0 {
+					break
+				}
+			}
+		}
+		if line == len(lines) {
+			return 0
+		}
+		if i < len(needles)-1 {
+			line++
+		}
+	}
+	return line + 1
+}
+
+// printLintResults prints the problems grouped by file and sorted by line, and returns the number of errors and
+// warnings.
+func printLintResults(results []*lintResult) (errors, warnings int) {
+	sort.SliceStable(results, func(i, j int) bool { return results[i].LocaleFile.RelPath() < results[j].LocaleFile.RelPath() })
+
+	unsupported := []string{}
+	for _, r := range results {
+		if r.Unsupported {
+			unsupported = append(unsupported, r.LocaleFile.RelPath())
+			continue
+		}
+		if len(r.problems) == 0 {
+			continue
+		}
+
+		sort.SliceStable(r.problems, func(i, j int) bool { return r.problems[i].line < r.problems[j].line })
+		fmt.Println(r.LocaleFile.RelPath())
+		for _, p := range r.problems {
+			line := ""
+			if p.line > 0 {
+				line = strconv.Itoa(p.line)
+			}
+			fmt.Printf("  %4s  %-7s  %s\n", line, p.severity, p.message)
+			if p.severity == lintError {
+				errors++
+			} else {
+				warnings++
+			}
+		}
+		fmt.Println()
+	}
+
+	if len(unsupported) > 0 {
+		fmt.Println("Not checked, the format is not supported:")
+		for _, path := range unsupported {
+			fmt.Printf("\t%s\n", path)
+		}
+		fmt.Println()
+	}
+	return errors, warnings
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func lintContent(format, code, content string) *lintResult {
+	r := &lintResult{LocaleFile: &LocaleFile{Code: code}, Format: format, Code: code, content: []byte(content)}
+	r.check()
+	return r
+}
+
+func lintMessages(r *lintResult) []string {
+	messages := []string{}
+	for _, p := range r.problems {
+		messages = append(messages, p.severity+" "+p.message)
+	}
+	return messages
+}
+
+func lintLines(r *lintResult) []int {
+	lines := []int{}
+	for _, p := range r.problems {
+		lines = append(lines, p.line)
+	}
+	return lines
+}
+
+func TestLintCheck(t *testing.T) {
+	tests := []struct {
+		format  string
+		code    string
+		content string
+		exp     []string
+		lines   []int
+	}{
+		{
+			"yml", "ru", "ru:\n  a: A\n  apples:\n    one: apple\n    other: apples\n  b: \"\"\n",
+			[]string{
+				`error "apples" is missing the plural forms few, many of ru`,
+				`warning empty value for "b"`,
+			},
+			[]int{3, 6},
+		},
+		{
+			"yml", "en", "en:\n  a: A\n  b: B\n  a: again\n",
+			[]string{`error syntax error: line 4: duplicate key "a", first defined on line 2`},
+			[]int{4},
+		},
+		{
+			"properties", "en", "a=A\nb=B\na=again\n",
+			[]string{`error duplicate key "a", first defined on line 1`},
+			[]int{3},
+		},
+		{
+			"nested_json", "en", "{\n  \"a\": {\n    \"b\": \"{n, plural, one {# x}}\"\n  }\n}\n",
+			[]string{`error {n, plural} in "a.b" has no other option`},
+			[]int{3},
+		},
+		{
+			"simple_json", "en", "{\n  \"a\": \"A\",\n  \"b\" \"B\"\n}\n",
+			[]string{`error syntax error: invalid character '"' after object key`},
+			[]int{3},
+		},
+		{
+			"xml", "en", "<resources>\n  <string name=\"a\">A</string>\n  <string name=\"b\">B</strin>\n</resources>\n",
+			[]string{`error syntax error: XML syntax error on line 3: element <string> closed by </strin>`},
+			[]int{3},
+		},
+		{
+			"gettext", "de", "msgid \"\"\nmsgstr \"Plural-Forms: nplurals=2; plural=(n != 1);\\n\"\n\nmsgid \"apple\"\nmsgid_plural \"apples\"\nmsgstr[0] \"Apfel\"\n",
+			[]string{`error "apple" is missing the plural forms [1] of de`},
+			[]int{4},
+		},
+		{
+			"properties", "en", "a=A\nb=B\n",
+			[]string{},
+			[]int{},
+		},
+	}
+	for _, test := range tests {
+		r := lintContent(test.format, test.code, test.content)
+		if got := lintMessages(r); !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("%s: expected %q, got %q", test.format, test.exp, got)
+		}
+		if got := lintLines(r); !reflect.DeepEqual(got, test.lines) {
+			t.Errorf("%s: expected lines %v, got %v", test.format, test.lines, got)
+		}
+	}
+}
+
+func TestLintComparePlaceholders(t *testing.T) {
+	d := lintContent("yml", "en", "en:\n  hello: \"Hello %{name}\"\n  items:\n    one: \"one item\"\n    other: \"%{count} items\"\n  printf: \"%1$s of %2$d\"\n  missing: \"\"\n")
+	r := lintContent("yml", "de", "de:\n  hello: \"Hallo {{name}}\"\n  items:\n    one: \"%{count} Ding\"\n    other: \"%{count} Dinge\"\n  printf: \"%2$d von %1$s\"\n  missing: \"%{x}\"\n  only_here: \"%{y}\"\n")
+	r.comparePlaceholders(d)
+
+	exp := []string{
+		`error "hello" is missing the placeholders %{name} of en`,
+		`error "hello" has the placeholders {{name}} not used in en`,
+		`error "missing" has the placeholders %{x} not used in en`,
+	}
+	if got := lintMessages(r); !reflect.DeepEqual(got, exp) {
+		t.Errorf("expected %q, got %q", exp, got)
+	}
+	if got := lintLines(r); !reflect.DeepEqual(got, []int{2, 2, 7}) {
+		t.Errorf("expected lines [2 2 7], got %v", got)
+	}
+}
+
+func TestDefaultLintResult(t *testing.T) {
+	en := lintContent("yml", "en", "en:\n  a: A\n")
+	enTagged := lintContent("yml", "en", "en:\n  a: A\n")
+	enTagged.LocaleFile.Tag = "web"
+	de := lintContent("yml", "de", "de:\n  a: A\n")
+	de.LocaleFile.Tag = "web"
+	results := []*lintResult{en, enTagged, de}
+
+	if got := defaultLintResult(results, de, "en"); got != enTagged {
+		t.Errorf("expected the default locale file with the same tag")
+	}
+	if got := defaultLintResult(results, en, "fr"); got != nil {
+		t.Errorf("expected no file for an unknown default locale, got %v", got)
+	}
+}
+
+func TestFindLine(t *testing.T) {
+	lines := []string{"en:", "  a:", "    b: x", "  c:", "    b: y", "    b: z"}
+	tests := []struct {
+		needles []string
+		n       int
+		exp     int
+	}{
+		{[]string{"en:", "a:", "b:"}, 1, 3},
+		{[]string{"en:", "c:", "b:"}, 1, 5},
+		{[]string{"en:", "c:", "b:"}, 2, 6},
+		{[]string{"en:", "d:"}, 1, 0},
+	}
+	for _, test := range tests {
+		if got := findLine(lines, test.needles, test.n); got != test.exp {
+			t.Errorf("%v (%d): expected line %d, got %d", test.needles, test.n, test.exp, got)
+		}
+	}
+}
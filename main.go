@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"runtime/debug"
+	"strconv"
+	"strings"
 
 	"github.com/phrase/phraseapp-client/cli"
 	"github.com/phrase/phraseapp-client/internal/print"
@@ -26,7 +28,6 @@ func main() {
 }
 
 func Run() {
-	var cfg *phraseapp.Config
 	defer func() {
 		if recovered := recover(); recovered != nil {
 			if Debug {
@@ -40,19 +41,36 @@ func Run() {
 	phraseapp.ClientVersion = PHRASEAPP_CLIENT_VERSION
 	updateChecker.Check()
 
-	cfg, err := phraseapp.ReadConfig()
+	cfg, err := ReadConfig()
+	if err != nil {
+		if !validatesConfig(os.Args[1:]) {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(2)
+		}
+		// config validate reports the problems of the config itself, so it has to run without it.
+		cfg = &Config{Config: new(phraseapp.Config)}
+	}
+
+	args, err := extractGlobalOptions(os.Args[1:], cfg)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 		os.Exit(2)
 	}
+	if err := cfg.applyProfile(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(2)
+	}
+	transportConfig = cfg.Transport
+	cacheConfig = cfg.Cache
+	branchNameConfig = cfg.BranchNames
 
-	r, err := router(cfg)
+	r, err := newRouter(cfg)
 	if err != nil {
 		print.Error(err)
 		os.Exit(3)
 	}
 
-	switch err := r.RunWithArgs(); err {
+	switch err := r.Run(args...); err {
 	case cli.ErrorHelpRequested, cli.ErrorNoRoute:
 		os.Exit(1)
 	case nil:
@@ -62,3 +80,131 @@ func Run() {
 		os.Exit(1)
 	}
 }
+
+// newRouter creates a router with all commands, including the ones depending on settings only known to the client,
+// and registers the aliases from the config.
+func newRouter(cfg *Config) (*cli.Router, error) {
+	r, err := router(cfg.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range globalOptions {
+		r.RegisterGlobalOption(globalOptionUsage(o.name, o.value), o.description)
+	}
+
+	r.Register("shell", &ShellCommand{Config: *cfg.Config, cfg: cfg}, "Start an interactive shell to run commands in the context of a project and branch.")
+	r.Register("config/validate", &ConfigValidateCommand{cfg: cfg}, "Check the config file for errors and report them with their line and column.")
+	r.Register("config/show", &ConfigShowCommand{Config: *cfg.Config, cfg: cfg}, "Show the effective configuration and where each value comes from.")
+	r.Register("config/schema", &ConfigSchemaCommand{}, "Print a JSON Schema of the config file, e.g. for validation and completion in editors.")
+	r.Register("auth/login", &AuthLoginCommand{cfg: cfg}, "Store an access token in the credentials store, so that it doesn't have to be kept in config files.")
+	r.Register("auth/logout", &AuthLogoutCommand{cfg: cfg}, "Remove an access token from the credentials store.")
+	r.Register("auth/status", &AuthStatusCommand{cfg: cfg}, "Show the credentials store and the access tokens stored in it.")
+	r.Register("cache/clear", &CacheClearCommand{cfg: cfg.Cache}, "Remove all API responses from the cache.")
+	r.Register("cache/stats", &CacheStatsCommand{cfg: cfg.Cache}, "Show location, number of entries and size of the API response cache.")
+
+	for name, command := range cfg.Aliases {
+		if err := r.RegisterAlias(name, command); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// validatesConfig reports whether args run the config validate command.
+func validatesConfig(args []string) bool {
+	commands := []string{}
+	for i := 0; i < len(args); i++ {
+		switch {
+		case isGlobalValueOption(args[i]):
+			i++
+		case !strings.HasPrefix(args[i], "-"):
+			commands = append(commands, args[i])
+		}
+	}
+	return len(commands) >= 2 && commands[0] == "config" && commands[1] == "validate"
+}
+
+// globalOptions are the options applying to all commands, which extractGlobalOptions removes from the arguments.
+// Options with a value name take a value.
+var globalOptions = []struct {
+	name        string
+	value       string
+	description string
+}{
+	{"--profile", "name", "Use the settings of the named profile from the config, overrides PHRASEAPP_PROFILE"},
+	{"--max-retries", "n", "Number of times a request failing with a transient error is retried"},
+	{"--retry-timeout", "duration", `Maximum time spent retrying a request apart from rate limit waits, as duration like "90s" or number of seconds`},
+	{"--cache", "", `Cache API responses on disk, same as "cache: true" in the config file`},
+	{"--record", "dir", "Store all requests and responses as fixtures in dir, with credentials scrubbed"},
+	{"--replay", "dir", "Answer requests with the responses recorded in dir instead of sending them"},
+}
+
+// globalOptionUsage returns the option as given on the command line, e.g. "--profile <name>".
+func globalOptionUsage(name, value string) string {
+	if value == "" {
+		return name
+	}
+	return name + " <" + value + ">"
+}
+
+func isGlobalValueOption(name string) bool {
+	for _, o := range globalOptions {
+		if o.name == name {
+			return o.value != ""
+		}
+	}
+	return false
+}
+
+// extractGlobalOptions removes the globalOptions from args and applies them.
+func extractGlobalOptions(args []string, cfg *Config) ([]string, error) {
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		name, value := args[i], ""
+		if idx := strings.Index(name, "="); idx >= 0 {
+			name, value = name[:idx], name[idx+1:]
+		} else if isGlobalValueOption(name) {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("option %s requires a value", name)
+			}
+			i++
+			value = args[i]
+		}
+
+		switch name {
+		case "--max-retries":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid value %q for %s, expected a number >= 0", value, name)
+			}
+			MaxRetries = n
+		case "--retry-timeout":
+			d, err := parseDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q for %s, expected a duration like 90s or 2m", value, name)
+			}
+			RetryTimeout = d
+		case "--cache":
+			if args[i] != name {
+				return nil, fmt.Errorf("option --cache doesn't take a value")
+			}
+			cfg.Cache.Enabled = true
+			cfg.setOrigin("cache", "flag --cache")
+		case "--record":
+			RecordDir = value
+		case "--replay":
+			ReplayDir = value
+		case "--profile":
+			cfg.Profile = value
+			cfg.setOrigin("profile", "flag --profile")
+		default:
+			remaining = append(remaining, args[i])
+		}
+	}
+
+	if RecordDir != "" && ReplayDir != "" {
+		return nil, fmt.Errorf("options --record and --replay can't be used together")
+	}
+	return remaining, nil
+}
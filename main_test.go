@@ -9,6 +9,7 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/phrase/phraseapp-client/cli"
 	"github.com/phrase/phraseapp-go/phraseapp"
@@ -320,3 +321,37 @@ func TestCLIHelp_FormatOptions(t *testing.T) {
 	// FormatOptions are ignored with regard to defaults!
 	matchDefaultExpectations(t, defaults, map[string]string{})
 }
+
+func TestExtractGlobalOptions(t *testing.T) {
+	maxRetries, retryTimeout := MaxRetries, RetryTimeout
+	defer func() { MaxRetries, RetryTimeout, RecordDir, ReplayDir = maxRetries, retryTimeout, "", "" }()
+
+	cfg := &Config{}
+	args, err := extractGlobalOptions([]string{"pull", "--max-retries", "5", "--retry-timeout=90", "--cache", "--branch", "feature"}, cfg)
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if strings.Join(args, " ") != "pull --branch feature" {
+		t.Errorf("expected global options to be removed, got %q", args)
+	}
+	if MaxRetries != 5 || RetryTimeout != 90*time.Second {
+		t.Errorf("expected 5 retries within 90s, got %d within %s", MaxRetries, RetryTimeout)
+	}
+	if !cfg.Cache.Enabled {
+		t.Errorf("expected caching to be enabled")
+	}
+
+	if _, err := extractGlobalOptions([]string{"push", "--profile", "staging"}, cfg); err != nil || cfg.Profile != "staging" {
+		t.Errorf("expected profile %q, got %q (%v)", "staging", cfg.Profile, err)
+	}
+
+	if _, err := extractGlobalOptions([]string{"--retry-timeout", "2m"}, cfg); err != nil || RetryTimeout != 2*time.Minute {
+		t.Errorf("expected timeout of 2m, got %s (%v)", RetryTimeout, err)
+	}
+
+	for _, args := range [][]string{{"--max-retries"}, {"--max-retries=-1"}, {"--retry-timeout", "soon"}, {"--cache=false"}, {"--profile"}, {"--record", "a", "--replay=b"}} {
+		if _, err := extractGlobalOptions(args, cfg); err == nil {
+			t.Errorf("%q: expected an error", args)
+		}
+	}
+}
@@ -10,9 +10,28 @@ func ApplyNonRestRoutes(r *cli.Router, cfg *phraseapp.Config) {
 
 	r.Register("push", &PushCommand{Config: *cfg}, "Upload locales to your PhraseApp project.\n  You can provide parameters supported by the uploads#create endpoint https://developers.phrase.com/api/#uploads_create\n  in your configuration (.phraseapp.yml) for each source.\n  See our configuration guide for more information https://help.phrase.com/phraseapp-for-developers/phraseapp-client/configuration#push")
 
+	r.Register("status", &StatusCommand{Config: *cfg}, "Compare the locale files of your sources with the locales in your PhraseApp project.\n  With --keys the differing keys of each file are listed.")
+
+	r.Register("lint", &LintCommand{Config: *cfg}, "Check the locale files of your sources for syntax errors, duplicate keys, empty values, missing plural forms\n  and placeholders differing from the default locale. Exits with status 1 if errors are found.")
+
+	r.Register("pseudo", &PseudoCommand{Config: *cfg}, "Write a pseudo-localized copy of a locale file of your sources to your pull targets, e.g. pseudo --from en --to en-XA.\n  Texts get accents, are made longer and wrapped in brackets, placeholders and HTML tags are kept.")
+
+	r.Register("convert", &ConvertCommand{}, "Convert locale files between formats without PhraseApp, e.g. convert --from yml --to nested_json in.yml out.json.\n  With a glob like 'locales/*.yml' as input all matching files are converted to the output directory.")
+
 	r.Register("init", &InitCommand{Config: *cfg}, "Configure your PhraseApp client.")
 
 	r.Register("upload/cleanup", &UploadCleanupCommand{Config: *cfg}, "Delete unmentioned keys for given upload")
 
+	r.Register("upload/cleanup/restore", &UploadCleanupRestoreCommand{Config: *cfg}, "Restore the keys deleted by a cleanup from its backup file")
+
+	r.Register("branch/sync/start", &BranchSyncStartCommand{Config: *cfg}, "Create a branch in all projects pushed to and wait until it is ready.")
+
+	r.Register("branch/sync/status", &BranchSyncStatusCommand{Config: *cfg}, "List the keys a branch added, changed and deleted compared to the main branch.")
+	r.Register("branch/sync/finish", &BranchSyncFinishCommand{Config: *cfg}, "Merge a branch in all projects pushed to, wait for the merge and optionally delete the branch.")
+
 	r.RegisterFunc("info", infoCommand, "Info about version and revision of this client")
+
+	r.Register("docs/generate", &DocsGenerateCommand{}, "Generate Markdown or man pages documenting all commands, their options and config keys.")
+
+	r.RegisterHidden("dev/fake-server", &DevFakeServerCommand{}, "Run a fake PhraseApp API with in-memory state for local development and tests.")
 }
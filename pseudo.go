@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/phrase/phraseapp-client/internal/localefile"
+	"github.com/phrase/phraseapp-client/internal/placeholders"
+	"github.com/phrase/phraseapp-client/internal/print"
+	"github.com/phrase/phraseapp-client/internal/pseudo"
+	"github.com/phrase/phraseapp-go/phraseapp"
+)
+
+type PseudoCommand struct {
+	phraseapp.Config
+	From string `cli:"opt --from required desc='Code of the locale to pseudo-localize, e.g. en'"`
+	To   string `cli:"opt --to required desc='Code of the pseudo locale to write, e.g. en-XA'"`
+}
+
+func (cmd *PseudoCommand) Run() error {
+	sources, err := SourcesFromConfig(cmd.Config)
+	if err != nil {
+		return err
+	}
+
+	targets, err := TargetsFromConfig(cmd.Config)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, source := range sources {
+		localeFiles, err := source.LocaleFiles()
+		if err != nil {
+			return err
+		}
+
+		format := source.GetFileFormat()
+		for _, localeFile := range localeFiles {
+			content, err := ioutil.ReadFile(localeFile.Path)
+			if err != nil {
+				return err
+			}
+			if !isSourceLocaleFile(localeFile, format, content, cmd.From) {
+				continue
+			}
+			found = true
+
+			pseudoTargets := pseudoTargetsFor(targets, source.ProjectID, format)
+			if len(pseudoTargets) == 0 {
+				return fmt.Errorf("no pull target with a locale placeholder and the format %s found for project %q to write %s to", format, source.ProjectID, cmd.To)
+			}
+			for _, target := range pseudoTargets {
+				if err := target.writePseudoLocaleFile(format, content, cmd.To, localeFile.Tag); err != nil {
+					return fmt.Errorf("%s: %s", localeFile.RelPath(), err)
+				}
+			}
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("no locale file of the sources found for %s", cmd.From)
+	}
+	return nil
+}
+
+// isSourceLocaleFile reports whether the file is one of the locale with the given code, by the placeholders of the
+// source or, if it has none, by the locale the file itself names.
+func isSourceLocaleFile(localeFile *LocaleFile, format string, content []byte, code string) bool {
+	if localeFile.Code != "" || localeFile.Name != "" {
+		return localeFile.Code == code || localeFile.Name == code
+	}
+	f, err := localefile.Parse(format, content)
+	return err == nil && f.Locale == code
+}
+
+// pseudoTargetsFor returns the pull targets of a project with the given format that have a locale placeholder to
+// write pseudo locales to.
+func pseudoTargetsFor(targets Targets, projectID, format string) Targets {
+	matching := Targets{}
+	for _, target := range targets {
+		if target.ProjectID == projectID && target.GetFormat() == format && placeholders.ContainsLocalePlaceholder(target.File) {
+			matching = append(matching, target)
+		}
+	}
+	return matching
+}
+
+// pseudoLocalize returns the content of a locale file with all translations pseudo-localized and the locale set to
+// code, for the formats naming their locale.
+func pseudoLocalize(format string, content []byte, code string) ([]byte, error) {
+	f, err := localefile.Parse(format, content)
+	if err != nil {
+		return nil, err
+	}
+
+	f.Locale = code
+	for _, e := range f.Entries {
+		e.Value = pseudo.Localize(e.Value)
+		for form, value := range e.Plural {
+			e.Plural[form] = pseudo.Localize(value)
+		}
+	}
+	return localefile.Write(format, f)
+}
+
+// writePseudoLocaleFile pseudo-localizes the content of a locale file and writes it to the path of the target for the
+// pseudo locale.
+func (target *Target) writePseudoLocaleFile(format string, content []byte, code, tag string) error {
+	if !localefile.Supported(format) {
+		return fmt.Errorf("pseudo-localizing %s files is not supported, use one of %v", format, localefile.APINames())
+	}
+
+	pseudoContent, err := pseudoLocalize(format, content, code)
+	if err != nil {
+		return err
+	}
+
+	pseudoFile := &LocaleFile{Name: code, Code: code, Tag: tag, FileFormat: format}
+	if pseudoFile.Path, err = target.ReplacePlaceholders(pseudoFile); err != nil {
+		return err
+	}
+	if err := createFile(pseudoFile.Path); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(pseudoFile.Path, pseudoContent, 0700); err != nil {
+		return err
+	}
+
+	print.Success("Pseudo-localized %s to %s", code, pseudoFile.RelPath())
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/phrase/phraseapp-go/phraseapp"
+)
+
+func TestPseudoLocalize(t *testing.T) {
+	tests := []struct {
+		format  string
+		content string
+		exp     string
+	}{
+		{"yml", "en:\n  a: \"Hi %{name}\"\n", "en-XA:\n  a: \"[Ĥî %{name} ~]\"\n"},
+		{"simple_json", `{"a": "<b>Hi</b>"}`, "{\n  \"a\": \"[<b>Ĥî</b> ~]\"\n}\n"},
+		{"properties", "a=Hi {0}\n", "a=[\\u0124\\u00ee {0} ~]\n"},
+	}
+	for _, test := range tests {
+		got, err := pseudoLocalize(test.format, []byte(test.content), "en-XA")
+		if err != nil {
+			t.Fatalf("%s: didn't expect an error, got: %s", test.format, err)
+		}
+		if string(got) != test.exp {
+			t.Errorf("%s: expected %q, got %q", test.format, test.exp, got)
+		}
+	}
+}
+
+func TestIsSourceLocaleFile(t *testing.T) {
+	content := []byte("en:\n  a: A\n")
+	if !isSourceLocaleFile(&LocaleFile{}, "yml", content, "en") {
+		t.Errorf("expected the locale named in the file to be used without placeholders")
+	}
+	if isSourceLocaleFile(&LocaleFile{Code: "de"}, "yml", content, "en") {
+		t.Errorf("expected the locale code of the path to be used")
+	}
+	if !isSourceLocaleFile(&LocaleFile{Name: "en"}, "yml", content, "en") {
+		t.Errorf("expected the locale name of the path to be used")
+	}
+}
+
+func TestPseudoTargetsFor(t *testing.T) {
+	targets := Targets{
+		{File: "a/<locale_code>.yml", ProjectID: "p1", FileFormat: "yml"},
+		{File: "b/en.yml", ProjectID: "p1", FileFormat: "yml"},
+		{File: "c/<locale_name>.json", ProjectID: "p1", FileFormat: "simple_json"},
+		{File: "d/<locale_code>.yml", ProjectID: "p2", FileFormat: "yml"},
+	}
+	got := pseudoTargetsFor(targets, "p1", "yml")
+	if len(got) != 1 || got[0] != targets[0] {
+		t.Errorf("expected only the first target, got %v", got)
+	}
+}
+
+func TestPullPseudo(t *testing.T) {
+	ct, done := newCleanupTest(t)
+	defer done()
+
+	ct.upload("en:\n  a: Hello\n")
+	locales, err := RemoteLocales(ct.client, LocaleCacheKey{ProjectID: "project"})
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+
+	format := "yml"
+	target := &Target{
+		File:          "./out/<locale_code>.yml",
+		ProjectID:     "project",
+		Params:        &PullParams{LocaleDownloadParams: phraseapp.LocaleDownloadParams{FileFormat: &format}},
+		RemoteLocales: locales,
+	}
+	if err := target.PullPseudo(ct.client, "", "en-XA"); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(ct.dir, "out", "en-XA.yml"))
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if exp := "en-XA:\n  a: \"[Ĥéļļö ~~]\"\n"; string(content) != exp {
+		t.Errorf("expected %q, got %q", exp, content)
+	}
+
+	target.File = "./out/en.yml"
+	if err := target.PullPseudo(ct.client, "", "en-XA"); err == nil {
+		t.Errorf("expected an error for a target without locale placeholder")
+	}
+}
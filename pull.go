@@ -22,6 +22,7 @@ type PullCommand struct {
 	phraseapp.Config
 	Branch             string `cli:"opt --branch"`
 	UseLocalBranchName bool   `cli:"opt --use-local-branch-name desc='pull from the branch with the name of your currently checked out branch (git or mercurial)'"`
+	Pseudo             string `cli:"opt --pseudo desc='Also write a pseudo-localized copy of the default locale as the locale with this code, e.g. en-XA'"`
 }
 
 func (cmd *PullCommand) Run() error {
@@ -67,6 +68,12 @@ func (cmd *PullCommand) Run() error {
 		if err != nil {
 			return err
 		}
+
+		if cmd.Pseudo != "" {
+			if err := target.PullPseudo(client, cmd.Branch, cmd.Pseudo); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -113,6 +120,49 @@ func (target *Target) Pull(client *phraseapp.Client, branch string) error {
 }
 
 func (target *Target) DownloadAndWriteToFile(client *phraseapp.Client, localeFile *LocaleFile, branch string) error {
+	res, err := target.download(client, localeFile, branch)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(localeFile.Path, res, 0700)
+	return err
+}
+
+// PullPseudo writes a pseudo-localized copy of the default locale of the project as the locale with the given code, to
+// the files the target would have for it. The pseudo locale doesn't need to exist in PhraseApp.
+func (target *Target) PullPseudo(client *phraseapp.Client, branch, code string) error {
+	if !placeholders.ContainsLocalePlaceholder(target.File) {
+		return fmt.Errorf("--pseudo needs a <locale_code> or <locale_name> placeholder in the target file %q", target.File)
+	}
+
+	var defaultLocale *phraseapp.Locale
+	for _, locale := range target.RemoteLocales {
+		if locale.Default {
+			defaultLocale = locale
+		}
+	}
+	if defaultLocale == nil {
+		return fmt.Errorf("Could not find the default locale of project %q to pseudo-localize", target.ProjectID)
+	}
+
+	localeFiles, err := target.createLocaleFiles(defaultLocale)
+	if err != nil {
+		return err
+	}
+	for _, localeFile := range localeFiles {
+		content, err := target.download(client, localeFile, branch)
+		if err != nil {
+			return fmt.Errorf("%s for %s", err, localeFile.Message())
+		}
+		if err := target.writePseudoLocaleFile(localeFile.FileFormat, content, code, localeFile.Tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (target *Target) download(client *phraseapp.Client, localeFile *LocaleFile, branch string) ([]byte, error) {
 	downloadParams := &phraseapp.LocaleDownloadParams{Branch: &branch}
 	if target.Params != nil {
 		*downloadParams = target.Params.LocaleDownloadParams
@@ -136,21 +186,7 @@ func (target *Target) DownloadAndWriteToFile(client *phraseapp.Client, localeFil
 		fmt.Fprintln(os.Stderr, "FormatOptions", downloadParams.FormatOptions)
 	}
 
-	res, err := client.LocaleDownload(target.ProjectID, localeFile.ID, downloadParams)
-	if err != nil {
-		if rateLimitError, ok := (err).(*phraseapp.RateLimitingError); ok {
-			waitForRateLimit(rateLimitError)
-			res, err = client.LocaleDownload(target.ProjectID, localeFile.ID, downloadParams)
-			if err != nil {
-				return err
-			}
-		} else {
-			return err
-		}
-	}
-
-	err = ioutil.WriteFile(localeFile.Path, res, 0700)
-	return err
+	return client.LocaleDownload(target.ProjectID, localeFile.ID, downloadParams)
 }
 
 func (target *Target) LocaleFiles() (LocaleFiles, error) {
@@ -210,15 +246,6 @@ func (target *Target) createLocaleFiles(remoteLocale *phraseapp.Locale) (LocaleF
 	return files, nil
 }
 
-func waitForRateLimit(rateLimitError *phraseapp.RateLimitingError) {
-	if rateLimitError.Remaining == 0 {
-		reset := rateLimitError.Reset
-		resetTime := reset.Add(time.Second * 5).Sub(time.Now())
-		fmt.Printf("Rate limit exceeded. Download will resume in %d seconds\n", int64(resetTime.Seconds()))
-		time.Sleep(resetTime)
-	}
-}
-
 func createLocaleFile(target *Target, remoteLocale *phraseapp.Locale, tag string) (*LocaleFile, error) {
 	localeFile := &LocaleFile{
 		Name:       remoteLocale.Name,
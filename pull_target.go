@@ -190,15 +190,21 @@ func TargetsFromConfig(config phraseapp.Config) (Targets, error) {
 	return validTargets, nil
 }
 
-func (tgt *Target) UnmarshalYAML(unmarshal func(interface{}) error) error {
-	m := map[string]interface{}{}
-	err := phraseapp.ParseYAMLToMap(unmarshal, map[string]interface{}{
+// yamlFields maps the keys of a target in the config file to the fields they are parsed into. The params are parsed
+// into the given map.
+func (tgt *Target) yamlFields(params *map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
 		"file":         &tgt.File,
 		"project_id":   &tgt.ProjectID,
 		"access_token": &tgt.AccessToken,
 		"file_format":  &tgt.FileFormat,
-		"params":       &m,
-	})
+		"params":       params,
+	}
+}
+
+func (tgt *Target) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	m := map[string]interface{}{}
+	err := phraseapp.ParseYAMLToMap(unmarshal, tgt.yamlFields(&m))
 	if err != nil {
 		return err
 	}
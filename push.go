@@ -2,14 +2,17 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	ct "github.com/daviddengcn/go-colortext"
 	"github.com/jpillora/backoff"
+	"github.com/phrase/phraseapp-client/internal/lineedit"
 	"github.com/phrase/phraseapp-client/internal/paths"
 	"github.com/phrase/phraseapp-client/internal/placeholders"
 	"github.com/phrase/phraseapp-client/internal/print"
@@ -22,6 +25,9 @@ type PushCommand struct {
 	Wait               bool   `cli:"opt --wait desc='Wait for files to be processed'"`
 	Branch             string `cli:"opt --branch"`
 	UseLocalBranchName bool   `cli:"opt --use-local-branch-name desc='push from the branch with the name of your currently checked out branch (git or mercurial)'"`
+	Cleanup            bool   `cli:"opt --cleanup desc='Delete keys not mentioned in any of the pushed files, implies --wait'"`
+	Confirm            bool   `cli:"opt --confirm desc='Don’t ask for confirmation before deleting keys with --cleanup'"`
+	CreateBranch       string `cli:"opt --create-branch desc='Create a missing branch: always, never or ask (default: ask on a terminal with --use-local-branch-name, else always)'"`
 }
 
 func (cmd *PushCommand) Run() error {
@@ -73,45 +79,20 @@ func (cmd *PushCommand) Run() error {
 	cmd.Branch = branchName
 
 	if cmd.Branch != "" {
+		policy, err := cmd.createBranchPolicy()
+		if err != nil {
+			return err
+		}
+
+		projectIDs := make([]string, 0, len(projectsAffected))
 		for projectID := range projectsAffected {
-			_, err := client.BranchShow(projectID, cmd.Branch)
-			if err != nil {
-				if useLocalBranchName(cmd.UseLocalBranchName) {
-					printCreateBranchQuestion(cmd.Branch)
-					text, _ := bufio.NewReader(os.Stdin).ReadString('\n')
-
-					if !isYes(strings.TrimSpace(text)) {
-						return nil
-					}
-				}
-
-				branchParams := &phraseapp.BranchParams{Name: &cmd.Branch}
-				branch, _ := client.BranchCreate(projectID, branchParams)
-
-				fmt.Println()
-
-				taskResult := make(chan string, 1)
-				taskErr := make(chan error, 1)
-
-				fmt.Printf("Waiting for branch %s is created!", branch.Name)
-				spinner.While(func() {
-					branchCreateResult, err := getBranchCreateResult(client, projectID, branch)
-					taskResult <- branchCreateResult
-					taskErr <- err
-				})
-				fmt.Println()
-
-				if err := <-taskErr; err != nil {
-					return err
-				}
-
-				switch <-taskResult {
-				case "success":
-					print.Success("Successfully created branch %s", branch.Name)
-				case "error":
-					print.Failure("There was an error creating branch %s.", branch.Name)
-				}
-			}
+			projectIDs = append(projectIDs, projectID)
+		}
+		sort.Strings(projectIDs)
+
+		proceed, err := cmd.ensureBranch(client, projectIDs, policy)
+		if err != nil || !proceed {
+			return err
 		}
 	}
 
@@ -126,17 +107,98 @@ func (cmd *PushCommand) Run() error {
 		}
 	}
 
+	// keys can only be told to be unmentioned once the uploads are processed
+	if cmd.Cleanup {
+		cmd.Wait = true
+	}
+
+	pushed := newPushedUploads()
 	for _, source := range sources {
-		err := source.Push(client, cmd.Wait, cmd.Branch)
+		err := source.Push(client, cmd.Wait, cmd.Branch, pushed)
 		if err != nil {
 			return err
 		}
 	}
 
+	if cmd.Cleanup {
+		return cmd.cleanup(client, pushed)
+	}
+
 	return nil
 }
 
-func (source *Source) Push(client *phraseapp.Client, waitForResults bool, branch string) error {
+// pushedUploads collects the uploads of a push by project and branch, for the cleanup of unmentioned keys. Keys belong
+// to a project and branch, not to a locale, so the uploads of all locales are grouped: a key is only unmentioned if no
+// file of the push mentions it, whereas grouping by locale would delete keys that are missing from a single locale file.
+type pushedUploads struct {
+	ids map[LocaleCacheKey][]string
+	// incomplete projects had files fail to upload or process, the keys of which would be deleted otherwise.
+	incomplete map[LocaleCacheKey]bool
+}
+
+func newPushedUploads() *pushedUploads {
+	return &pushedUploads{ids: map[LocaleCacheKey][]string{}, incomplete: map[LocaleCacheKey]bool{}}
+}
+
+func (p *pushedUploads) add(projectID, branch, uploadID string, processed bool) {
+	key := LocaleCacheKey{ProjectID: projectID, Branch: branch}
+	if !processed {
+		p.incomplete[key] = true
+		return
+	}
+	p.ids[key] = append(p.ids[key], uploadID)
+}
+
+// keys returns the projects and branches uploads were made to, including incomplete ones.
+func (p *pushedUploads) keys() []LocaleCacheKey {
+	keys := []LocaleCacheKey{}
+	for key := range p.ids {
+		keys = append(keys, key)
+	}
+	for key := range p.incomplete {
+		if _, found := p.ids[key]; !found {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].ProjectID != keys[j].ProjectID {
+			return keys[i].ProjectID < keys[j].ProjectID
+		}
+		return keys[i].Branch < keys[j].Branch
+	})
+	return keys
+}
+
+// cleanup deletes the keys not mentioned in any upload of the push. Keys mentioned in an upload of another locale or
+// tag of the same project are kept. Projects with failed uploads are not cleaned up, which is reported as an error.
+func (cmd *PushCommand) cleanup(client *phraseapp.Client, pushed *pushedUploads) error {
+	skipped := []string{}
+	for _, key := range pushed.keys() {
+		if pushed.incomplete[key] {
+			print.Failure("Not cleaning up project %s, as not all files were uploaded and processed successfully.", key.ProjectID)
+			skipped = append(skipped, key.ProjectID)
+			continue
+		}
+
+		queries := []string{}
+		for _, id := range pushed.ids[key] {
+			queries = append(queries, "unmentioned_in_upload:"+id)
+		}
+
+		fmt.Printf("Cleaning up project %s...\n", key.ProjectID)
+		cleanup := &keysCleanup{ProjectID: key.ProjectID, Branch: key.Branch, Queries: queries, Confirm: cmd.Confirm}
+		if err := cleanup.run(client); err != nil {
+			return err
+		}
+	}
+
+	if len(skipped) > 0 {
+		return fmt.Errorf("project(s) %s were not cleaned up, as not all files were uploaded and processed successfully", strings.Join(skipped, ", "))
+	}
+	return nil
+}
+
+func (source *Source) Push(client *phraseapp.Client, waitForResults bool, branch string, pushed *pushedUploads) error {
 	localeFiles, err := source.LocaleFiles()
 	if err != nil {
 		return err
@@ -153,6 +215,7 @@ func (source *Source) Push(client *phraseapp.Client, waitForResults bool, branch
 				localeFile.Name = localeDetails.Name
 			} else {
 				fmt.Printf("failed to create locale: %s\n", err)
+				pushed.add(source.ProjectID, branch, "", false)
 				continue
 			}
 		}
@@ -180,12 +243,14 @@ func (source *Source) Push(client *phraseapp.Client, waitForResults bool, branch
 				return err
 			}
 
-			switch <-taskResult {
+			result := <-taskResult
+			switch result {
 			case "success":
 				print.Success("Successfully uploaded and processed %s.", localeFile.RelPath())
 			case "error":
 				print.Failure("There was an error processing %s. Your changes were not saved online.", localeFile.RelPath())
 			}
+			pushed.add(source.ProjectID, branch, upload.ID, result == "success")
 		} else {
 			fmt.Println("done!")
 			fmt.Printf("Check upload ID: %s, filename: %s for information about processing results.\n", upload.ID, upload.Filename)
@@ -388,31 +453,88 @@ func getUploadResult(client *phraseapp.Client, projectID string, upload *phrasea
 	return
 }
 
-func getBranchCreateResult(client *phraseapp.Client, projectID string, branch *phraseapp.Branch) (result string, err error) {
-	b := &backoff.Backoff{
-		Min:    500 * time.Millisecond,
-		Max:    10 * time.Second,
-		Factor: 2,
-		Jitter: true,
+const (
+	createBranchAlways = "always"
+	createBranchNever  = "never"
+	createBranchAsk    = "ask"
+)
+
+// createBranchPolicy returns how to handle a branch missing in PhraseApp. Asking is only possible on a terminal, so
+// that pushes in CI don't wait for an answer forever.
+func (cmd *PushCommand) createBranchPolicy() (string, error) {
+	switch cmd.CreateBranch {
+	case "":
+		if useLocalBranchName(cmd.UseLocalBranchName) && lineedit.IsTerminal(os.Stdin) {
+			return createBranchAsk, nil
+		}
+		return createBranchAlways, nil
+	case createBranchAsk:
+		if !lineedit.IsTerminal(os.Stdin) {
+			return "", errors.New("--create-branch=ask needs a terminal, use always or never instead")
+		}
+		return createBranchAsk, nil
+	case createBranchAlways, createBranchNever:
+		return cmd.CreateBranch, nil
+	default:
+		return "", fmt.Errorf("unknown --create-branch %q, use always, never or ask", cmd.CreateBranch)
 	}
+}
 
-	for ; result != "success" && result != "error"; result = branch.State {
-		time.Sleep(b.Duration())
-		branch, err = client.BranchShow(projectID, branch.Name)
+// ensureBranch makes sure the branch exists in all given projects, creating it where missing according to the policy.
+// It returns false if creating the branch was declined.
+func (cmd *PushCommand) ensureBranch(client *phraseapp.Client, projectIDs []string, policy string) (bool, error) {
+	for _, projectID := range projectIDs {
+		if _, err := client.BranchShow(projectID, cmd.Branch); err == nil {
+			continue
+		}
+
+		switch policy {
+		case createBranchNever:
+			return false, fmt.Errorf("branch %s doesn't exist in project %s, create it or use --create-branch=always", cmd.Branch, projectID)
+		case createBranchAsk:
+			printCreateBranchQuestion(cmd.Branch, projectID)
+			text, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+
+			if !isYes(strings.TrimSpace(text)) {
+				return false, nil
+			}
+		}
+
+		if err := createBranch(client, projectID, cmd.Branch); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func createBranch(client *phraseapp.Client, projectID, name string) error {
+	branch, err := client.BranchCreate(projectID, &phraseapp.BranchParams{Name: &name})
+	if err != nil {
+		return fmt.Errorf("creating branch %s in project %s failed: %s", name, projectID, err)
+	}
+
+	if branch.State != "success" {
+		fmt.Println()
+		fmt.Printf("Waiting for branch %s is created!", name)
+		spinner.While(func() {
+			_, err = waitForBranch(client, projectID, name, "success")
+		})
+		fmt.Println()
 		if err != nil {
-			break
+			return err
 		}
 	}
 
-	return
+	print.Success("Successfully created branch %s in project %s", name, projectID)
+	return nil
 }
 
-func printCreateBranchQuestion(branch string) {
+func printCreateBranchQuestion(branch, projectID string) {
 	fmt.Printf("\nYou have currently checked out the branch '")
 	ct.ChangeColor(ct.Green, false, ct.None, false)
 	fmt.Printf("%s", branch)
 	ct.ResetColor()
-	fmt.Printf("'.\nThere currently is no branch in PhraseApp with this name.\n\n")
+	fmt.Printf("'.\nThere currently is no branch in the PhraseApp project %s with this name.\n\n", projectID)
 	fmt.Printf("Should we create a new branch in PhraseApp with the same name and push to it? [y/N]: ")
 }
 
@@ -129,15 +129,21 @@ func (source *Source) CheckPreconditions() error {
 	return nil
 }
 
-func (src *Source) UnmarshalYAML(unmarshal func(interface{}) error) error {
-	m := map[string]interface{}{}
-	err := phraseapp.ParseYAMLToMap(unmarshal, map[string]interface{}{
+// yamlFields maps the keys of a source in the config file to the fields they are parsed into. The params are parsed
+// into the given map.
+func (src *Source) yamlFields(params *map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
 		"file":         &src.File,
 		"project_id":   &src.ProjectID,
 		"access_token": &src.AccessToken,
 		"file_format":  &src.FileFormat,
-		"params":       &m,
-	})
+		"params":       params,
+	}
+}
+
+func (src *Source) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	m := map[string]interface{}{}
+	err := phraseapp.ParseYAMLToMap(unmarshal, src.yamlFields(&m))
 	if err != nil {
 		return err
 	}
@@ -483,6 +483,38 @@ func TestUploadFile(t *testing.T) {
 	}
 }
 
+func TestUploadFileReplay(t *testing.T) {
+	d := setupFiles(t, "a/b/c/d.txt")
+	defer os.RemoveAll(d)
+
+	replay, err := newReplayer("testdata/fixtures/upload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(replay)
+	defer srv.Close()
+
+	c := new(phraseapp.Client)
+	c.Credentials.Host = srv.URL
+	c.Credentials.Token = "some_token"
+
+	src := new(Source)
+	src.ProjectID = "project-id"
+	src.Params = new(phraseapp.UploadParams)
+
+	file := new(LocaleFile)
+	file.Path = filepath.Join(d, "a/b/c/d.txt")
+	file.ID = "locale_id"
+
+	upload, err := src.uploadFile(c, file, "")
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if upload.ID != "upload-id" || upload.State != "processing" {
+		t.Errorf("expected the recorded upload, got %+v", upload)
+	}
+}
+
 func TestRemoteLocaleForLocaleFile(t *testing.T) {
 	rlEN := &phraseapp.Locale{ID: "en-locale-id", Name: "english", Code: "en"}
 	rlDE := &phraseapp.Locale{ID: "de-locale-id", Name: "deutsch", Code: "de"}
@@ -951,3 +983,54 @@ func (pattern *Pattern) TestPattern(t *testing.T, idx int) {
 		t.Errorf("Expected LocaleName to equal '%s' but was '%s' Pattern: %d", pattern.ExpectedName, localeFile.Name, idx+1)
 	}
 }
+
+func TestCreateBranchPolicy(t *testing.T) {
+	defer os.Setenv("PHRASEAPP_USE_LOCAL_BRANCH_NAME", os.Getenv("PHRASEAPP_USE_LOCAL_BRANCH_NAME"))
+	os.Unsetenv("PHRASEAPP_USE_LOCAL_BRANCH_NAME")
+
+	// Tests don't run on a terminal, so asking isn't possible.
+	tests := []struct {
+		cmd *PushCommand
+		exp string
+	}{
+		{&PushCommand{}, createBranchAlways},
+		{&PushCommand{UseLocalBranchName: true}, createBranchAlways},
+		{&PushCommand{CreateBranch: "never"}, createBranchNever},
+		{&PushCommand{CreateBranch: "ask"}, ""},
+		{&PushCommand{CreateBranch: "sometimes"}, ""},
+	}
+	for _, test := range tests {
+		got, err := test.cmd.createBranchPolicy()
+		if test.exp == "" && err == nil {
+			t.Errorf("%q: expected an error, got %q", test.cmd.CreateBranch, got)
+		} else if got != test.exp {
+			t.Errorf("%q: expected %q, got %q", test.cmd.CreateBranch, test.exp, got)
+		}
+	}
+}
+
+func TestEnsureBranch(t *testing.T) {
+	ct, done := newCleanupTest(t)
+	defer done()
+
+	if _, err := ct.client.BranchCreate("a", &phraseapp.BranchParams{Name: optionalString("feature")}); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+
+	cmd := &PushCommand{Branch: "feature"}
+	if _, err := cmd.ensureBranch(ct.client, []string{"a", "b"}, createBranchNever); err == nil {
+		t.Errorf("expected an error for the branch missing in project b")
+	}
+
+	proceed, err := cmd.ensureBranch(ct.client, []string{"a", "b"}, createBranchAlways)
+	if err != nil || !proceed {
+		t.Fatalf("expected the branch to be created, got %t (%v)", proceed, err)
+	}
+	if _, err := ct.client.BranchShow("b", "feature"); err != nil {
+		t.Errorf("expected the branch to exist in project b, got: %s", err)
+	}
+
+	if err := createBranch(ct.client, "b", "feature"); err == nil {
+		t.Errorf("expected an error creating an existing branch")
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jpillora/backoff"
+)
+
+// Settings for retrying failed API requests, changed with the global --max-retries and --retry-timeout options.
+var (
+	MaxRetries   = 3
+	RetryTimeout = 2 * time.Minute
+)
+
+// Methods that can be sent again without changing the result, even if the server might have handled the failed
+// request already.
+var idempotentMethods = map[string]bool{
+	"GET":     true,
+	"HEAD":    true,
+	"OPTIONS": true,
+	"PUT":     true,
+	"DELETE":  true,
+}
+
+// retryTransport retries requests failing with transient errors. Connection errors and server errors are only
+// retried for idempotent methods, as the server might have handled the request already. Requests rejected because
+// of the rate limit are retried for all methods, after waiting as long as the server asks for, even if that exceeds
+// the timeout: the rate limit window lasts several minutes, and giving up would only fail the command.
+type retryTransport struct {
+	Transport  http.RoundTripper // Transport used to send the requests, http.DefaultTransport if nil.
+	MaxRetries int
+	Timeout    time.Duration // Retrying stops once waiting for the next attempt would exceed the timeout, unless rate limited.
+
+	backoff backoff.Backoff
+}
+
+func newRetryTransport(transport http.RoundTripper, maxRetries int, timeout time.Duration) *retryTransport {
+	return &retryTransport{
+		Transport:  transport,
+		MaxRetries: maxRetries,
+		Timeout:    timeout,
+		backoff: backoff.Backoff{
+			Min:    500 * time.Millisecond,
+			Max:    30 * time.Second,
+			Factor: 2,
+			Jitter: true,
+		},
+	}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	deadline := time.Now().Add(t.Timeout)
+	for attempt := 0; ; attempt++ {
+		resp, err := transport.RoundTrip(req)
+		if attempt >= t.MaxRetries || !retryable(req, resp, err) {
+			return resp, err
+		}
+
+		wait := retryDelay(resp)
+		if wait <= 0 {
+			wait = t.backoff.ForAttempt(float64(attempt))
+		}
+		rateLimited := resp != nil && resp.StatusCode == http.StatusTooManyRequests
+		if !rateLimited && time.Now().Add(wait).After(deadline) {
+			return resp, err
+		}
+
+		next, rewindErr := rewind(req)
+		if rewindErr != nil {
+			return resp, err
+		}
+
+		reason := ""
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = resp.Status
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if Debug || wait >= 5*time.Second {
+			fmt.Fprintf(os.Stderr, "%s %s failed (%s), retrying in %s (%d/%d)\n", req.Method, req.URL.Path, reason, wait.Round(time.Second/10), attempt+1, t.MaxRetries)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		req = next
+	}
+}
+
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		return req.Context().Err() == nil && idempotentMethods[req.Method]
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return idempotentMethods[req.Method]
+	}
+	return false
+}
+
+// retryDelay returns how long the server asks to wait before sending the request again, either with a Retry-After
+// header or, if the rate limit is used up, with the time the limit is reset. Zero is returned if the response doesn't
+// tell.
+func retryDelay(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			return time.Duration(seconds) * time.Second
+		}
+		if at, err := http.ParseTime(retryAfter); err == nil {
+			return time.Until(at)
+		}
+	}
+
+	if resp.Header.Get("X-Rate-Limit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-Rate-Limit-Reset"), 10, 64); err == nil {
+			// Add some leeway, as the clocks of client and server might differ.
+			return time.Until(time.Unix(reset, 0).Add(time.Second))
+		}
+	}
+	return 0
+}
+
+// rewind returns a copy of the request that can be sent again, with a fresh body.
+func rewind(req *http.Request) (*http.Request, error) {
+	next := *req
+	if req.Body == nil || req.Body == http.NoBody {
+		return &next, nil
+	}
+	if req.GetBody == nil {
+		return nil, fmt.Errorf("request body can't be sent again")
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	next.Body = body
+	return &next, nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestRetryTransport(maxRetries int) *retryTransport {
+	t := newRetryTransport(nil, maxRetries, time.Minute)
+	t.backoff.Min = time.Millisecond
+	t.backoff.Max = 2 * time.Millisecond
+	return t
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		method   string
+		statuses []int
+		expected int // number of requests
+		status   int // final status
+	}{
+		{"GET", []int{503, 502, 200}, 3, 200},
+		{"GET", []int{500, 500, 500, 500, 500}, 4, 500},
+		{"GET", []int{404}, 1, 404},
+		{"POST", []int{503, 201}, 1, 503},
+		{"POST", []int{429, 201}, 2, 201},
+		{"PUT", []int{504, 200}, 2, 200},
+	}
+
+	for _, test := range tests {
+		requests := 0
+		bodies := []string{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+			w.WriteHeader(test.statuses[requests])
+			requests++
+		}))
+
+		client := &http.Client{Transport: newTestRetryTransport(3)}
+		req, err := http.NewRequest(test.method, server.URL, strings.NewReader("payload"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		server.Close()
+		if err != nil {
+			t.Errorf("%s %v: didn't expect an error, got: %s", test.method, test.statuses, err)
+			continue
+		}
+		resp.Body.Close()
+
+		if requests != test.expected {
+			t.Errorf("%s %v: expected %d requests, got %d", test.method, test.statuses, test.expected, requests)
+		}
+		if resp.StatusCode != test.status {
+			t.Errorf("%s %v: expected status %d, got %d", test.method, test.statuses, test.status, resp.StatusCode)
+		}
+		for _, body := range bodies {
+			if body != "payload" {
+				t.Errorf("%s %v: expected every request to send the body, got %q", test.method, test.statuses, bodies)
+				break
+			}
+		}
+	}
+}
+
+func TestRetryTransportTimeout(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	resp, err := (&http.Client{Transport: newTestRetryTransport(3)}).Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if requests != 1 || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected to give up when waiting exceeds the timeout, got %d requests and status %d", requests, resp.StatusCode)
+	}
+}
+
+func TestRetryTransportRateLimitExceedingTimeout(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	transport := newTestRetryTransport(3)
+	transport.Timeout = 10 * time.Millisecond
+	resp, err := (&http.Client{Transport: transport}).Post(server.URL, "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if requests != 2 || resp.StatusCode != http.StatusCreated {
+		t.Errorf("expected to wait for the rate limit reset beyond the timeout, got %d requests and status %d", requests, resp.StatusCode)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	resp := &http.Response{Header: http.Header{}}
+	if d := retryDelay(resp); d != 0 {
+		t.Errorf("expected no delay without headers, got %s", d)
+	}
+
+	resp.Header.Set("Retry-After", "7")
+	if d := retryDelay(resp); d != 7*time.Second {
+		t.Errorf("expected delay of 7s, got %s", d)
+	}
+
+	resp.Header.Set("Retry-After", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	if d := retryDelay(resp); d < 58*time.Second || d > time.Minute {
+		t.Errorf("expected delay of about 1m, got %s", d)
+	}
+
+	resp.Header = http.Header{}
+	resp.Header.Set("X-Rate-Limit-Remaining", "0")
+	resp.Header.Set("X-Rate-Limit-Reset", "0")
+	if d := retryDelay(resp); d >= 0 {
+		t.Errorf("expected delay for reset in the past to be negative, got %s", d)
+	}
+}
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/phrase/phraseapp-client/cli"
+	"github.com/phrase/phraseapp-client/internal/lineedit"
+	"github.com/phrase/phraseapp-client/internal/print"
+	"github.com/phrase/phraseapp-go/phraseapp"
+)
+
+const shellHistoryFile = ".phraseapp_history"
+
+var errExitShell = errors.New("exit shell")
+
+// resultRefRegexp matches references to the last JSON result, like "$" (the whole result), "$2" (second element of
+// a list) or "$1.id" (field of the first element).
+var resultRefRegexp = regexp.MustCompile(`^\$(\d*)((?:\.[^.\s]+)*)$`)
+
+type ShellCommand struct {
+	phraseapp.Config
+	ProjectID string `cli:"opt --project-id desc='Project to start the session with (default: project_id from config)'"`
+	Branch    string `cli:"opt --branch desc='Branch to start the session with'"`
+
+	cfg *Config
+}
+
+func (cmd *ShellCommand) Run() error {
+	if sessionClients != nil {
+		return fmt.Errorf("already running an interactive shell")
+	}
+	sessionClients = map[sessionKey]*phraseapp.Client{}
+	defer func() { sessionClients = nil }()
+
+	// Keep the credentials and settings given on the command line for all commands run in the shell.
+	libCfg := cmd.Config
+	cfg := *cmd.cfg
+	cfg.Config = &libCfg
+
+	sh := &shell{
+		cfg:     &cfg,
+		project: cmd.cfg.DefaultProjectID,
+		branch:  cmd.Branch,
+		editor:  lineedit.New(os.Stdin, os.Stdout),
+	}
+	if cmd.ProjectID != "" {
+		sh.project = cmd.ProjectID
+	}
+	sh.editor.Complete = sh.complete
+
+	historyPath := filepath.Join(homeDir(), shellHistoryFile)
+	if err := sh.editor.LoadHistory(historyPath); err != nil {
+		print.Error(err)
+	}
+	defer sh.editor.SaveHistory(historyPath)
+
+	fmt.Println("Interactive PhraseApp shell. Type 'help' for a list of commands, 'exit' or Ctrl-D to leave.")
+
+	for {
+		line, err := sh.editor.ReadLine(sh.prompt())
+		switch err {
+		case nil:
+		case io.EOF:
+			return nil
+		case lineedit.ErrInterrupted:
+			continue
+		default:
+			return err
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		sh.editor.AddHistory(line)
+
+		switch err := sh.exec(line); err {
+		case nil, cli.ErrorHelpRequested, cli.ErrorNoRoute:
+		case errExitShell:
+			return nil
+		default:
+			print.Error(err)
+		}
+	}
+}
+
+// shell is the state of an interactive session: the context commands run in and the result of the last command.
+type shell struct {
+	cfg     *Config
+	project string
+	branch  string
+	editor  *lineedit.Editor
+
+	last    interface{} // last JSON result printed by a command
+	hasLast bool
+}
+
+func (sh *shell) prompt() string {
+	context := sh.project
+	if sh.branch != "" {
+		context += "@" + sh.branch
+	}
+	if context != "" {
+		return fmt.Sprintf("phraseapp [%s]> ", context)
+	}
+	return "phraseapp> "
+}
+
+var shellBuiltins = map[string]string{
+	"use":      "use <project-id>     switch to the given project (and its main branch)",
+	"checkout": "checkout [<branch>]  switch to the given branch, or back to the main branch",
+	"help":     "help                 show this help",
+	"exit":     "exit                 leave the shell",
+}
+
+func (sh *shell) exec(line string) error {
+	args, err := cli.SplitCommandLine(line)
+	if err != nil {
+		return err
+	}
+
+	args, err = sh.substituteResultRefs(args)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "exit", "quit":
+		return errExitShell
+	case "help":
+		return sh.help()
+	case "use":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: %s", shellBuiltins["use"])
+		}
+		sh.project, sh.branch = args[1], ""
+		return nil
+	case "checkout":
+		if len(args) > 2 {
+			return fmt.Errorf("usage: %s", shellBuiltins["checkout"])
+		}
+		sh.branch = ""
+		if len(args) == 2 {
+			sh.branch = args[1]
+		}
+		return nil
+	case "shell":
+		return fmt.Errorf("already running an interactive shell")
+	}
+
+	// The router is created anew for every command, as actions keep the values parsed from the command line.
+	r, err := sh.router()
+	if err != nil {
+		return err
+	}
+
+	route, rest, err := r.Lookup(args...)
+	if err != nil {
+		return r.Run(args...)
+	}
+
+	if sh.branch != "" && route.Option("branch") != nil && !containsOption(rest, "branch") {
+		rest = append([]string{"--branch", sh.branch}, rest...)
+	}
+
+	debug := Debug
+	defer func() { Debug = debug }()
+
+	output, err := captureStdout(func() error {
+		return r.Run(append(strings.Split(route.Path, "/"), rest...)...)
+	})
+
+	var result interface{}
+	if json.Unmarshal(output, &result) == nil {
+		sh.last, sh.hasLast = result, true
+	}
+	return err
+}
+
+func (sh *shell) router() (*cli.Router, error) {
+	libCfg := *sh.cfg.Config
+	libCfg.DefaultProjectID = sh.project
+
+	cfg := *sh.cfg
+	cfg.Config = &libCfg
+	return newRouter(&cfg)
+}
+
+func (sh *shell) help() error {
+	r, err := sh.router()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("SHELL COMMANDS")
+	names := make([]string, 0, len(shellBuiltins))
+	for name := range shellBuiltins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Println("  " + shellBuiltins[name])
+	}
+	fmt.Println()
+	fmt.Println("Reference the JSON output of the previous command with $ (all of it), $<n> (n-th element of a list)")
+	fmt.Println("and .<field> suffixes, e.g. 'use $1.id' after 'projects list'.")
+	fmt.Println()
+
+	cli.DefaultWriter = os.Stdout
+	defer func() { cli.DefaultWriter = os.Stderr }()
+	r.Run()
+	return nil
+}
+
+func (sh *shell) complete(line string) []string {
+	args, err := cli.SplitCommandLine(line)
+	if err != nil {
+		return nil
+	}
+
+	word := ""
+	if len(args) > 0 && !strings.HasSuffix(line, " ") {
+		word, args = args[len(args)-1], args[:len(args)-1]
+	}
+
+	r, err := sh.router()
+	if err != nil {
+		return nil
+	}
+	candidates := r.Complete(args, word)
+
+	if len(args) == 0 {
+		for name := range shellBuiltins {
+			if strings.HasPrefix(name, word) {
+				candidates = append(candidates, name)
+			}
+		}
+		sort.Strings(candidates)
+	}
+	return candidates
+}
+
+// substituteResultRefs replaces the arguments referencing the last JSON result with the referenced values.
+func (sh *shell) substituteResultRefs(args []string) ([]string, error) {
+	substituted := make([]string, len(args))
+	for i, arg := range args {
+		m := resultRefRegexp.FindStringSubmatch(arg)
+		if m == nil {
+			substituted[i] = arg
+			continue
+		}
+
+		if !sh.hasLast {
+			return nil, fmt.Errorf("cannot resolve %q: no previous command printed a JSON result", arg)
+		}
+
+		value, err := lookupResult(sh.last, m[1], m[2])
+		if err != nil {
+			return nil, fmt.Errorf("cannot resolve %q: %s", arg, err)
+		}
+		substituted[i] = value
+	}
+	return substituted, nil
+}
+
+func lookupResult(result interface{}, index, path string) (string, error) {
+	value := result
+	if index != "" {
+		n, err := strconv.Atoi(index)
+		if err != nil {
+			return "", err
+		}
+
+		switch v := value.(type) {
+		case []interface{}:
+			if n < 1 || n > len(v) {
+				return "", fmt.Errorf("result has %d element(s)", len(v))
+			}
+			value = v[n-1]
+		default:
+			// A single object is treated like a list with one element.
+			if n != 1 {
+				return "", fmt.Errorf("result is no list")
+			}
+		}
+	}
+
+	for _, field := range strings.Split(path, ".")[1:] {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			fieldValue, found := v[field]
+			if !found {
+				return "", fmt.Errorf("no field %q", field)
+			}
+			value = fieldValue
+		case []interface{}:
+			n, err := strconv.Atoi(field)
+			if err != nil || n < 1 || n > len(v) {
+				return "", fmt.Errorf("no element %q", field)
+			}
+			value = v[n-1]
+		default:
+			return "", fmt.Errorf("no field %q", field)
+		}
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		b, err := json.Marshal(v)
+		return string(b), err
+	}
+}
+
+func containsOption(args []string, long string) bool {
+	for _, arg := range args {
+		if arg == "--"+long || strings.HasPrefix(arg, "--"+long+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+// captureStdout runs f and returns what it printed to stdout, while still passing it through.
+func captureStdout(f func() error) ([]byte, error) {
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, f()
+	}
+
+	os.Stdout = w
+	buf := &bytes.Buffer{}
+	done := make(chan struct{})
+	go func() {
+		io.Copy(io.MultiWriter(stdout, buf), r)
+		close(done)
+	}()
+
+	err = f()
+
+	os.Stdout = stdout
+	w.Close()
+	<-done
+	r.Close()
+
+	return buf.Bytes(), err
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/phrase/phraseapp-go/phraseapp"
+)
+
+func TestShellSubstituteResultRefs(t *testing.T) {
+	sh := &shell{hasLast: true}
+	err := json.Unmarshal([]byte(`[
+		{"id": "abc", "name": "First", "main_format": "yml", "account": {"id": "acc"}},
+		{"id": "def", "name": "Second", "shares_translation_memory": true, "locales": 2}
+	]`), &sh.last)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"$1.id":                        "abc",
+		"$2.name":                      "Second",
+		"$1.account.id":                "acc",
+		"$2.shares_translation_memory": "true",
+		"$2.locales":                   "2",
+		"$.2.id":                       "def",
+		"$1.account":                   `{"id":"acc"}`,
+		"no-ref":                       "no-ref",
+		"$notaref":                     "$notaref",
+	}
+
+	for ref, expected := range tests {
+		args, err := sh.substituteResultRefs([]string{"use", ref})
+		if err != nil {
+			t.Errorf("%s: didn't expect an error, got: %s", ref, err)
+			continue
+		}
+		if args[1] != expected {
+			t.Errorf("%s: expected %q, got %q", ref, expected, args[1])
+		}
+	}
+
+	for _, ref := range []string{"$3.id", "$1.unknown", "$1.id.more"} {
+		if _, err := sh.substituteResultRefs([]string{"use", ref}); err == nil {
+			t.Errorf("%s: expected an error, got none", ref)
+		}
+	}
+
+	if _, err := new(shell).substituteResultRefs([]string{"$1.id"}); err == nil {
+		t.Errorf("expected an error without a previous result, got none")
+	}
+}
+
+func TestShellComplete(t *testing.T) {
+	sh := &shell{cfg: &Config{Config: new(phraseapp.Config)}}
+
+	tests := map[string][]string{
+		"u":                  {"upload", "uploads", "use"},
+		"keys s":             {"search"},
+		"locale dow":         {"download"},
+		"keys search --que":  {"--query"},
+		"keys search abc de": nil,
+	}
+
+	for line, expected := range tests {
+		got := sh.complete(line)
+		if len(got) != len(expected) {
+			t.Errorf("%q: expected candidates %q, got %q", line, expected, got)
+			continue
+		}
+		for i := range expected {
+			if got[i] != expected[i] {
+				t.Errorf("%q: expected candidates %q, got %q", line, expected, got)
+				break
+			}
+		}
+	}
+}
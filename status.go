@@ -0,0 +1,282 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/phrase/phraseapp-client/internal/localefile"
+	"github.com/phrase/phraseapp-client/internal/print"
+	"github.com/phrase/phraseapp-go/phraseapp"
+	yaml "gopkg.in/yaml.v2"
+)
+
+type StatusCommand struct {
+	phraseapp.Config
+	Branch             string `cli:"opt --branch"`
+	UseLocalBranchName bool   `cli:"opt --use-local-branch-name desc='compare with the branch with the name of your currently checked out branch (git or mercurial)'"`
+	Keys               bool   `cli:"opt --keys desc='List the keys of files out of sync'"`
+}
+
+func (cmd *StatusCommand) Run() error {
+	client, err := newClient(cmd.Config.Credentials, cmd.Config.Debug)
+	if err != nil {
+		return err
+	}
+
+	sources, err := SourcesFromConfig(cmd.Config)
+	if err != nil {
+		return err
+	}
+
+	if err := sources.Validate(); err != nil {
+		return err
+	}
+
+	branchName, err := usedBranchName(cmd.UseLocalBranchName, cmd.Branch)
+	if err != nil {
+		return err
+	}
+	cmd.Branch = branchName
+
+	projectIdToLocales, err := LocalesForProjects(client, sources, cmd.Branch)
+	if err != nil {
+		return err
+	}
+	for _, source := range sources {
+		if val, ok := projectIdToLocales[LocaleCacheKey{source.ProjectID, cmd.Branch}]; ok {
+			source.RemoteLocales = val
+		}
+	}
+
+	statuses := []*fileStatus{}
+	for _, source := range sources {
+		s, err := source.status(client, cmd.Branch)
+		if err != nil {
+			return err
+		}
+		statuses = append(statuses, s...)
+	}
+
+	printStatus(statuses, cmd.Branch, cmd.Keys)
+	return nil
+}
+
+// fileStatus is the result of comparing a locale file with the locale in PhraseApp.
+type fileStatus struct {
+	LocaleFile *LocaleFile
+	// Missing is set if the locale doesn't exist in PhraseApp, in which case all keys are only local.
+	Missing bool
+	// Unsupported is set if the file format can't be compared.
+	Unsupported bool
+
+	OnlyLocal  []string
+	OnlyRemote []string
+	Changed    []string
+	Local      map[string]string
+	Remote     map[string]string
+}
+
+func (s *fileStatus) inSync() bool {
+	return !s.Missing && !s.Unsupported && len(s.OnlyLocal)+len(s.OnlyRemote)+len(s.Changed) == 0
+}
+
+func (source *Source) status(client *phraseapp.Client, branch string) ([]*fileStatus, error) {
+	localeFiles, err := source.LocaleFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	format := source.GetFileFormat()
+	statuses := []*fileStatus{}
+	for _, localeFile := range localeFiles {
+		s := &fileStatus{LocaleFile: localeFile}
+		statuses = append(statuses, s)
+		if !comparableFormat(format) {
+			s.Unsupported = true
+			continue
+		}
+
+		content, err := ioutil.ReadFile(localeFile.Path)
+		if err != nil {
+			return nil, err
+		}
+		if s.Local, err = flattenLocaleFile(format, localeFile.Code, content); err != nil {
+			return nil, fmt.Errorf("%s: %s", localeFile.RelPath(), err)
+		}
+
+		if !localeFile.ExistsRemote {
+			s.Missing = true
+			s.OnlyLocal = sortedKeys(s.Local)
+			continue
+		}
+
+		downloadParams := &phraseapp.LocaleDownloadParams{FileFormat: &format, FormatOptions: source.Params.FormatOptions}
+		if branch != "" {
+			downloadParams.Branch = &branch
+		}
+		if localeFile.Tag != "" {
+			downloadParams.Tags = &localeFile.Tag
+		}
+		content, err = client.LocaleDownload(source.ProjectID, localeFile.ID, downloadParams)
+		if err != nil {
+			return nil, err
+		}
+		if s.Remote, err = flattenLocaleFile(format, localeFile.Code, content); err != nil {
+			return nil, fmt.Errorf("%s in PhraseApp: %s", localeFile.Message(), err)
+		}
+
+		s.compare()
+	}
+	return statuses, nil
+}
+
+func (s *fileStatus) compare() {
+	for _, key := range sortedKeys(s.Local) {
+		remote, found := s.Remote[key]
+		switch {
+		case !found:
+			s.OnlyLocal = append(s.OnlyLocal, key)
+		case remote != s.Local[key]:
+			s.Changed = append(s.Changed, key)
+		}
+	}
+	for _, key := range sortedKeys(s.Remote) {
+		if _, found := s.Local[key]; !found {
+			s.OnlyRemote = append(s.OnlyRemote, key)
+		}
+	}
+}
+
+func printStatus(statuses []*fileStatus, branch string, listKeys bool) {
+	if branch != "" {
+		fmt.Printf("On branch %s\n", branch)
+	}
+
+	sections := []struct {
+		title   string
+		matches func(s *fileStatus) bool
+	}{
+		{"Not in PhraseApp, pushing creates these locales:", func(s *fileStatus) bool { return s.Missing }},
+		{"Out of sync:", func(s *fileStatus) bool { return !s.Missing && !s.Unsupported && !s.inSync() }},
+		{"In sync:", func(s *fileStatus) bool { return s.inSync() }},
+		{"Not compared, the format is not supported:", func(s *fileStatus) bool { return s.Unsupported }},
+	}
+	for _, section := range sections {
+		matching := []*fileStatus{}
+		for _, s := range statuses {
+			if section.matches(s) {
+				matching = append(matching, s)
+			}
+		}
+		if len(matching) == 0 {
+			continue
+		}
+
+		fmt.Printf("\n%s\n", section.title)
+		for _, s := range matching {
+			s.print(listKeys)
+		}
+	}
+}
+
+func (s *fileStatus) print(listKeys bool) {
+	path := s.LocaleFile.RelPath()
+	switch {
+	case s.inSync():
+		print.Success("\t%s", path)
+		return
+	case s.Unsupported:
+		fmt.Printf("\t%s\n", path)
+		return
+	case s.Missing:
+		print.Failure("\t%s\t(%d keys)", path, len(s.OnlyLocal))
+		return
+	}
+
+	print.Failure("\t%s\t(%d only local, %d only remote, %d changed)", path, len(s.OnlyLocal), len(s.OnlyRemote), len(s.Changed))
+	if !listKeys {
+		return
+	}
+	for _, key := range s.OnlyLocal {
+		fmt.Printf("\t\t+ %s: %q\n", key, s.Local[key])
+	}
+	for _, key := range s.OnlyRemote {
+		fmt.Printf("\t\t- %s: %q\n", key, s.Remote[key])
+	}
+	for _, key := range s.Changed {
+		fmt.Printf("\t\t~ %s: %q (remote: %q)\n", key, s.Local[key], s.Remote[key])
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func comparableFormat(format string) bool {
+	switch format {
+	case "yml_symfony", "yml_symfony2":
+		return true
+	}
+	return localefile.Supported(format)
+}
+
+// flattenLocaleFile returns the translations of a locale file by key, with the keys of nested formats joined by dots.
+// The root key of Rails YAML files is left out if it is the locale code.
+func flattenLocaleFile(format, code string, content []byte) (map[string]string, error) {
+	switch format {
+	case "yml_symfony", "yml_symfony2":
+		var v interface{}
+		if err := yaml.Unmarshal(content, &v); err != nil {
+			return nil, err
+		}
+		translations := map[string]string{}
+		flattenValue("", v, translations)
+		return translations, nil
+	}
+
+	f, err := localefile.Parse(format, content)
+	if err != nil {
+		return nil, err
+	}
+	translations := f.Translations()
+	if format == "yml" && f.Locale != code {
+		prefixed := make(map[string]string, len(translations))
+		for key, value := range translations {
+			prefixed[f.Locale+"."+key] = value
+		}
+		translations = prefixed
+	}
+	return translations, nil
+}
+
+func flattenValue(prefix string, v interface{}, translations map[string]string) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		for key, value := range v {
+			flattenValue(join(fmt.Sprint(key)), value, translations)
+		}
+	case []interface{}:
+		for i, value := range v {
+			flattenValue(fmt.Sprintf("%s[%d]", prefix, i), value, translations)
+		}
+	case nil:
+		if prefix != "" {
+			translations[prefix] = ""
+		}
+	default:
+		translations[prefix] = fmt.Sprint(v)
+	}
+}
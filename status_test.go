@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/phrase/phraseapp-go/phraseapp"
+)
+
+func TestFlattenLocaleFile(t *testing.T) {
+	tests := []struct {
+		format  string
+		content string
+		exp     map[string]string
+	}{
+		{"yml", "en:\n  a: A\n  b:\n    c: C\n    m: 1\n  e:\n", map[string]string{"a": "A", "b.c": "C", "b.m": "1", "e": ""}},
+		{"yml", "de:\n  a: A\n", map[string]string{"de.a": "A"}},
+		{"simple_json", `{"a.b": "A", "c": "C"}`, map[string]string{"a.b": "A", "c": "C"}},
+		{"nested_json", `{"a": {"b": "A", "n": 1.5}, "c": ["x", "y"]}`, map[string]string{"a.b": "A", "a.n": "1.5", "c[0]": "x", "c[1]": "y"}},
+		{"properties", "# comment\n! comment\na=A\nb : B \\\n    continued\nc\\ d=\\u00e4\\n\ne\n", map[string]string{"a": "A", "b": "B continued", "c d": "ä\n", "e": ""}},
+		{"strings", "/* comment */\n\"a\" = \"A\";\n", map[string]string{"a": "A"}},
+		{"yml_symfony", "a: A\nb:\n  c: C\n", map[string]string{"a": "A", "b.c": "C"}},
+	}
+	for _, test := range tests {
+		got, err := flattenLocaleFile(test.format, "en", []byte(test.content))
+		if err != nil {
+			t.Fatalf("%s: didn't expect an error, got: %s", test.format, err)
+		}
+		if !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("%s: expected %v, got %v", test.format, test.exp, got)
+		}
+	}
+
+	if _, err := flattenLocaleFile("properties", "en", []byte("a=\\u00")); err == nil {
+		t.Errorf("expected an error for an invalid unicode escape")
+	}
+	if _, err := flattenLocaleFile("simple_json", "en", []byte("{")); err == nil {
+		t.Errorf("expected an error for invalid JSON")
+	}
+}
+
+func TestSourceStatus(t *testing.T) {
+	ct, done := newCleanupTest(t)
+	defer done()
+
+	ct.upload("en:\n  a: A\n  b: B\n  c: C\n")
+	ct.writeFile("en.yml", "en:\n  a: A\n  b: Changed\n  d: D\n")
+	if err := os.Mkdir("new", 0755); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	ct.writeFile("new/de.yml", "de:\n  a: A\n")
+
+	locales, err := RemoteLocales(ct.client, LocaleCacheKey{ProjectID: "project"})
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+
+	statuses := []*fileStatus{}
+	for _, file := range []string{"./<locale_code>.yml", "./new/<locale_code>.yml"} {
+		source := &Source{File: file, ProjectID: "project", FileFormat: "yml", Params: &phraseapp.UploadParams{}, RemoteLocales: locales}
+		s, err := source.status(ct.client, "")
+		if err != nil {
+			t.Fatalf("didn't expect an error, got: %s", err)
+		}
+		statuses = append(statuses, s...)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("expected the status of two files, got %d", len(statuses))
+	}
+
+	en := statuses[0]
+	if !reflect.DeepEqual(en.OnlyLocal, []string{"d"}) || !reflect.DeepEqual(en.OnlyRemote, []string{"c"}) || !reflect.DeepEqual(en.Changed, []string{"b"}) {
+		t.Errorf("expected d only local, c only remote and b changed, got %+v", en)
+	}
+	if de := statuses[1]; !de.Missing || !reflect.DeepEqual(de.OnlyLocal, []string{"a"}) {
+		t.Errorf("expected the de locale to be missing, got %+v", de)
+	}
+
+	ct.writeFile("en.yml", "en:\n  a: A\n  b: B\n  c: C\n")
+	source := &Source{File: "./<locale_code>.yml", ProjectID: "project", FileFormat: "yml", Params: &phraseapp.UploadParams{}, RemoteLocales: locales}
+	if s, err := source.status(ct.client, ""); err != nil || !s[0].inSync() {
+		t.Errorf("expected the file to be in sync, got %+v (%v)", s, err)
+	}
+}
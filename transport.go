@@ -0,0 +1,232 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/phrase/phraseapp-go/phraseapp"
+)
+
+// TransportConfig contains the settings for the connection to the API. They are read from the phrase block of the
+// config file and from environment variables, used for the settings missing in the config file.
+type TransportConfig struct {
+	CABundle       string        // PEM file with certificates trusted in addition to the system's ones.
+	ClientCert     string        // PEM file with a client certificate, for hosts requiring mutual TLS.
+	ClientKey      string        // PEM file with the key of the client certificate.
+	Proxy          string        // URL of the proxy to use, by default the proxy is taken from HTTPS_PROXY.
+	ConnectTimeout time.Duration // Time to wait for a connection to be established.
+	RequestTimeout time.Duration // Time to wait for the response of the API, once a request was sent.
+}
+
+// transportConfig is applied to all clients created with newClient.
+var transportConfig TransportConfig
+
+var transportSettings = []struct {
+	key string
+	env string
+}{
+	{"ca_bundle", "PHRASEAPP_CA_BUNDLE"},
+	{"client_cert", "PHRASEAPP_CLIENT_CERT"},
+	{"client_key", "PHRASEAPP_CLIENT_KEY"},
+	{"proxy", "PHRASEAPP_PROXY"},
+	{"connect_timeout", "PHRASEAPP_CONNECT_TIMEOUT"},
+	{"request_timeout", "PHRASEAPP_REQUEST_TIMEOUT"},
+}
+
+// extract reads and removes the transport settings from the given phrase block. Relative paths are resolved against
+// baseDir, the directory of the config file.
+func (tc *TransportConfig) extract(block map[string]interface{}, baseDir string) error {
+	for _, setting := range transportSettings {
+		v, found := block[setting.key]
+		if !found {
+			continue
+		}
+		delete(block, setting.key)
+
+		if err := tc.set(setting.key, v, baseDir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyEnv sets the settings missing in the config file from the environment.
+func (tc *TransportConfig) applyEnv() error {
+	for _, setting := range transportSettings {
+		value := os.Getenv(setting.env)
+		if value == "" || tc.isSet(setting.key) {
+			continue
+		}
+
+		if err := tc.set(setting.key, value, ""); err != nil {
+			return fmt.Errorf("%s (from %s environment variable)", err, setting.env)
+		}
+	}
+	return nil
+}
+
+func (tc *TransportConfig) isSet(key string) bool {
+	return tc.get(key) != ""
+}
+
+// get returns the value of the setting with the given key as text, or an empty string if it isn't set.
+func (tc *TransportConfig) get(key string) string {
+	switch key {
+	case "ca_bundle":
+		return tc.CABundle
+	case "client_cert":
+		return tc.ClientCert
+	case "client_key":
+		return tc.ClientKey
+	case "proxy":
+		return tc.Proxy
+	case "connect_timeout":
+		if tc.ConnectTimeout != 0 {
+			return tc.ConnectTimeout.String()
+		}
+	case "request_timeout":
+		if tc.RequestTimeout != 0 {
+			return tc.RequestTimeout.String()
+		}
+	}
+	return ""
+}
+
+func (tc *TransportConfig) set(key string, v interface{}, baseDir string) error {
+	var err error
+	switch key {
+	case "connect_timeout":
+		tc.ConnectTimeout, err = validateIsDuration(key, v)
+		return err
+	case "request_timeout":
+		tc.RequestTimeout, err = validateIsDuration(key, v)
+		return err
+	}
+
+	value, err := phraseapp.ValidateIsString(key, v)
+	if err != nil {
+		return err
+	}
+
+	path := value
+	if baseDir != "" && path != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+
+	switch key {
+	case "ca_bundle":
+		tc.CABundle = path
+	case "client_cert":
+		tc.ClientCert = path
+	case "client_key":
+		tc.ClientKey = path
+	case "proxy":
+		if _, err := url.Parse(value); err != nil {
+			return fmt.Errorf("%s: invalid proxy URL: %s", key, err)
+		}
+		tc.Proxy = value
+	}
+	return nil
+}
+
+// validateIsDuration accepts a number of seconds or a duration like "90s" or "2m".
+func validateIsDuration(k string, v interface{}) (time.Duration, error) {
+	switch value := v.(type) {
+	case int:
+		if value >= 0 {
+			return time.Duration(value) * time.Second, nil
+		}
+	case string:
+		if d, err := parseDuration(value); err == nil {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("%s must be a number of seconds or a duration like 90s or 2m, was %v", k, v)
+}
+
+func parseDuration(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		seconds, convErr := strconv.Atoi(value)
+		if convErr != nil {
+			return 0, err
+		}
+		d = time.Duration(seconds) * time.Second
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("negative duration %s", value)
+	}
+	return d, nil
+}
+
+// newTransport builds the transport for the connection to the API, with the same defaults as http.DefaultTransport.
+func newTransport(tc TransportConfig) (*http.Transport, error) {
+	connectTimeout := 30 * time.Second
+	if tc.ConnectTimeout > 0 {
+		connectTimeout = tc.ConnectTimeout
+	}
+
+	tr := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   connectTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		ResponseHeaderTimeout: tc.RequestTimeout,
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: os.Getenv("PHRASEAPP_INSECURE_SKIP_VERIFY") == "true",
+		},
+	}
+
+	if tc.Proxy != "" {
+		proxyURL, err := url.Parse(tc.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %s", err)
+		}
+		tr.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if tc.CABundle != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		pem, err := ioutil.ReadFile(tc.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %s", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA bundle %s contains no PEM encoded certificates", tc.CABundle)
+		}
+		tr.TLSClientConfig.RootCAs = pool
+	}
+
+	switch {
+	case tc.ClientCert != "" && tc.ClientKey != "":
+		cert, err := tls.LoadX509KeyPair(tc.ClientCert, tc.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %s", err)
+		}
+		tr.TLSClientConfig.Certificates = []tls.Certificate{cert}
+	case tc.ClientCert != "":
+		return nil, fmt.Errorf("client_cert is given without client_key")
+	case tc.ClientKey != "":
+		return nil, fmt.Errorf("client_key is given without client_cert")
+	}
+
+	return tr, nil
+}
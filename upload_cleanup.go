@@ -1,18 +1,26 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/phrase/phraseapp-client/internal/print"
 	"github.com/phrase/phraseapp-client/internal/prompt"
 	"github.com/phrase/phraseapp-go/phraseapp"
 )
 
 type UploadCleanupCommand struct {
 	phraseapp.Config
-	ID      string `cli:"arg required"`
-	Confirm bool   `cli:"opt --confirm desc='Don’t ask for confirmation'"`
+	ID        string `cli:"arg required"`
+	Confirm   bool   `cli:"opt --confirm desc='Don’t ask for confirmation'"`
+	DryRun    bool   `cli:"opt --dry-run desc='Only list the keys that would be deleted'"`
+	Branch    string `cli:"opt --branch desc='Branch the upload was made in'"`
+	ProjectID string `cli:"opt --project-id desc='Project the upload was made in (default: project_id from config)'"`
 }
 
 func (cmd *UploadCleanupCommand) Run() error {
@@ -25,60 +33,386 @@ func (cmd *UploadCleanupCommand) Run() error {
 	return UploadCleanup(client, cmd)
 }
 
-func UploadCleanup(client *phraseapp.Client, cmd *UploadCleanupCommand) error {
-	q := "unmentioned_in_upload:" + cmd.ID
-	params := &phraseapp.KeysListParams{Q: &q}
-	page := 1
+type UploadCleanupRestoreCommand struct {
+	phraseapp.Config
+	Backup string `cli:"arg required"`
+}
 
-	keys, err := client.KeysList(cmd.Config.DefaultProjectID, page, 100, params)
+func (cmd *UploadCleanupRestoreCommand) Run() error {
+	client, err := newClient(cmd.Config.Credentials, cmd.Config.Debug)
 	if err != nil {
 		return err
 	}
+	return RestoreCleanupBackup(client, cmd.Backup)
+}
+
+func UploadCleanup(client *phraseapp.Client, cmd *UploadCleanupCommand) error {
+	projectID := cmd.ProjectID
+	if projectID == "" {
+		projectID = cmd.Config.DefaultProjectID
+	}
+	if projectID == "" {
+		return errors.New("no project given, set it with --project-id or project_id in the config file")
+	}
+
+	cleanup := &keysCleanup{
+		ProjectID: projectID,
+		Branch:    cmd.Branch,
+		Queries:   []string{"unmentioned_in_upload:" + cmd.ID},
+		Confirm:   cmd.Confirm,
+		DryRun:    cmd.DryRun,
+	}
+	return cleanup.run(client)
+}
+
+// keysCleanup deletes the keys of a project matching all of the queries. The keys and their translations are written
+// to a backup file before, which RestoreCleanupBackup can recreate them from.
+type keysCleanup struct {
+	ProjectID string
+	Branch    string
+	Queries   []string
+	Confirm   bool
+	DryRun    bool
+}
+
+// keysPerRequest is the number of keys listed and deleted per request.
+const keysPerRequest = 100
 
+func (c *keysCleanup) run(client *phraseapp.Client) error {
+	// All keys are listed before deleting any, as deleting keys moves the following ones to the pages already listed.
+	keys, err := c.keys(client)
+	if err != nil {
+		return err
+	}
 	if len(keys) == 0 {
-		fmt.Println("There were no keys unmentioned in that upload.")
+		fmt.Println("There were no unmentioned keys.")
+		return nil
+	}
+
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = key.Name
+	}
+	sort.Strings(names)
+
+	if c.DryRun {
+		fmt.Println("The following key(s) would be deleted from your project:")
+		fmt.Println(strings.Join(names, "\n"))
+		fmt.Printf("%d key(s) would be deleted.\n", len(keys))
 		return nil
 	}
 
-	for len(keys) != 0 {
-		ids := make([]string, len(keys))
-		names := make([]string, len(keys))
-		for i, key := range keys {
-			ids[i] = key.ID
-			names[i] = key.Name
+	if !c.Confirm {
+		fmt.Println("You are about to delete the following key(s) from your project:")
+		fmt.Println(strings.Join(names, "\n"))
+
+		confirmation := ""
+		err := prompt.WithDefault("Are you sure you want to continue? (y/n)", &confirmation, "n")
+		if err != nil {
+			return err
 		}
 
-		if !cmd.Confirm {
-			fmt.Println("You are about to delete the following key(s) from your project:")
-			sort.Strings(names)
-			fmt.Println(strings.Join(names, "\n"))
+		if strings.ToLower(confirmation) != "y" {
+			fmt.Println("Clean up aborted")
+			return nil
+		}
+	}
 
-			confirmation := ""
-			err := prompt.WithDefault("Are you sure you want to continue? (y/n)", &confirmation, "n")
-			if err != nil {
-				return err
+	path, err := c.backup(client, keys)
+	if err != nil {
+		return fmt.Errorf("writing the backup failed, no keys were deleted: %s", err)
+	}
+	fmt.Printf("Keys and translations backed up to %s, restore them with: phraseapp upload cleanup restore %s\n", path, path)
+
+	deleted := 0
+	for start := 0; start < len(keys); start += keysPerRequest {
+		end := start + keysPerRequest
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		ids := make([]string, 0, end-start)
+		for _, key := range keys[start:end] {
+			ids = append(ids, key.ID)
+		}
+
+		q := "ids:" + strings.Join(ids, ",")
+		params := &phraseapp.KeysDeleteParams{Q: &q}
+		if c.Branch != "" {
+			params.Branch = &c.Branch
+		}
+		affected, err := client.KeysDelete(c.ProjectID, params)
+		if err != nil {
+			return err
+		}
+		deleted += int(affected.RecordsAffected)
+	}
+
+	fmt.Printf("%d key(s) successfully deleted.\n", deleted)
+	return nil
+}
+
+func (c *keysCleanup) keys(client *phraseapp.Client) ([]*phraseapp.TranslationKey, error) {
+	keys, err := c.query(client, c.Queries[0])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, q := range c.Queries[1:] {
+		if len(keys) == 0 {
+			break
+		}
+		matching, err := c.query(client, q)
+		if err != nil {
+			return nil, err
+		}
+		ids := map[string]bool{}
+		for _, key := range matching {
+			ids[key.ID] = true
+		}
+		remaining := []*phraseapp.TranslationKey{}
+		for _, key := range keys {
+			if ids[key.ID] {
+				remaining = append(remaining, key)
 			}
+		}
+		keys = remaining
+	}
+	return keys, nil
+}
+
+func (c *keysCleanup) query(client *phraseapp.Client, q string) ([]*phraseapp.TranslationKey, error) {
+	params := &phraseapp.KeysListParams{Q: &q}
+	if c.Branch != "" {
+		params.Branch = &c.Branch
+	}
+
+	keys := []*phraseapp.TranslationKey{}
+	for page := 1; ; page++ {
+		list, err := client.KeysList(c.ProjectID, page, keysPerRequest, params)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, list...)
+		if len(list) < keysPerRequest {
+			return keys, nil
+		}
+	}
+}
+
+// cleanupBackup is the content of a backup file written before keys are deleted.
+type cleanupBackup struct {
+	ProjectID string       `json:"project_id"`
+	Branch    string       `json:"branch,omitempty"`
+	Query     string       `json:"query"`
+	CreatedAt time.Time    `json:"created_at"`
+	Keys      []*backupKey `json:"keys"`
+}
+
+type backupKey struct {
+	Name                 string               `json:"name"`
+	Description          string               `json:"description,omitempty"`
+	DataType             string               `json:"data_type,omitempty"`
+	Plural               bool                 `json:"plural,omitempty"`
+	NamePlural           string               `json:"name_plural,omitempty"`
+	Tags                 []string             `json:"tags,omitempty"`
+	MaxCharactersAllowed int64                `json:"max_characters_allowed,omitempty"`
+	OriginalFile         string               `json:"original_file,omitempty"`
+	Unformatted          bool                 `json:"unformatted,omitempty"`
+	XmlSpacePreserve     bool                 `json:"xml_space_preserve,omitempty"`
+	Translations         []*backupTranslation `json:"translations"`
+}
+
+type backupTranslation struct {
+	LocaleID     string `json:"locale_id"`
+	LocaleCode   string `json:"locale_code"`
+	Content      string `json:"content"`
+	PluralSuffix string `json:"plural_suffix,omitempty"`
+	Unverified   bool   `json:"unverified,omitempty"`
+	Excluded     bool   `json:"excluded,omitempty"`
+}
+
+// backup writes the keys with all their translations to a file in the working directory and returns its path.
+func (c *keysCleanup) backup(client *phraseapp.Client, keys []*phraseapp.TranslationKey) (string, error) {
+	now := time.Now()
+	backup := &cleanupBackup{ProjectID: c.ProjectID, Branch: c.Branch, Query: strings.Join(c.Queries, " "), CreatedAt: now.UTC().Truncate(time.Second)}
+
+	for _, key := range keys {
+		bk, err := c.backupKey(client, key)
+		if err != nil {
+			return "", fmt.Errorf("key %s: %s", key.Name, err)
+		}
+		backup.Keys = append(backup.Keys, bk)
+	}
+
+	content, err := json.MarshalIndent(backup, "", "  ")
+	if err != nil {
+		return "", err
+	}
 
-			if strings.ToLower(confirmation) != "y" {
-				fmt.Println("Clean up aborted")
-				return nil
+	path := fmt.Sprintf("phraseapp-cleanup-%s-%s.json", c.ProjectID, now.Format("20060102-150405"))
+	return path, ioutil.WriteFile(path, append(content, '\n'), 0600)
+}
+
+func (c *keysCleanup) backupKey(client *phraseapp.Client, key *phraseapp.TranslationKey) (*backupKey, error) {
+	showParams := &phraseapp.KeyShowParams{}
+	translationsParams := &phraseapp.TranslationsByKeyParams{}
+	if c.Branch != "" {
+		showParams.Branch = &c.Branch
+		translationsParams.Branch = &c.Branch
+	}
+
+	details, err := client.KeyShow(c.ProjectID, key.ID, showParams)
+	if err != nil {
+		return nil, err
+	}
+	bk := &backupKey{
+		Name:                 details.Name,
+		Description:          details.Description,
+		DataType:             details.DataType,
+		Plural:               details.Plural,
+		NamePlural:           details.NamePlural,
+		Tags:                 details.Tags,
+		MaxCharactersAllowed: details.MaxCharactersAllowed,
+		OriginalFile:         details.OriginalFile,
+		Unformatted:          details.Unformatted,
+		XmlSpacePreserve:     details.XmlSpacePreserve,
+		Translations:         []*backupTranslation{},
+	}
+
+	for page := 1; ; page++ {
+		translations, err := client.TranslationsByKey(c.ProjectID, key.ID, page, keysPerRequest, translationsParams)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range translations {
+			bt := &backupTranslation{Content: t.Content, PluralSuffix: t.PluralSuffix, Unverified: t.Unverified, Excluded: t.Excluded}
+			if t.Locale != nil {
+				bt.LocaleID, bt.LocaleCode = t.Locale.ID, t.Locale.Code
 			}
+			bk.Translations = append(bk.Translations, bt)
 		}
+		if len(translations) < keysPerRequest {
+			return bk, nil
+		}
+	}
+}
 
-		q := "ids:" + strings.Join(ids, ",")
-		affected, err := client.KeysDelete(cmd.Config.DefaultProjectID, &phraseapp.KeysDeleteParams{
-			Q: &q,
-		})
+// RestoreCleanupBackup recreates the keys and translations of a backup file written by a cleanup. Keys that exist
+// again are skipped.
+func RestoreCleanupBackup(client *phraseapp.Client, path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	backup := &cleanupBackup{}
+	if err := json.Unmarshal(content, backup); err != nil {
+		return fmt.Errorf("%s: %s", path, err)
+	}
+
+	// Translations are restored to the locales with the same code, as locales might have been recreated since.
+	locales, err := RemoteLocales(client, LocaleCacheKey{ProjectID: backup.ProjectID, Branch: backup.Branch})
+	if err != nil {
+		return err
+	}
+	localeIDs := map[string]string{}
+	for _, l := range locales {
+		localeIDs[l.Code] = l.ID
+	}
+
+	var branch *string
+	if backup.Branch != "" {
+		branch = &backup.Branch
+	}
 
+	restored, skipped, failed := 0, 0, 0
+	for _, bk := range backup.Keys {
+		exists, err := keyExists(client, backup.ProjectID, backup.Branch, bk.Name)
 		if err != nil {
 			return err
 		}
+		if exists {
+			fmt.Printf("Skipping key %s, it exists already.\n", bk.Name)
+			skipped++
+			continue
+		}
+
+		params := &phraseapp.TranslationKeyParams{
+			Branch:           branch,
+			Name:             &bk.Name,
+			Description:      optionalString(bk.Description),
+			DataType:         optionalString(bk.DataType),
+			NamePlural:       optionalString(bk.NamePlural),
+			OriginalFile:     optionalString(bk.OriginalFile),
+			Plural:           &bk.Plural,
+			Unformatted:      &bk.Unformatted,
+			XmlSpacePreserve: &bk.XmlSpacePreserve,
+		}
+		if len(bk.Tags) > 0 {
+			tags := strings.Join(bk.Tags, ",")
+			params.Tags = &tags
+		}
+		if bk.MaxCharactersAllowed > 0 {
+			params.MaxCharactersAllowed = &bk.MaxCharactersAllowed
+		}
 
-		fmt.Printf("%d key(s) successfully deleted.\n", affected.RecordsAffected)
+		key, err := client.KeyCreate(backup.ProjectID, params)
+		if err != nil {
+			print.Failure("Restoring key %s failed: %s", bk.Name, err)
+			failed++
+			continue
+		}
+		restored++
 
-		page++
-		keys, _ = client.KeysList(cmd.Config.DefaultProjectID, page, 100, params)
+		for _, bt := range bk.Translations {
+			localeID := bt.LocaleID
+			if id, found := localeIDs[bt.LocaleCode]; found {
+				localeID = id
+			}
+			_, err := client.TranslationCreate(backup.ProjectID, &phraseapp.TranslationParams{
+				Branch:       branch,
+				KeyID:        &key.ID,
+				LocaleID:     &localeID,
+				Content:      &bt.Content,
+				PluralSuffix: optionalString(bt.PluralSuffix),
+				Unverified:   &bt.Unverified,
+				Excluded:     &bt.Excluded,
+			})
+			if err != nil {
+				print.Failure("Restoring the %s translation of key %s failed: %s", bt.LocaleCode, bk.Name, err)
+				failed++
+			}
+		}
 	}
 
+	if skipped > 0 {
+		fmt.Printf("%d key(s) skipped.\n", skipped)
+	}
+	if failed > 0 {
+		return fmt.Errorf("restoring %d key(s) or translation(s) failed", failed)
+	}
+	print.Success("%d key(s) restored.", restored)
 	return nil
 }
+
+// keyExists reports whether the project has a key with the given name.
+func keyExists(client *phraseapp.Client, projectID, branch, name string) (bool, error) {
+	c := &keysCleanup{ProjectID: projectID, Branch: branch}
+	keys, err := c.query(client, "name:"+name)
+	if err != nil {
+		return false, err
+	}
+	for _, key := range keys {
+		if key.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
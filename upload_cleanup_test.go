@@ -0,0 +1,158 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/phrase/phraseapp-client/internal/fakeapi"
+	"github.com/phrase/phraseapp-go/phraseapp"
+)
+
+// cleanupTest runs a fake API and switches to a temporary directory for the backup files.
+type cleanupTest struct {
+	t      *testing.T
+	client *phraseapp.Client
+	dir    string
+}
+
+func newCleanupTest(t *testing.T) (*cleanupTest, func()) {
+	srv := httptest.NewServer(fakeapi.New())
+	client, err := phraseapp.NewClient(phraseapp.Credentials{Token: "token", Host: srv.URL}, false)
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+
+	dir, err := ioutil.TempDir("", "phraseapp-cleanup")
+	if err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+
+	return &cleanupTest{t: t, client: client, dir: dir}, func() {
+		os.Chdir(wd)
+		os.RemoveAll(dir)
+		srv.Close()
+	}
+}
+
+func (ct *cleanupTest) writeFile(name, content string) {
+	if err := ioutil.WriteFile(filepath.Join(ct.dir, name), []byte(content), 0600); err != nil {
+		ct.t.Fatalf("didn't expect an error, got: %s", err)
+	}
+}
+
+func (ct *cleanupTest) upload(content string) *phraseapp.Upload {
+	ct.writeFile("en.yml", content)
+	path, format := filepath.Join(ct.dir, "en.yml"), "yml"
+	u, err := ct.client.UploadCreate("project", &phraseapp.UploadParams{File: &path, FileFormat: &format})
+	if err != nil {
+		ct.t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	return u
+}
+
+func (ct *cleanupTest) keyNames() map[string]bool {
+	keys, err := ct.client.KeysList("project", 1, 100, &phraseapp.KeysListParams{})
+	if err != nil {
+		ct.t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	names := map[string]bool{}
+	for _, k := range keys {
+		names[k.Name] = true
+	}
+	return names
+}
+
+func TestUploadCleanupAndRestore(t *testing.T) {
+	ct, done := newCleanupTest(t)
+	defer done()
+
+	ct.upload("en:\n  a: A\n  b: B\n  c: C\n")
+	u := ct.upload("en:\n  a: A\n")
+
+	cmd := &UploadCleanupCommand{ID: u.ID, ProjectID: "project", Confirm: true, DryRun: true}
+	if err := UploadCleanup(ct.client, cmd); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if names := ct.keyNames(); len(names) != 3 {
+		t.Errorf("expected a dry run to keep all keys, got %v", names)
+	}
+
+	cmd.DryRun = false
+	if err := UploadCleanup(ct.client, cmd); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if names := ct.keyNames(); len(names) != 1 || !names["a"] {
+		t.Errorf("expected only key a to remain, got %v", names)
+	}
+
+	backups, _ := filepath.Glob(filepath.Join(ct.dir, "phraseapp-cleanup-project-*.json"))
+	if len(backups) != 1 {
+		t.Fatalf("expected one backup file, got %v", backups)
+	}
+
+	if err := RestoreCleanupBackup(ct.client, backups[0]); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if names := ct.keyNames(); len(names) != 3 {
+		t.Errorf("expected the keys to be restored, got %v", names)
+	}
+
+	keys, err := ct.client.KeysList("project", 1, 100, &phraseapp.KeysListParams{Q: optionalString("name:b")})
+	if err != nil || len(keys) != 1 {
+		t.Fatalf("expected key b, got %v (%v)", keys, err)
+	}
+	translations, err := ct.client.TranslationsByKey("project", keys[0].ID, 1, 100, &phraseapp.TranslationsByKeyParams{})
+	if err != nil || len(translations) != 1 || translations[0].Content != "B" {
+		t.Errorf("expected the translation of key b to be restored, got %v (%v)", translations, err)
+	}
+
+	// restoring again skips the existing keys
+	if err := RestoreCleanupBackup(ct.client, backups[0]); err != nil {
+		t.Fatalf("didn't expect an error, got: %s", err)
+	}
+	if keys, err := ct.client.KeysList("project", 1, 100, &phraseapp.KeysListParams{}); err != nil || len(keys) != 3 {
+		t.Errorf("expected no keys to be added again, got %v (%v)", keys, err)
+	}
+}
+
+func TestPushCleanup(t *testing.T) {
+	ct, done := newCleanupTest(t)
+	defer done()
+
+	ct.upload("en:\n  a: A\n  b: B\n  c: C\n")
+	web := ct.upload("en:\n  a: A\n")
+	app := ct.upload("en:\n  b: B\n")
+
+	pushed := newPushedUploads()
+	pushed.add("project", "", web.ID, true)
+	pushed.add("project", "", app.ID, true)
+	pushed.add("other", "", "", false)
+
+	cmd := &PushCommand{Confirm: true}
+	err := cmd.cleanup(ct.client, pushed)
+	if err == nil || !strings.Contains(err.Error(), "project(s) other were not cleaned up") {
+		t.Errorf("expected an error about the skipped project, got: %v", err)
+	}
+	if names := ct.keyNames(); len(names) != 2 || !names["a"] || !names["b"] {
+		t.Errorf("expected the keys mentioned in either upload to remain, got %v", names)
+	}
+
+	// a failed upload of a project keeps all of its keys
+	pushed = newPushedUploads()
+	pushed.add("project", "", web.ID, true)
+	pushed.add("project", "", "", false)
+	if err := cmd.cleanup(ct.client, pushed); err == nil {
+		t.Errorf("expected an error about the skipped project")
+	}
+	if names := ct.keyNames(); len(names) != 2 {
+		t.Errorf("expected no keys to be deleted, got %v", names)
+	}
+}